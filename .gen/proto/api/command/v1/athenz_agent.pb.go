@@ -31,7 +31,7 @@ var file_proto_athenz_agent_api_command_v1_athenz_agent_proto_rawDesc = []byte{
 	0x2e, 0x76, 0x31, 0x1a, 0x34, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e,
 	0x7a, 0x2f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x6d, 0x65, 0x73, 0x73,
 	0x61, 0x67, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x5f, 0x61, 0x67,
-	0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x80, 0x02, 0x0a, 0x0b, 0x41, 0x74,
+	0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0xfd, 0x03, 0x0a, 0x0b, 0x41, 0x74,
 	0x68, 0x65, 0x6e, 0x7a, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x12, 0x79, 0x0a, 0x14, 0x43, 0x68, 0x65,
 	0x63, 0x6b, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x57, 0x69, 0x74, 0x68, 0x54, 0x6f, 0x6b, 0x65,
 	0x6e, 0x12, 0x2f, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
@@ -40,34 +40,58 @@ var file_proto_athenz_agent_api_command_v1_athenz_agent_proto_rawDesc = []byte{
 	0x73, 0x74, 0x1a, 0x30, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e,
 	0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31,
 	0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x76, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69,
-	0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x30, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x85, 0x01, 0x0a, 0x18, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x48, 0x54,
+	0x54, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x57, 0x69, 0x74, 0x68, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x33, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x48, 0x54, 0x54, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x34, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e,
+	0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x54, 0x54, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x76, 0x0a, 0x0f,
+	0x47, 0x65, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12,
+	0x30, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x31, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x73, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x41, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x2f, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e,
+	0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a,
 	0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b,
-	0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x31, 0x2e, 0x61, 0x74, 0x68, 0x65,
-	0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54,
-	0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x44, 0x5a, 0x42,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x61, 0x6d, 0x65, 0x64,
-	0x2d, 0x79, 0x6f, 0x75, 0x73, 0x65, 0x66, 0x69, 0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2d,
-	0x61, 0x67, 0x65, 0x6e, 0x74, 0x2f, 0x2e, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2f, 0x76, 0x31, 0x3b,
-	0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x44, 0x5a, 0x42, 0x67, 0x69, 0x74,
+	0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x61, 0x6d, 0x65, 0x64, 0x2d, 0x79, 0x6f,
+	0x75, 0x73, 0x65, 0x66, 0x69, 0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2d, 0x61, 0x67, 0x65,
+	0x6e, 0x74, 0x2f, 0x2e, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2f, 0x76, 0x31, 0x3b, 0x76, 0x31, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_proto_athenz_agent_api_command_v1_athenz_agent_proto_goTypes = []interface{}{
-	(*v1.AccessCheckRequest)(nil),   // 0: athenz.agent.api.message.v1.AccessCheckRequest
-	(*v1.ServiceTokenRequest)(nil),  // 1: athenz.agent.api.message.v1.ServiceTokenRequest
-	(*v1.AccessCheckResponse)(nil),  // 2: athenz.agent.api.message.v1.AccessCheckResponse
-	(*v1.ServiceTokenResponse)(nil), // 3: athenz.agent.api.message.v1.ServiceTokenResponse
+	(*v1.AccessCheckRequest)(nil),      // 0: athenz.agent.api.message.v1.AccessCheckRequest
+	(*v1.HTTPAccessCheckRequest)(nil),  // 1: athenz.agent.api.message.v1.HTTPAccessCheckRequest
+	(*v1.ServiceTokenRequest)(nil),     // 2: athenz.agent.api.message.v1.ServiceTokenRequest
+	(*v1.AccessTokenRequest)(nil),      // 3: athenz.agent.api.message.v1.AccessTokenRequest
+	(*v1.AccessCheckResponse)(nil),     // 4: athenz.agent.api.message.v1.AccessCheckResponse
+	(*v1.HTTPAccessCheckResponse)(nil), // 5: athenz.agent.api.message.v1.HTTPAccessCheckResponse
+	(*v1.ServiceTokenResponse)(nil),    // 6: athenz.agent.api.message.v1.ServiceTokenResponse
+	(*v1.AccessTokenResponse)(nil),     // 7: athenz.agent.api.message.v1.AccessTokenResponse
 }
 var file_proto_athenz_agent_api_command_v1_athenz_agent_proto_depIdxs = []int32{
 	0, // 0: athenz.agent.api.command.v1.AthenzAgent.CheckAccessWithToken:input_type -> athenz.agent.api.message.v1.AccessCheckRequest
-	1, // 1: athenz.agent.api.command.v1.AthenzAgent.GetServiceToken:input_type -> athenz.agent.api.message.v1.ServiceTokenRequest
-	2, // 2: athenz.agent.api.command.v1.AthenzAgent.CheckAccessWithToken:output_type -> athenz.agent.api.message.v1.AccessCheckResponse
-	3, // 3: athenz.agent.api.command.v1.AthenzAgent.GetServiceToken:output_type -> athenz.agent.api.message.v1.ServiceTokenResponse
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
+	1, // 1: athenz.agent.api.command.v1.AthenzAgent.CheckHTTPAccessWithToken:input_type -> athenz.agent.api.message.v1.HTTPAccessCheckRequest
+	2, // 2: athenz.agent.api.command.v1.AthenzAgent.GetServiceToken:input_type -> athenz.agent.api.message.v1.ServiceTokenRequest
+	3, // 3: athenz.agent.api.command.v1.AthenzAgent.GetAccessToken:input_type -> athenz.agent.api.message.v1.AccessTokenRequest
+	4, // 4: athenz.agent.api.command.v1.AthenzAgent.CheckAccessWithToken:output_type -> athenz.agent.api.message.v1.AccessCheckResponse
+	5, // 5: athenz.agent.api.command.v1.AthenzAgent.CheckHTTPAccessWithToken:output_type -> athenz.agent.api.message.v1.HTTPAccessCheckResponse
+	6, // 6: athenz.agent.api.command.v1.AthenzAgent.GetServiceToken:output_type -> athenz.agent.api.message.v1.ServiceTokenResponse
+	7, // 7: athenz.agent.api.command.v1.AthenzAgent.GetAccessToken:output_type -> athenz.agent.api.message.v1.AccessTokenResponse
+	4, // [4:8] is the sub-list for method output_type
+	0, // [0:4] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -0,0 +1,153 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.25.0-devel
+// 	protoc        v3.14.0
+// source: proto/athenz/agent/api/command/v1/athenz_agent_admin.proto
+
+package v1
+
+import (
+	v1 "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto protoreflect.FileDescriptor
+
+var file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_rawDesc = []byte{
+	0x0a, 0x3a, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2f, 0x61,
+	0x67, 0x65, 0x6e, 0x74, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x2f, 0x76, 0x31, 0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x5f, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x5f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1b, 0x61, 0x74,
+	0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x2e, 0x76, 0x31, 0x1a, 0x3a, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x74, 0x68,
+	0x65, 0x6e, 0x7a, 0x5f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0xb9, 0x06, 0x0a, 0x10, 0x41, 0x74, 0x68, 0x65, 0x6e, 0x7a,
+	0x41, 0x67, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x6d, 0x69, 0x6e, 0x12, 0x6d, 0x0a, 0x0b, 0x47, 0x65,
+	0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x2f, 0x2e, 0x61, 0x74, 0x68, 0x65,
+	0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65,
+	0x76, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x61, 0x74, 0x68,
+	0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6d, 0x0a, 0x0b, 0x53, 0x65, 0x74,
+	0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x2f, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e,
+	0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x74, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76,
+	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x61, 0x74, 0x68, 0x65,
+	0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x67, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x70, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74,
+	0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x2f, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a,
+	0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e,
+	0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69,
+	0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7c, 0x0a, 0x0f, 0x52, 0x65,
+	0x66, 0x72, 0x65, 0x73, 0x68, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x12, 0x33, 0x2e,
+	0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x66, 0x72,
+	0x65, 0x73, 0x68, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x34, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e,
+	0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x69, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x70, 0x0a, 0x0b, 0x52, 0x65, 0x6c, 0x6f,
+	0x61, 0x64, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x2f, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a,
+	0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x61, 0x63, 0x68,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x30, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e,
+	0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x7c, 0x0a, 0x0f, 0x46, 0x6c,
+	0x75, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x12, 0x33, 0x2e,
+	0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6c, 0x75, 0x73,
+	0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x34, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e,
+	0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x6c, 0x75, 0x73, 0x68, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x67, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x2d, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61,
+	0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67,
+	0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x42, 0x44, 0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x68, 0x61, 0x6d, 0x65, 0x64, 0x2d, 0x79, 0x6f, 0x75, 0x73, 0x65, 0x66, 0x69, 0x2f, 0x61, 0x74,
+	0x68, 0x65, 0x6e, 0x7a, 0x2d, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2f, 0x2e, 0x67, 0x65, 0x6e, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x2f, 0x76, 0x31, 0x3b, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_goTypes = []interface{}{
+	(*v1.GetLogLevelRequest)(nil),      // 0: athenz.agent.api.message.v1.GetLogLevelRequest
+	(*v1.SetLogLevelRequest)(nil),      // 1: athenz.agent.api.message.v1.SetLogLevelRequest
+	(*v1.ListDomainsRequest)(nil),      // 2: athenz.agent.api.message.v1.ListDomainsRequest
+	(*v1.RefreshPoliciesRequest)(nil),  // 3: athenz.agent.api.message.v1.RefreshPoliciesRequest
+	(*v1.ReloadCacheRequest)(nil),      // 4: athenz.agent.api.message.v1.ReloadCacheRequest
+	(*v1.FlushTokenCacheRequest)(nil),  // 5: athenz.agent.api.message.v1.FlushTokenCacheRequest
+	(*v1.GetStatusRequest)(nil),        // 6: athenz.agent.api.message.v1.GetStatusRequest
+	(*v1.LogLevelResponse)(nil),        // 7: athenz.agent.api.message.v1.LogLevelResponse
+	(*v1.ListDomainsResponse)(nil),     // 8: athenz.agent.api.message.v1.ListDomainsResponse
+	(*v1.RefreshPoliciesResponse)(nil), // 9: athenz.agent.api.message.v1.RefreshPoliciesResponse
+	(*v1.ReloadCacheResponse)(nil),     // 10: athenz.agent.api.message.v1.ReloadCacheResponse
+	(*v1.FlushTokenCacheResponse)(nil), // 11: athenz.agent.api.message.v1.FlushTokenCacheResponse
+	(*v1.StatusResponse)(nil),          // 12: athenz.agent.api.message.v1.StatusResponse
+}
+var file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_depIdxs = []int32{
+	0,  // 0: athenz.agent.api.command.v1.AthenzAgentAdmin.GetLogLevel:input_type -> athenz.agent.api.message.v1.GetLogLevelRequest
+	1,  // 1: athenz.agent.api.command.v1.AthenzAgentAdmin.SetLogLevel:input_type -> athenz.agent.api.message.v1.SetLogLevelRequest
+	2,  // 2: athenz.agent.api.command.v1.AthenzAgentAdmin.ListDomains:input_type -> athenz.agent.api.message.v1.ListDomainsRequest
+	3,  // 3: athenz.agent.api.command.v1.AthenzAgentAdmin.RefreshPolicies:input_type -> athenz.agent.api.message.v1.RefreshPoliciesRequest
+	4,  // 4: athenz.agent.api.command.v1.AthenzAgentAdmin.ReloadCache:input_type -> athenz.agent.api.message.v1.ReloadCacheRequest
+	5,  // 5: athenz.agent.api.command.v1.AthenzAgentAdmin.FlushTokenCache:input_type -> athenz.agent.api.message.v1.FlushTokenCacheRequest
+	6,  // 6: athenz.agent.api.command.v1.AthenzAgentAdmin.GetStatus:input_type -> athenz.agent.api.message.v1.GetStatusRequest
+	7,  // 7: athenz.agent.api.command.v1.AthenzAgentAdmin.GetLogLevel:output_type -> athenz.agent.api.message.v1.LogLevelResponse
+	7,  // 8: athenz.agent.api.command.v1.AthenzAgentAdmin.SetLogLevel:output_type -> athenz.agent.api.message.v1.LogLevelResponse
+	8,  // 9: athenz.agent.api.command.v1.AthenzAgentAdmin.ListDomains:output_type -> athenz.agent.api.message.v1.ListDomainsResponse
+	9,  // 10: athenz.agent.api.command.v1.AthenzAgentAdmin.RefreshPolicies:output_type -> athenz.agent.api.message.v1.RefreshPoliciesResponse
+	10, // 11: athenz.agent.api.command.v1.AthenzAgentAdmin.ReloadCache:output_type -> athenz.agent.api.message.v1.ReloadCacheResponse
+	11, // 12: athenz.agent.api.command.v1.AthenzAgentAdmin.FlushTokenCache:output_type -> athenz.agent.api.message.v1.FlushTokenCacheResponse
+	12, // 13: athenz.agent.api.command.v1.AthenzAgentAdmin.GetStatus:output_type -> athenz.agent.api.message.v1.StatusResponse
+	7,  // [7:14] is the sub-list for method output_type
+	0,  // [0:7] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_init() }
+func file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_init() {
+	if File_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_goTypes,
+		DependencyIndexes: file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_depIdxs,
+	}.Build()
+	File_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto = out.File
+	file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_rawDesc = nil
+	file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_goTypes = nil
+	file_proto_athenz_agent_api_command_v1_athenz_agent_admin_proto_depIdxs = nil
+}
@@ -0,0 +1,316 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package v1
+
+import (
+	context "context"
+	v1 "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// AthenzAgentAdminClient is the client API for AthenzAgentAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AthenzAgentAdminClient interface {
+	GetLogLevel(ctx context.Context, in *v1.GetLogLevelRequest, opts ...grpc.CallOption) (*v1.LogLevelResponse, error)
+	SetLogLevel(ctx context.Context, in *v1.SetLogLevelRequest, opts ...grpc.CallOption) (*v1.LogLevelResponse, error)
+	ListDomains(ctx context.Context, in *v1.ListDomainsRequest, opts ...grpc.CallOption) (*v1.ListDomainsResponse, error)
+	RefreshPolicies(ctx context.Context, in *v1.RefreshPoliciesRequest, opts ...grpc.CallOption) (*v1.RefreshPoliciesResponse, error)
+	ReloadCache(ctx context.Context, in *v1.ReloadCacheRequest, opts ...grpc.CallOption) (*v1.ReloadCacheResponse, error)
+	FlushTokenCache(ctx context.Context, in *v1.FlushTokenCacheRequest, opts ...grpc.CallOption) (*v1.FlushTokenCacheResponse, error)
+	GetStatus(ctx context.Context, in *v1.GetStatusRequest, opts ...grpc.CallOption) (*v1.StatusResponse, error)
+}
+
+type athenzAgentAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAthenzAgentAdminClient(cc grpc.ClientConnInterface) AthenzAgentAdminClient {
+	return &athenzAgentAdminClient{cc}
+}
+
+func (c *athenzAgentAdminClient) GetLogLevel(ctx context.Context, in *v1.GetLogLevelRequest, opts ...grpc.CallOption) (*v1.LogLevelResponse, error) {
+	out := new(v1.LogLevelResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgentAdmin/GetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *athenzAgentAdminClient) SetLogLevel(ctx context.Context, in *v1.SetLogLevelRequest, opts ...grpc.CallOption) (*v1.LogLevelResponse, error) {
+	out := new(v1.LogLevelResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgentAdmin/SetLogLevel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *athenzAgentAdminClient) ListDomains(ctx context.Context, in *v1.ListDomainsRequest, opts ...grpc.CallOption) (*v1.ListDomainsResponse, error) {
+	out := new(v1.ListDomainsResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgentAdmin/ListDomains", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *athenzAgentAdminClient) RefreshPolicies(ctx context.Context, in *v1.RefreshPoliciesRequest, opts ...grpc.CallOption) (*v1.RefreshPoliciesResponse, error) {
+	out := new(v1.RefreshPoliciesResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgentAdmin/RefreshPolicies", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *athenzAgentAdminClient) ReloadCache(ctx context.Context, in *v1.ReloadCacheRequest, opts ...grpc.CallOption) (*v1.ReloadCacheResponse, error) {
+	out := new(v1.ReloadCacheResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgentAdmin/ReloadCache", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *athenzAgentAdminClient) FlushTokenCache(ctx context.Context, in *v1.FlushTokenCacheRequest, opts ...grpc.CallOption) (*v1.FlushTokenCacheResponse, error) {
+	out := new(v1.FlushTokenCacheResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgentAdmin/FlushTokenCache", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *athenzAgentAdminClient) GetStatus(ctx context.Context, in *v1.GetStatusRequest, opts ...grpc.CallOption) (*v1.StatusResponse, error) {
+	out := new(v1.StatusResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgentAdmin/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AthenzAgentAdminServer is the server API for AthenzAgentAdmin service.
+// All implementations should embed UnimplementedAthenzAgentAdminServer
+// for forward compatibility
+type AthenzAgentAdminServer interface {
+	GetLogLevel(context.Context, *v1.GetLogLevelRequest) (*v1.LogLevelResponse, error)
+	SetLogLevel(context.Context, *v1.SetLogLevelRequest) (*v1.LogLevelResponse, error)
+	ListDomains(context.Context, *v1.ListDomainsRequest) (*v1.ListDomainsResponse, error)
+	RefreshPolicies(context.Context, *v1.RefreshPoliciesRequest) (*v1.RefreshPoliciesResponse, error)
+	ReloadCache(context.Context, *v1.ReloadCacheRequest) (*v1.ReloadCacheResponse, error)
+	FlushTokenCache(context.Context, *v1.FlushTokenCacheRequest) (*v1.FlushTokenCacheResponse, error)
+	GetStatus(context.Context, *v1.GetStatusRequest) (*v1.StatusResponse, error)
+}
+
+// UnimplementedAthenzAgentAdminServer should be embedded to have forward compatible implementations.
+type UnimplementedAthenzAgentAdminServer struct {
+}
+
+func (UnimplementedAthenzAgentAdminServer) GetLogLevel(context.Context, *v1.GetLogLevelRequest) (*v1.LogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogLevel not implemented")
+}
+func (UnimplementedAthenzAgentAdminServer) SetLogLevel(context.Context, *v1.SetLogLevelRequest) (*v1.LogLevelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedAthenzAgentAdminServer) ListDomains(context.Context, *v1.ListDomainsRequest) (*v1.ListDomainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDomains not implemented")
+}
+func (UnimplementedAthenzAgentAdminServer) RefreshPolicies(context.Context, *v1.RefreshPoliciesRequest) (*v1.RefreshPoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshPolicies not implemented")
+}
+func (UnimplementedAthenzAgentAdminServer) ReloadCache(context.Context, *v1.ReloadCacheRequest) (*v1.ReloadCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadCache not implemented")
+}
+func (UnimplementedAthenzAgentAdminServer) FlushTokenCache(context.Context, *v1.FlushTokenCacheRequest) (*v1.FlushTokenCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FlushTokenCache not implemented")
+}
+func (UnimplementedAthenzAgentAdminServer) GetStatus(context.Context, *v1.GetStatusRequest) (*v1.StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+
+// UnsafeAthenzAgentAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AthenzAgentAdminServer will
+// result in compilation errors.
+type UnsafeAthenzAgentAdminServer interface {
+	mustEmbedUnimplementedAthenzAgentAdminServer()
+}
+
+func RegisterAthenzAgentAdminServer(s grpc.ServiceRegistrar, srv AthenzAgentAdminServer) {
+	s.RegisterService(&AthenzAgentAdmin_ServiceDesc, srv)
+}
+
+func _AthenzAgentAdmin_GetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.GetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentAdminServer).GetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgentAdmin/GetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentAdminServer).GetLogLevel(ctx, req.(*v1.GetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AthenzAgentAdmin_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentAdminServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgentAdmin/SetLogLevel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentAdminServer).SetLogLevel(ctx, req.(*v1.SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AthenzAgentAdmin_ListDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.ListDomainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentAdminServer).ListDomains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgentAdmin/ListDomains",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentAdminServer).ListDomains(ctx, req.(*v1.ListDomainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AthenzAgentAdmin_RefreshPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.RefreshPoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentAdminServer).RefreshPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgentAdmin/RefreshPolicies",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentAdminServer).RefreshPolicies(ctx, req.(*v1.RefreshPoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AthenzAgentAdmin_ReloadCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.ReloadCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentAdminServer).ReloadCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgentAdmin/ReloadCache",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentAdminServer).ReloadCache(ctx, req.(*v1.ReloadCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AthenzAgentAdmin_FlushTokenCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.FlushTokenCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentAdminServer).FlushTokenCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgentAdmin/FlushTokenCache",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentAdminServer).FlushTokenCache(ctx, req.(*v1.FlushTokenCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AthenzAgentAdmin_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentAdminServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgentAdmin/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentAdminServer).GetStatus(ctx, req.(*v1.GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AthenzAgentAdmin_ServiceDesc is the grpc.ServiceDesc for AthenzAgentAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AthenzAgentAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "athenz.agent.api.command.v1.AthenzAgentAdmin",
+	HandlerType: (*AthenzAgentAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLogLevel",
+			Handler:    _AthenzAgentAdmin_GetLogLevel_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _AthenzAgentAdmin_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "ListDomains",
+			Handler:    _AthenzAgentAdmin_ListDomains_Handler,
+		},
+		{
+			MethodName: "RefreshPolicies",
+			Handler:    _AthenzAgentAdmin_RefreshPolicies_Handler,
+		},
+		{
+			MethodName: "ReloadCache",
+			Handler:    _AthenzAgentAdmin_ReloadCache_Handler,
+		},
+		{
+			MethodName: "FlushTokenCache",
+			Handler:    _AthenzAgentAdmin_FlushTokenCache_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _AthenzAgentAdmin_GetStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/athenz/agent/api/command/v1/athenz_agent_admin.proto",
+}
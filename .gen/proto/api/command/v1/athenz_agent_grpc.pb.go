@@ -20,7 +20,9 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AthenzAgentClient interface {
 	CheckAccessWithToken(ctx context.Context, in *v1.AccessCheckRequest, opts ...grpc.CallOption) (*v1.AccessCheckResponse, error)
+	CheckHTTPAccessWithToken(ctx context.Context, in *v1.HTTPAccessCheckRequest, opts ...grpc.CallOption) (*v1.HTTPAccessCheckResponse, error)
 	GetServiceToken(ctx context.Context, in *v1.ServiceTokenRequest, opts ...grpc.CallOption) (*v1.ServiceTokenResponse, error)
+	GetAccessToken(ctx context.Context, in *v1.AccessTokenRequest, opts ...grpc.CallOption) (*v1.AccessTokenResponse, error)
 }
 
 type athenzAgentClient struct {
@@ -40,6 +42,15 @@ func (c *athenzAgentClient) CheckAccessWithToken(ctx context.Context, in *v1.Acc
 	return out, nil
 }
 
+func (c *athenzAgentClient) CheckHTTPAccessWithToken(ctx context.Context, in *v1.HTTPAccessCheckRequest, opts ...grpc.CallOption) (*v1.HTTPAccessCheckResponse, error) {
+	out := new(v1.HTTPAccessCheckResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgent/CheckHTTPAccessWithToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *athenzAgentClient) GetServiceToken(ctx context.Context, in *v1.ServiceTokenRequest, opts ...grpc.CallOption) (*v1.ServiceTokenResponse, error) {
 	out := new(v1.ServiceTokenResponse)
 	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgent/GetServiceToken", in, out, opts...)
@@ -49,12 +60,23 @@ func (c *athenzAgentClient) GetServiceToken(ctx context.Context, in *v1.ServiceT
 	return out, nil
 }
 
+func (c *athenzAgentClient) GetAccessToken(ctx context.Context, in *v1.AccessTokenRequest, opts ...grpc.CallOption) (*v1.AccessTokenResponse, error) {
+	out := new(v1.AccessTokenResponse)
+	err := c.cc.Invoke(ctx, "/athenz.agent.api.command.v1.AthenzAgent/GetAccessToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AthenzAgentServer is the server API for AthenzAgent service.
 // All implementations should embed UnimplementedAthenzAgentServer
 // for forward compatibility
 type AthenzAgentServer interface {
 	CheckAccessWithToken(context.Context, *v1.AccessCheckRequest) (*v1.AccessCheckResponse, error)
+	CheckHTTPAccessWithToken(context.Context, *v1.HTTPAccessCheckRequest) (*v1.HTTPAccessCheckResponse, error)
 	GetServiceToken(context.Context, *v1.ServiceTokenRequest) (*v1.ServiceTokenResponse, error)
+	GetAccessToken(context.Context, *v1.AccessTokenRequest) (*v1.AccessTokenResponse, error)
 }
 
 // UnimplementedAthenzAgentServer should be embedded to have forward compatible implementations.
@@ -64,9 +86,15 @@ type UnimplementedAthenzAgentServer struct {
 func (UnimplementedAthenzAgentServer) CheckAccessWithToken(context.Context, *v1.AccessCheckRequest) (*v1.AccessCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckAccessWithToken not implemented")
 }
+func (UnimplementedAthenzAgentServer) CheckHTTPAccessWithToken(context.Context, *v1.HTTPAccessCheckRequest) (*v1.HTTPAccessCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckHTTPAccessWithToken not implemented")
+}
 func (UnimplementedAthenzAgentServer) GetServiceToken(context.Context, *v1.ServiceTokenRequest) (*v1.ServiceTokenResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetServiceToken not implemented")
 }
+func (UnimplementedAthenzAgentServer) GetAccessToken(context.Context, *v1.AccessTokenRequest) (*v1.AccessTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccessToken not implemented")
+}
 
 // UnsafeAthenzAgentServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to AthenzAgentServer will
@@ -97,6 +125,24 @@ func _AthenzAgent_CheckAccessWithToken_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AthenzAgent_CheckHTTPAccessWithToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.HTTPAccessCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentServer).CheckHTTPAccessWithToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgent/CheckHTTPAccessWithToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentServer).CheckHTTPAccessWithToken(ctx, req.(*v1.HTTPAccessCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _AthenzAgent_GetServiceToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(v1.ServiceTokenRequest)
 	if err := dec(in); err != nil {
@@ -115,6 +161,24 @@ func _AthenzAgent_GetServiceToken_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AthenzAgent_GetAccessToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(v1.AccessTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AthenzAgentServer).GetAccessToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/athenz.agent.api.command.v1.AthenzAgent/GetAccessToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AthenzAgentServer).GetAccessToken(ctx, req.(*v1.AccessTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AthenzAgent_ServiceDesc is the grpc.ServiceDesc for AthenzAgent service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -126,10 +190,18 @@ var AthenzAgent_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckAccessWithToken",
 			Handler:    _AthenzAgent_CheckAccessWithToken_Handler,
 		},
+		{
+			MethodName: "CheckHTTPAccessWithToken",
+			Handler:    _AthenzAgent_CheckHTTPAccessWithToken_Handler,
+		},
 		{
 			MethodName: "GetServiceToken",
 			Handler:    _AthenzAgent_GetServiceToken_Handler,
 		},
+		{
+			MethodName: "GetAccessToken",
+			Handler:    _AthenzAgent_GetAccessToken_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/athenz/agent/api/command/v1/athenz_agent.proto",
@@ -33,21 +33,24 @@ const (
 	AccessStatus_DENY_NO_MATCH           AccessStatus = 7
 	AccessStatus_DENY_DOMAIN_EMPTY       AccessStatus = 8
 	AccessStatus_DENY_DOMAIN_EXPIRED     AccessStatus = 9
+	// the policies of the domain are being downloaded on demand, retry later
+	AccessStatus_DENY_DOMAIN_LOADING AccessStatus = 10
 )
 
 // Enum value maps for AccessStatus.
 var (
 	AccessStatus_name = map[int32]string{
-		0: "ALLOW",
-		1: "DENY",
-		2: "DENY_ROLE_TOKEN_EXPIRED",
-		3: "DENY_ROLE_TOKEN_INVALID",
-		4: "DENY_INVALID_PARAMETERS",
-		5: "DENY_DOMAIN_MISMATCH",
-		6: "DENY_DOMAIN_NOT_FOUND",
-		7: "DENY_NO_MATCH",
-		8: "DENY_DOMAIN_EMPTY",
-		9: "DENY_DOMAIN_EXPIRED",
+		0:  "ALLOW",
+		1:  "DENY",
+		2:  "DENY_ROLE_TOKEN_EXPIRED",
+		3:  "DENY_ROLE_TOKEN_INVALID",
+		4:  "DENY_INVALID_PARAMETERS",
+		5:  "DENY_DOMAIN_MISMATCH",
+		6:  "DENY_DOMAIN_NOT_FOUND",
+		7:  "DENY_NO_MATCH",
+		8:  "DENY_DOMAIN_EMPTY",
+		9:  "DENY_DOMAIN_EXPIRED",
+		10: "DENY_DOMAIN_LOADING",
 	}
 	AccessStatus_value = map[string]int32{
 		"ALLOW":                   0,
@@ -60,6 +63,7 @@ var (
 		"DENY_NO_MATCH":           7,
 		"DENY_DOMAIN_EMPTY":       8,
 		"DENY_DOMAIN_EXPIRED":     9,
+		"DENY_DOMAIN_LOADING":     10,
 	}
 )
 
@@ -90,6 +94,63 @@ func (AccessStatus) EnumDescriptor() ([]byte, []int) {
 	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{0}
 }
 
+// StalePolicyMode is the stale policy mode that an access check used, the
+// policies of the domain are expired unless it's FRESH.
+type StalePolicyMode int32
+
+const (
+	StalePolicyMode_FRESH StalePolicyMode = 0
+	// expired policies deny the access
+	StalePolicyMode_STRICT StalePolicyMode = 1
+	// expired policies are evaluated in the grace period of the domain
+	StalePolicyMode_GRACE StalePolicyMode = 2
+	// the access is allowed while the policies of the domain are expired
+	StalePolicyMode_FAIL_OPEN StalePolicyMode = 3
+)
+
+// Enum value maps for StalePolicyMode.
+var (
+	StalePolicyMode_name = map[int32]string{
+		0: "FRESH",
+		1: "STRICT",
+		2: "GRACE",
+		3: "FAIL_OPEN",
+	}
+	StalePolicyMode_value = map[string]int32{
+		"FRESH":     0,
+		"STRICT":    1,
+		"GRACE":     2,
+		"FAIL_OPEN": 3,
+	}
+)
+
+func (x StalePolicyMode) Enum() *StalePolicyMode {
+	p := new(StalePolicyMode)
+	*p = x
+	return p
+}
+
+func (x StalePolicyMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StalePolicyMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_enumTypes[1].Descriptor()
+}
+
+func (StalePolicyMode) Type() protoreflect.EnumType {
+	return &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_enumTypes[1]
+}
+
+func (x StalePolicyMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StalePolicyMode.Descriptor instead.
+func (StalePolicyMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{1}
+}
+
 type AccessCheckRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -158,7 +219,8 @@ type AccessCheckResponse struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	AccessCheckStatus AccessStatus `protobuf:"varint,1,opt,name=access_check_status,json=accessCheckStatus,proto3,enum=athenz.agent.api.message.v1.AccessStatus" json:"access_check_status,omitempty"`
+	AccessCheckStatus AccessStatus    `protobuf:"varint,1,opt,name=access_check_status,json=accessCheckStatus,proto3,enum=athenz.agent.api.message.v1.AccessStatus" json:"access_check_status,omitempty"`
+	StalePolicyMode   StalePolicyMode `protobuf:"varint,2,opt,name=stale_policy_mode,json=stalePolicyMode,proto3,enum=athenz.agent.api.message.v1.StalePolicyMode" json:"stale_policy_mode,omitempty"`
 }
 
 func (x *AccessCheckResponse) Reset() {
@@ -200,16 +262,182 @@ func (x *AccessCheckResponse) GetAccessCheckStatus() AccessStatus {
 	return AccessStatus_ALLOW
 }
 
+func (x *AccessCheckResponse) GetStalePolicyMode() StalePolicyMode {
+	if x != nil {
+		return x.StalePolicyMode
+	}
+	return StalePolicyMode_FRESH
+}
+
+// HTTPAccessCheckRequest describes an HTTP request, it's mapped to access and
+// resource by http_mappings rules of zpe config. Empty token is read from the
+// role token header or authorization header.
+type HTTPAccessCheckRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Token  string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Method string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	// absolute path, it may have query
+	Path    string            `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	Headers map[string]string `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *HTTPAccessCheckRequest) Reset() {
+	*x = HTTPAccessCheckRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HTTPAccessCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTTPAccessCheckRequest) ProtoMessage() {}
+
+func (x *HTTPAccessCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HTTPAccessCheckRequest.ProtoReflect.Descriptor instead.
+func (*HTTPAccessCheckRequest) Descriptor() ([]byte, []int) {
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HTTPAccessCheckRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *HTTPAccessCheckRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *HTTPAccessCheckRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HTTPAccessCheckRequest) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+// HTTPAccessCheckResponse has the access and resource that the request is
+// mapped to.
+type HTTPAccessCheckResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccessCheckStatus AccessStatus    `protobuf:"varint,1,opt,name=access_check_status,json=accessCheckStatus,proto3,enum=athenz.agent.api.message.v1.AccessStatus" json:"access_check_status,omitempty"`
+	Access            string          `protobuf:"bytes,2,opt,name=access,proto3" json:"access,omitempty"`
+	Resource          string          `protobuf:"bytes,3,opt,name=resource,proto3" json:"resource,omitempty"`
+	StalePolicyMode   StalePolicyMode `protobuf:"varint,4,opt,name=stale_policy_mode,json=stalePolicyMode,proto3,enum=athenz.agent.api.message.v1.StalePolicyMode" json:"stale_policy_mode,omitempty"`
+}
+
+func (x *HTTPAccessCheckResponse) Reset() {
+	*x = HTTPAccessCheckResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HTTPAccessCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTTPAccessCheckResponse) ProtoMessage() {}
+
+func (x *HTTPAccessCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HTTPAccessCheckResponse.ProtoReflect.Descriptor instead.
+func (*HTTPAccessCheckResponse) Descriptor() ([]byte, []int) {
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HTTPAccessCheckResponse) GetAccessCheckStatus() AccessStatus {
+	if x != nil {
+		return x.AccessCheckStatus
+	}
+	return AccessStatus_ALLOW
+}
+
+func (x *HTTPAccessCheckResponse) GetAccess() string {
+	if x != nil {
+		return x.Access
+	}
+	return ""
+}
+
+func (x *HTTPAccessCheckResponse) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *HTTPAccessCheckResponse) GetStalePolicyMode() StalePolicyMode {
+	if x != nil {
+		return x.StalePolicyMode
+	}
+	return StalePolicyMode_FRESH
+}
+
+// ServiceTokenRequest fields are optional, empty domain and roles use the
+// domain and roles of zpe config. Requesting other domains, roles or a proxy
+// principal must be allowed by token_allow_list of zpe config.
 type ServiceTokenRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Domain string   `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Roles  []string `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`
+	// in seconds format, 0 uses token_expiration_min of zpe config
+	MinExpiryTime int32 `protobuf:"varint,3,opt,name=min_expiry_time,json=minExpiryTime,proto3" json:"min_expiry_time,omitempty"`
+	// in seconds format, 0 uses token_expiration_max of zpe config
+	MaxExpiryTime     int32  `protobuf:"varint,4,opt,name=max_expiry_time,json=maxExpiryTime,proto3" json:"max_expiry_time,omitempty"`
+	ProxyForPrincipal string `protobuf:"bytes,5,opt,name=proxy_for_principal,json=proxyForPrincipal,proto3" json:"proxy_for_principal,omitempty"`
 }
 
 func (x *ServiceTokenRequest) Reset() {
 	*x = ServiceTokenRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[2]
+		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -222,7 +450,7 @@ func (x *ServiceTokenRequest) String() string {
 func (*ServiceTokenRequest) ProtoMessage() {}
 
 func (x *ServiceTokenRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[2]
+	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -235,7 +463,42 @@ func (x *ServiceTokenRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServiceTokenRequest.ProtoReflect.Descriptor instead.
 func (*ServiceTokenRequest) Descriptor() ([]byte, []int) {
-	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{2}
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ServiceTokenRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *ServiceTokenRequest) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *ServiceTokenRequest) GetMinExpiryTime() int32 {
+	if x != nil {
+		return x.MinExpiryTime
+	}
+	return 0
+}
+
+func (x *ServiceTokenRequest) GetMaxExpiryTime() int32 {
+	if x != nil {
+		return x.MaxExpiryTime
+	}
+	return 0
+}
+
+func (x *ServiceTokenRequest) GetProxyForPrincipal() string {
+	if x != nil {
+		return x.ProxyForPrincipal
+	}
+	return ""
 }
 
 type ServiceTokenResponse struct {
@@ -249,7 +512,7 @@ type ServiceTokenResponse struct {
 func (x *ServiceTokenResponse) Reset() {
 	*x = ServiceTokenResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[3]
+		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -262,7 +525,7 @@ func (x *ServiceTokenResponse) String() string {
 func (*ServiceTokenResponse) ProtoMessage() {}
 
 func (x *ServiceTokenResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[3]
+	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -275,7 +538,7 @@ func (x *ServiceTokenResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ServiceTokenResponse.ProtoReflect.Descriptor instead.
 func (*ServiceTokenResponse) Descriptor() ([]byte, []int) {
-	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{3}
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ServiceTokenResponse) GetToken() string {
@@ -285,6 +548,161 @@ func (x *ServiceTokenResponse) GetToken() string {
 	return ""
 }
 
+// AccessTokenRequest fields are optional like ServiceTokenRequest, empty
+// domain and roles use the domain and roles of zpe config.
+type AccessTokenRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Domain string   `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Roles  []string `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"`
+	// in seconds format, 0 uses token_expiration_max of zpe config
+	ExpiryTime int32 `protobuf:"varint,3,opt,name=expiry_time,json=expiryTime,proto3" json:"expiry_time,omitempty"`
+	// service of the domain that the ID token is issued for, empty means no ID token
+	IdTokenService    string `protobuf:"bytes,4,opt,name=id_token_service,json=idTokenService,proto3" json:"id_token_service,omitempty"`
+	ProxyForPrincipal string `protobuf:"bytes,5,opt,name=proxy_for_principal,json=proxyForPrincipal,proto3" json:"proxy_for_principal,omitempty"`
+}
+
+func (x *AccessTokenRequest) Reset() {
+	*x = AccessTokenRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccessTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessTokenRequest) ProtoMessage() {}
+
+func (x *AccessTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessTokenRequest.ProtoReflect.Descriptor instead.
+func (*AccessTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AccessTokenRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *AccessTokenRequest) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *AccessTokenRequest) GetExpiryTime() int32 {
+	if x != nil {
+		return x.ExpiryTime
+	}
+	return 0
+}
+
+func (x *AccessTokenRequest) GetIdTokenService() string {
+	if x != nil {
+		return x.IdTokenService
+	}
+	return ""
+}
+
+func (x *AccessTokenRequest) GetProxyForPrincipal() string {
+	if x != nil {
+		return x.ProxyForPrincipal
+	}
+	return ""
+}
+
+type AccessTokenResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AccessToken string `protobuf:"bytes,1,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	IdToken     string `protobuf:"bytes,2,opt,name=id_token,json=idToken,proto3" json:"id_token,omitempty"`
+	TokenType   string `protobuf:"bytes,3,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
+	// in unix seconds format
+	ExpiryTime int64 `protobuf:"varint,4,opt,name=expiry_time,json=expiryTime,proto3" json:"expiry_time,omitempty"`
+}
+
+func (x *AccessTokenResponse) Reset() {
+	*x = AccessTokenResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AccessTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessTokenResponse) ProtoMessage() {}
+
+func (x *AccessTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessTokenResponse.ProtoReflect.Descriptor instead.
+func (*AccessTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AccessTokenResponse) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *AccessTokenResponse) GetIdToken() string {
+	if x != nil {
+		return x.IdToken
+	}
+	return ""
+}
+
+func (x *AccessTokenResponse) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+func (x *AccessTokenResponse) GetExpiryTime() int64 {
+	if x != nil {
+		return x.ExpiryTime
+	}
+	return 0
+}
+
 var File_proto_athenz_agent_api_message_v1_athenz_agent_proto protoreflect.FileDescriptor
 
 var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDesc = []byte{
@@ -299,38 +717,114 @@ var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDesc = []byte{
 	0x16, 0x0a, 0x06, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
 	0x06, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75,
 	0x72, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75,
-	0x72, 0x63, 0x65, 0x22, 0x70, 0x0a, 0x13, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x68, 0x65,
-	0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x13, 0x61, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a,
-	0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x52, 0x11, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0x15, 0x0a, 0x13, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x2c, 0x0a, 0x14,
-	0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x2a, 0xf2, 0x01, 0x0a, 0x0c, 0x41,
-	0x63, 0x63, 0x65, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x09, 0x0a, 0x05, 0x41,
-	0x4c, 0x4c, 0x4f, 0x57, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x45, 0x4e, 0x59, 0x10, 0x01,
-	0x12, 0x1b, 0x0a, 0x17, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x54, 0x4f,
-	0x4b, 0x45, 0x4e, 0x5f, 0x45, 0x58, 0x50, 0x49, 0x52, 0x45, 0x44, 0x10, 0x02, 0x12, 0x1b, 0x0a,
-	0x17, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e,
-	0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x03, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x45,
-	0x4e, 0x59, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x41, 0x4d,
-	0x45, 0x54, 0x45, 0x52, 0x53, 0x10, 0x04, 0x12, 0x18, 0x0a, 0x14, 0x44, 0x45, 0x4e, 0x59, 0x5f,
-	0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e, 0x5f, 0x4d, 0x49, 0x53, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x10,
-	0x05, 0x12, 0x19, 0x0a, 0x15, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e,
-	0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x06, 0x12, 0x11, 0x0a, 0x0d,
-	0x44, 0x45, 0x4e, 0x59, 0x5f, 0x4e, 0x4f, 0x5f, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x10, 0x07, 0x12,
-	0x15, 0x0a, 0x11, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e, 0x5f, 0x45,
-	0x4d, 0x50, 0x54, 0x59, 0x10, 0x08, 0x12, 0x17, 0x0a, 0x13, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x44,
-	0x4f, 0x4d, 0x41, 0x49, 0x4e, 0x5f, 0x45, 0x58, 0x50, 0x49, 0x52, 0x45, 0x44, 0x10, 0x09, 0x42,
-	0x44, 0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x61,
-	0x6d, 0x65, 0x64, 0x2d, 0x79, 0x6f, 0x75, 0x73, 0x65, 0x66, 0x69, 0x2f, 0x61, 0x74, 0x68, 0x65,
-	0x6e, 0x7a, 0x2d, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2f, 0x2e, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2f,
-	0x76, 0x31, 0x3b, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x72, 0x63, 0x65, 0x22, 0xca, 0x01, 0x0a, 0x13, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x13, 0x61,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x5f, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e,
+	0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x11, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x58, 0x0a, 0x11, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x5f,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x2c, 0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74,
+	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x74, 0x61, 0x6c, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x52,
+	0x0f, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x4d, 0x6f, 0x64, 0x65,
+	0x22, 0xf2, 0x01, 0x0a, 0x16, 0x48, 0x54, 0x54, 0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43,
+	0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74,
+	0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x5a, 0x0a,
+	0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x40,
+	0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x54, 0x54,
+	0x50, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x82, 0x02, 0x0a, 0x17, 0x48, 0x54, 0x54, 0x50, 0x41, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x59, 0x0a, 0x13, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29,
+	0x2e, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x11, 0x61, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06,
+	0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x12, 0x58, 0x0a, 0x11, 0x73, 0x74, 0x61, 0x6c, 0x65, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2c, 0x2e, 0x61, 0x74,
+	0x68, 0x65, 0x6e, 0x7a, 0x2e, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x6c, 0x65, 0x50,
+	0x6f, 0x6c, 0x69, 0x63, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0f, 0x73, 0x74, 0x61, 0x6c, 0x65,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x4d, 0x6f, 0x64, 0x65, 0x22, 0xc3, 0x01, 0x0a, 0x13, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f,
+	0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73,
+	0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x69, 0x6e, 0x5f, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x6d, 0x69, 0x6e, 0x45, 0x78,
+	0x70, 0x69, 0x72, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f,
+	0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x45, 0x78, 0x70, 0x69, 0x72, 0x79, 0x54, 0x69, 0x6d, 0x65,
+	0x12, 0x2e, 0x0a, 0x13, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x5f, 0x66, 0x6f, 0x72, 0x5f, 0x70, 0x72,
+	0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x70,
+	0x72, 0x6f, 0x78, 0x79, 0x46, 0x6f, 0x72, 0x50, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c,
+	0x22, 0x2c, 0x0a, 0x14, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0xbd,
+	0x01, 0x0a, 0x12, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x14, 0x0a,
+	0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x72, 0x6f,
+	0x6c, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x69, 0x64, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e,
+	0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x69, 0x64, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x2e,
+	0x0a, 0x13, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x5f, 0x66, 0x6f, 0x72, 0x5f, 0x70, 0x72, 0x69, 0x6e,
+	0x63, 0x69, 0x70, 0x61, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x70, 0x72, 0x6f,
+	0x78, 0x79, 0x46, 0x6f, 0x72, 0x50, 0x72, 0x69, 0x6e, 0x63, 0x69, 0x70, 0x61, 0x6c, 0x22, 0x93,
+	0x01, 0x0a, 0x13, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x64, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x64, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x79,
+	0x54, 0x69, 0x6d, 0x65, 0x2a, 0x8b, 0x02, 0x0a, 0x0c, 0x41, 0x63, 0x63, 0x65, 0x73, 0x73, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x09, 0x0a, 0x05, 0x41, 0x4c, 0x4c, 0x4f, 0x57, 0x10, 0x00,
+	0x12, 0x08, 0x0a, 0x04, 0x44, 0x45, 0x4e, 0x59, 0x10, 0x01, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x45,
+	0x4e, 0x59, 0x5f, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x45, 0x58,
+	0x50, 0x49, 0x52, 0x45, 0x44, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x45, 0x4e, 0x59, 0x5f,
+	0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c,
+	0x49, 0x44, 0x10, 0x03, 0x12, 0x1b, 0x0a, 0x17, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x49, 0x4e, 0x56,
+	0x41, 0x4c, 0x49, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x41, 0x4d, 0x45, 0x54, 0x45, 0x52, 0x53, 0x10,
+	0x04, 0x12, 0x18, 0x0a, 0x14, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e,
+	0x5f, 0x4d, 0x49, 0x53, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x10, 0x05, 0x12, 0x19, 0x0a, 0x15, 0x44,
+	0x45, 0x4e, 0x59, 0x5f, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46,
+	0x4f, 0x55, 0x4e, 0x44, 0x10, 0x06, 0x12, 0x11, 0x0a, 0x0d, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x4e,
+	0x4f, 0x5f, 0x4d, 0x41, 0x54, 0x43, 0x48, 0x10, 0x07, 0x12, 0x15, 0x0a, 0x11, 0x44, 0x45, 0x4e,
+	0x59, 0x5f, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e, 0x5f, 0x45, 0x4d, 0x50, 0x54, 0x59, 0x10, 0x08,
+	0x12, 0x17, 0x0a, 0x13, 0x44, 0x45, 0x4e, 0x59, 0x5f, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e, 0x5f,
+	0x45, 0x58, 0x50, 0x49, 0x52, 0x45, 0x44, 0x10, 0x09, 0x12, 0x17, 0x0a, 0x13, 0x44, 0x45, 0x4e,
+	0x59, 0x5f, 0x44, 0x4f, 0x4d, 0x41, 0x49, 0x4e, 0x5f, 0x4c, 0x4f, 0x41, 0x44, 0x49, 0x4e, 0x47,
+	0x10, 0x0a, 0x2a, 0x42, 0x0a, 0x0f, 0x53, 0x74, 0x61, 0x6c, 0x65, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x09, 0x0a, 0x05, 0x46, 0x52, 0x45, 0x53, 0x48, 0x10, 0x00,
+	0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x52, 0x49, 0x43, 0x54, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05,
+	0x47, 0x52, 0x41, 0x43, 0x45, 0x10, 0x02, 0x12, 0x0d, 0x0a, 0x09, 0x46, 0x41, 0x49, 0x4c, 0x5f,
+	0x4f, 0x50, 0x45, 0x4e, 0x10, 0x03, 0x42, 0x44, 0x5a, 0x42, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x68, 0x61, 0x6d, 0x65, 0x64, 0x2d, 0x79, 0x6f, 0x75, 0x73, 0x65,
+	0x66, 0x69, 0x2f, 0x61, 0x74, 0x68, 0x65, 0x6e, 0x7a, 0x2d, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2f,
+	0x2e, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2f, 0x76, 0x31, 0x3b, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -345,22 +839,32 @@ func file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescGZIP() []b
 	return file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDescData
 }
 
-var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_goTypes = []interface{}{
-	(AccessStatus)(0),            // 0: athenz.agent.api.message.v1.AccessStatus
-	(*AccessCheckRequest)(nil),   // 1: athenz.agent.api.message.v1.AccessCheckRequest
-	(*AccessCheckResponse)(nil),  // 2: athenz.agent.api.message.v1.AccessCheckResponse
-	(*ServiceTokenRequest)(nil),  // 3: athenz.agent.api.message.v1.ServiceTokenRequest
-	(*ServiceTokenResponse)(nil), // 4: athenz.agent.api.message.v1.ServiceTokenResponse
+	(AccessStatus)(0),               // 0: athenz.agent.api.message.v1.AccessStatus
+	(StalePolicyMode)(0),            // 1: athenz.agent.api.message.v1.StalePolicyMode
+	(*AccessCheckRequest)(nil),      // 2: athenz.agent.api.message.v1.AccessCheckRequest
+	(*AccessCheckResponse)(nil),     // 3: athenz.agent.api.message.v1.AccessCheckResponse
+	(*HTTPAccessCheckRequest)(nil),  // 4: athenz.agent.api.message.v1.HTTPAccessCheckRequest
+	(*HTTPAccessCheckResponse)(nil), // 5: athenz.agent.api.message.v1.HTTPAccessCheckResponse
+	(*ServiceTokenRequest)(nil),     // 6: athenz.agent.api.message.v1.ServiceTokenRequest
+	(*ServiceTokenResponse)(nil),    // 7: athenz.agent.api.message.v1.ServiceTokenResponse
+	(*AccessTokenRequest)(nil),      // 8: athenz.agent.api.message.v1.AccessTokenRequest
+	(*AccessTokenResponse)(nil),     // 9: athenz.agent.api.message.v1.AccessTokenResponse
+	nil,                             // 10: athenz.agent.api.message.v1.HTTPAccessCheckRequest.HeadersEntry
 }
 var file_proto_athenz_agent_api_message_v1_athenz_agent_proto_depIdxs = []int32{
-	0, // 0: athenz.agent.api.message.v1.AccessCheckResponse.access_check_status:type_name -> athenz.agent.api.message.v1.AccessStatus
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: athenz.agent.api.message.v1.AccessCheckResponse.access_check_status:type_name -> athenz.agent.api.message.v1.AccessStatus
+	1,  // 1: athenz.agent.api.message.v1.AccessCheckResponse.stale_policy_mode:type_name -> athenz.agent.api.message.v1.StalePolicyMode
+	10, // 2: athenz.agent.api.message.v1.HTTPAccessCheckRequest.headers:type_name -> athenz.agent.api.message.v1.HTTPAccessCheckRequest.HeadersEntry
+	0,  // 3: athenz.agent.api.message.v1.HTTPAccessCheckResponse.access_check_status:type_name -> athenz.agent.api.message.v1.AccessStatus
+	1,  // 4: athenz.agent.api.message.v1.HTTPAccessCheckResponse.stale_policy_mode:type_name -> athenz.agent.api.message.v1.StalePolicyMode
+	5,  // [5:5] is the sub-list for method output_type
+	5,  // [5:5] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_proto_athenz_agent_api_message_v1_athenz_agent_proto_init() }
@@ -394,7 +898,7 @@ func file_proto_athenz_agent_api_message_v1_athenz_agent_proto_init() {
 			}
 		}
 		file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ServiceTokenRequest); i {
+			switch v := v.(*HTTPAccessCheckRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -406,6 +910,30 @@ func file_proto_athenz_agent_api_message_v1_athenz_agent_proto_init() {
 			}
 		}
 		file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HTTPAccessCheckResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServiceTokenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ServiceTokenResponse); i {
 			case 0:
 				return &v.state
@@ -417,14 +945,38 @@ func file_proto_athenz_agent_api_message_v1_athenz_agent_proto_init() {
 				return nil
 			}
 		}
+		file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AccessTokenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_athenz_agent_api_message_v1_athenz_agent_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AccessTokenResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_athenz_agent_api_message_v1_athenz_agent_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   4,
+			NumEnums:      2,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
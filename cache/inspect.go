@@ -0,0 +1,221 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:40 AM
+ *
+ * Description:
+ * InspectPolicyFile reports what loadFile would do with a policy file
+ * without caching it: its signatures, its assertions and lint warnings for
+ * assertions that never match or match too much.
+ *
+ */
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/matcher"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureVerified is the status of a signature that is verified.
+	SignatureVerified = "verified"
+	// SignatureInvalid is the status of a signature that doesn't match the
+	// data.
+	SignatureInvalid = "invalid"
+)
+
+type (
+	// PolicyReport is the inspection result of a policy file.
+	PolicyReport struct {
+		File     string    `json:"file"`
+		Domain   string    `json:"domain"`
+		Modified time.Time `json:"modified"`
+		Expires  time.Time `json:"expires"`
+		Expired  bool      `json:"expired"`
+		ZtsKeyID string    `json:"zts_key_id"`
+		ZmsKeyID string    `json:"zms_key_id"`
+		// ZtsSignature and ZmsSignature are SignatureVerified,
+		// SignatureInvalid or the error of the verification, e.g. an unknown
+		// key id
+		ZtsSignature string          `json:"zts_signature"`
+		ZmsSignature string          `json:"zms_signature"`
+		Policies     int             `json:"policies"`
+		Assertions   AssertionCounts `json:"assertions"`
+		Warnings     []string        `json:"warnings"`
+	}
+
+	// AssertionCounts counts the assertions of a policy file by effect and by
+	// role type, wildcard roles have * or ? in their name.
+	AssertionCounts struct {
+		Allow    int `json:"allow"`
+		Deny     int `json:"deny"`
+		Standard int `json:"standard"`
+		Wildcard int `json:"wildcard"`
+	}
+
+	// inspectedAssertion is an assertion with the values that loadFile
+	// caches, matchers of invalid patterns are nil.
+	inspectedAssertion struct {
+		label                                 string
+		role, action, resource                string
+		deny                                  bool
+		roleMatch, actionMatch, resourceMatch matcher.ZpeMatch
+	}
+)
+
+// Verified checks both signatures are verified.
+func (r *PolicyReport) Verified() bool {
+	return r.ZtsSignature == SignatureVerified && r.ZmsSignature == SignatureVerified
+}
+
+// InspectPolicyFile decodes the policy file, verifies its signatures by the
+// public keys of config.KeyStore and lints its assertions. It fails only if
+// the file can't be decoded.
+func InspectPolicyFile(path string) (*PolicyReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, common.Errorf("unable to open file: %s, error: %s", path, err.Error())
+	}
+	defer file.Close()
+
+	var domainSignedPolicyData *zts.DomainSignedPolicyData
+	if err := json.NewDecoder(file).Decode(&domainSignedPolicyData); err != nil {
+		return nil, common.Errorf("unable to decode policy file: %s, error: %s", path, err.Error())
+	}
+	if domainSignedPolicyData == nil || domainSignedPolicyData.SignedPolicyData == nil ||
+		domainSignedPolicyData.SignedPolicyData.PolicyData == nil {
+		return nil, common.Errorf("policy file has no policy data: %s", path)
+	}
+
+	signedPolicyData := domainSignedPolicyData.SignedPolicyData
+	policyData := signedPolicyData.PolicyData
+	report := &PolicyReport{File: path, Domain: string(policyData.Domain),
+		Modified: signedPolicyData.Modified.Time, Expires: signedPolicyData.Expires.Time,
+		Expired: signedPolicyData.Expires.Time.Before(time.Now()), ZtsKeyID: domainSignedPolicyData.KeyId,
+		ZmsKeyID: signedPolicyData.ZmsKeyId, Policies: len(policyData.Policies), Warnings: []string{}}
+	report.ZtsSignature = signatureStatus(verifyZtsSignature(domainSignedPolicyData))
+	report.ZmsSignature = signatureStatus(verifyZmsSignature(signedPolicyData))
+
+	assertions := make([]*inspectedAssertion, 0)
+	for _, policy := range policyData.Policies {
+		for i, assertion := range policy.Assertions {
+			a := report.inspect(fmt.Sprintf("policy '%s' assertion %d", policy.Name, i), assertion)
+			assertions = append(assertions, a)
+		}
+	}
+	report.lintShadowed(assertions)
+	return report, nil
+}
+
+// inspect counts the assertion and adds the warnings of its values, the
+// values are converted like loadFile does.
+func (r *PolicyReport) inspect(label string, assertion *zts.Assertion) *inspectedAssertion {
+	a := &inspectedAssertion{label: label, action: assertion.Action,
+		resource: common.StripDomainPrefix(assertion.Resource, r.Domain, assertion.Resource),
+		deny:     assertion.Effect != nil && assertion.Effect.String() == "DENY"}
+	a.role = regexp.MustCompile("^role.").ReplaceAllString(
+		common.StripDomainPrefix(assertion.Role, r.Domain, assertion.Role), "$1")
+
+	var err error
+	if a.roleMatch, err = newMatchObject(a.role); err != nil {
+		r.warnf("%s: invalid role pattern '%s', error: %s", label, a.role, err.Error())
+	}
+	if a.actionMatch, err = newMatchObject(a.action); err != nil {
+		r.warnf("%s: invalid action pattern '%s', error: %s", label, a.action, err.Error())
+	}
+	if a.resourceMatch, err = newMatchObject(a.resource); err != nil {
+		r.warnf("%s: invalid resource pattern '%s', error: %s", label, a.resource, err.Error())
+	}
+
+	if a.deny {
+		r.Assertions.Deny++
+	} else {
+		r.Assertions.Allow++
+	}
+	if _, ok := a.roleMatch.(matcher.ZpeMatchEqual); ok {
+		r.Assertions.Standard++
+	} else {
+		r.Assertions.Wildcard++
+	}
+
+	// requests are checked with lower case action and resource, the resource
+	// of a request is always in the domain of its role token
+	if strings.Contains(assertion.Role, ":") && !strings.HasPrefix(assertion.Role, r.Domain+":") {
+		r.warnf("%s: role '%s' is not in domain '%s', it never matches", label, assertion.Role, r.Domain)
+	}
+	if strings.Contains(a.resource, ":") {
+		r.warnf("%s: resource '%s' is not in domain '%s', it never matches", label, assertion.Resource,
+			r.Domain)
+	}
+	if a.action != strings.ToLower(a.action) || a.resource != strings.ToLower(a.resource) {
+		r.warnf("%s: action '%s' or resource '%s' has upper case letters, it never matches lower case "+
+			"requests", label, a.action, assertion.Resource)
+	}
+	if !a.deny && a.resource == "*" {
+		r.warnf("%s: role '%s' is allowed to '%s' all resources of the domain", label, a.role, a.action)
+	}
+	return a
+}
+
+// lintShadowed warns about the duplicate assertions and the allow assertions
+// that are always denied by a deny assertion, deny assertions are checked
+// first.
+func (r *PolicyReport) lintShadowed(assertions []*inspectedAssertion) {
+	for i, a := range assertions {
+		for _, other := range assertions[:i] {
+			if a.deny == other.deny && a.role == other.role && a.action == other.action &&
+				a.resource == other.resource {
+				r.warnf("%s: duplicate of %s", a.label, other.label)
+				break
+			}
+		}
+		if a.deny {
+			continue
+		}
+		for _, deny := range assertions {
+			if deny.deny && deny.covers(a) {
+				r.warnf("%s: unreachable, %s denies it", a.label, deny.label)
+				break
+			}
+		}
+	}
+}
+
+// covers checks the patterns of the assertion match all values of the
+// patterns of other.
+func (a *inspectedAssertion) covers(other *inspectedAssertion) bool {
+	if a.roleMatch == nil || a.actionMatch == nil || a.resourceMatch == nil {
+		return false
+	}
+	return a.roleMatch.Match(other.role) && a.actionMatch.Match(other.action) &&
+		a.resourceMatch.Match(other.resource)
+}
+
+func (r *PolicyReport) warnf(format string, params ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, params...))
+}
+
+// signatureStatus returns the status of a signature verification.
+func signatureStatus(verified bool, err error) string {
+	switch {
+	case err != nil:
+		return err.Error()
+	case verified:
+		return SignatureVerified
+	}
+	return SignatureInvalid
+}
@@ -0,0 +1,72 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 10:05 AM
+ *
+ * Description:
+ *
+ */
+
+package cache
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+func TestInspectPolicyFile(t *testing.T) {
+	setup()
+	a := assert.New(t)
+
+	policyDir, err := ioutil.TempDir("./", policyDirPrefix)
+	a.NoError(err)
+	defer common.RemoveAll(policyDir)
+
+	policyPath := policyDir + "/sports.pol"
+	err = common.CreateFile(policyPath, `{"signedPolicyData":{"expires":"2017-06-09T06:11:12.125Z",
+"modified":"2017-06-02T06:11:12.125Z","policyData":{"domain":"sports","policies":[{"name":"sports:policy.readers",
+"assertions":[
+{"action":"read","effect":"ALLOW","resource":"sports:articles.*","role":"sports:role.reader"},
+{"action":"read","effect":"DENY","resource":"sports:articles.*","role":"sports:role.*"},
+{"action":"read","resource":"sports:articles.*","role":"sports:role.reader"},
+{"action":"Write","resource":"sports:(drafts*.old","role":"weather:role.writer"},
+{"action":"*","resource":"sports:*","role":"sports:role.admin"},
+{"action":"read","resource":"weather:forecast","role":"sports:role.reader"}]}]},
+"zmsSignature":"unsigned","zmsKeyId":"0"},"signature":"unsigned","keyId":"7"}`)
+	a.NoError(err)
+
+	report, err := InspectPolicyFile(policyPath)
+	a.NoError(err)
+	a.Equal("sports", report.Domain)
+	a.True(report.Expired)
+	a.Equal("7", report.ZtsKeyID)
+	a.Equal(1, report.Policies)
+	a.Equal(AssertionCounts{Allow: 5, Deny: 1, Standard: 5, Wildcard: 1}, report.Assertions)
+	a.False(report.Verified())
+	a.NotEqual(SignatureVerified, report.ZtsSignature)
+
+	prefix := "policy 'sports:policy.readers' assertion "
+	a.Equal([]string{
+		prefix + "3: invalid resource pattern '(drafts*.old', error: error parsing regexp: " +
+			"missing closing ): `^(drafts.*\\.old$`",
+		prefix + "3: role 'weather:role.writer' is not in domain 'sports', it never matches",
+		prefix + "3: action 'Write' or resource 'sports:(drafts*.old' has upper case letters, it never matches " +
+			"lower case requests",
+		prefix + "4: role 'admin' is allowed to '*' all resources of the domain",
+		prefix + "5: resource 'weather:forecast' is not in domain 'sports', it never matches",
+		prefix + "0: unreachable, " + prefix + "1 denies it",
+		prefix + "2: duplicate of " + prefix + "0",
+		prefix + "2: unreachable, " + prefix + "1 denies it",
+	}, report.Warnings)
+
+	_, err = InspectPolicyFile(policyDir + "/missing.pol")
+	a.Error(err)
+}
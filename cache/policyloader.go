@@ -28,24 +28,32 @@ import (
 	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/hamed-yousefi/athenz-agent/common/log"
 	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
 	"github.com/hamed-yousefi/athenz-agent/matcher"
 	"github.com/hamed-yousefi/athenz-agent/token"
 	"github.com/yahoo/athenz/clients/go/zts"
-	"github.com/yahoo/athenz/libs/go/zmssvctoken"
 	zpuUtil "github.com/yahoo/athenz/utils/zpe-updater/util"
 
 	"os"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var (
 	logger           = log.GetLogger(common.GolangFileName())
 	fileStatusMap    = make(map[string]*zpeFileStatus)
+	fileStatusLock   sync.RWMutex
+	// lastLoadTime is the time of the last LoadDB call with files
+	lastLoadTime time.Time
+	// generation changes when a policy file is loaded or removed, it's
+	// guarded by fileStatusLock
+	generation       uint64
 	lastTokenCleanup = common.CurrentTimeMillis()
-	PolicyDirectory  string
 
 	// key is the domain name, value is a map keyed by role name with list of assertions
 	DomainStandardRoleAllowMap = make(map[string]*RoleMap)
@@ -61,6 +69,11 @@ var (
 
 	// cache of active Role Tokens
 	RoleTokenCacheMap = make(map[string]*token.RoleToken)
+	roleTokenLock     sync.RWMutex
+
+	// reloadAll is set to 1 when all policy files must be loaded again, e.g.
+	// after public keys changed.
+	reloadAll int32
 )
 
 type zpeFileStatus struct {
@@ -68,6 +81,29 @@ type zpeFileStatus struct {
 	domainName       string
 	lastModifiedDate time.Time
 	isValidPolFile   bool
+	// lastLoadTime and lastError are set by each load of the file, expires
+	// and assertions are set when it's loaded successfully
+	lastLoadTime time.Time
+	lastError    string
+	expires      time.Time
+	assertions   AssertionCounts
+	// policy is the verified content of the file, it's kept for snapshots
+	policy *zts.DomainSignedPolicyData
+	// snapshotLoadTime is the load time of a snapshot file that isn't in the
+	// policy directory yet, zero for the files of the directory
+	snapshotLoadTime time.Time
+}
+
+// DomainStatus is the load status of a policy file, Domain is empty if the
+// file was never loaded successfully.
+type DomainStatus struct {
+	Domain       string
+	FileName     string
+	LastLoadTime time.Time
+	Valid        bool
+	Expires      time.Time
+	Assertions   AssertionCounts
+	LastError    string
 }
 
 type RoleMap struct {
@@ -76,34 +112,79 @@ type RoleMap struct {
 }
 
 func getMatchObject(value string) matcher.ZpeMatch {
+	match, err := newMatchObject(value)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create pattern for '%s', error: %s", value, err.Error()))
+	}
+	return match
+}
+
+// newMatchObject returns the matcher of an assertion value, it fails if the
+// value is a malformed regex pattern.
+func newMatchObject(value string) (matcher.ZpeMatch, error) {
 	if value == "*" {
-		return matcher.ZpeMatchAll{}
-	} else {
-		anyCharMatch := strings.Index(value, "*")
-		singleCharMatch := strings.Index(value, "?")
+		return matcher.ZpeMatchAll{}, nil
+	}
 
-		if anyCharMatch == -1 && singleCharMatch == -1 {
-			return matcher.ZpeMatchEqual{MatchValue: value}
-		} else if anyCharMatch == len(value)-1 && singleCharMatch == -1 {
-			return matcher.ZpeMatchStartsWith{Prefix: value[:anyCharMatch]}
-		} else {
-			regexMatcher, err := matcher.NewZpeMatchRegex(value)
-			if err != nil {
-				logger.Error(fmt.Sprintf("unable to create pattern for '%s', error: %s", value, err.Error()))
-			}
-			return regexMatcher
-		}
+	anyCharMatch := strings.Index(value, "*")
+	singleCharMatch := strings.Index(value, "?")
+
+	if anyCharMatch == -1 && singleCharMatch == -1 {
+		return matcher.ZpeMatchEqual{MatchValue: value}, nil
+	} else if anyCharMatch == len(value)-1 && singleCharMatch == -1 {
+		return matcher.ZpeMatchStartsWith{Prefix: value[:anyCharMatch]}, nil
 	}
+	return matcher.NewZpeMatchRegex(value)
 }
 
-// Process the given policy file list and determine if any of the
-// policy domain files have been updated. New ones will be loaded
+// Process the given policy file list of the directory and determine if any
+// of the policy domain files have been updated. New ones will be loaded
 // into the policy domain map.
-func LoadDB(files []os.FileInfo) {
+func LoadDB(dir string, files []os.FileInfo) {
+	loadDB(dir, files, true)
+}
+
+// LoadUnverifiedDB loads the policy files like LoadDB without verifying
+// their ZTS and ZMS signatures. It's only for testing policies offline, the
+// agent must not use it.
+func LoadUnverifiedDB(dir string, files []os.FileInfo) {
+	loadDB(dir, files, false)
+}
+
+// loadDB loads the policy files, verify is false only for offline tests.
+func loadDB(dir string, files []os.FileInfo, verify bool) {
 	if files == nil {
 		logger.Info("loadDb: no policy files to load")
 		return
 	}
+
+	fileStatusLock.Lock()
+	defer fileStatusLock.Unlock()
+	lastLoadTime = time.Now()
+
+	listed := make(map[string]bool, len(files))
+	for _, policyFile := range files {
+		listed[policyFile.Name()] = true
+	}
+	removeSnapshotFiles(listed)
+
+	// mark all files as invalid to verify them again
+	if atomic.CompareAndSwapInt32(&reloadAll, 1, 0) {
+		for name, fileStatus := range fileStatusMap {
+			fileStatus.isValidPolFile = false
+			// files of a snapshot that are not in the directory yet are
+			// verified from memory
+			if !listed[name] && !fileStatus.snapshotLoadTime.IsZero() && fileStatus.policy != nil {
+				generation++
+				fileStatus.lastLoadTime, fileStatus.lastError = time.Now(), ""
+				if err := loadPolicy(name, dir+"/"+name, fileStatus.policy, verify); err != nil {
+					fileStatus.lastError = err.Error()
+					logger.Error(err.Error())
+				}
+			}
+		}
+	}
+
 	for _, policyFile := range files {
 		if policyFile.IsDir() {
 			continue
@@ -112,8 +193,9 @@ func LoadDB(files []os.FileInfo) {
 		if fileStatus != nil {
 
 			//	check if file does not exist
-			if _, err := os.Stat(PolicyDirectory + "/" + fileStatus.fileName); os.IsNotExist(err) {
+			if _, err := os.Stat(dir + "/" + fileStatus.fileName); os.IsNotExist(err) {
 				delete(fileStatusMap, policyFile.Name())
+				generation++
 				if !fileStatus.isValidPolFile || fileStatus.domainName == "" {
 					continue
 				}
@@ -140,18 +222,94 @@ func LoadDB(files []os.FileInfo) {
 			fileStatusMap[policyFile.Name()] = &zpeFileStatus{fileName: policyFile.Name(),
 				lastModifiedDate: policyFile.ModTime()}
 		}
-		err := loadFile(policyFile)
+		fileStatus = fileStatusMap[policyFile.Name()]
+		fileStatus.lastLoadTime = time.Now()
+		fileStatus.lastError = ""
+		generation++
+		err := loadFile(dir, policyFile, verify)
 		if err != nil {
+			fileStatus.isValidPolFile = false
+			fileStatus.lastError = err.Error()
 			logger.Error(err.Error())
+			continue
 		}
+		// the file is loaded again only if it's modified after this load
+		fileStatus.lastModifiedDate = policyFile.ModTime()
 	}
 }
 
+// removeSnapshotFiles removes the files of the snapshot that are not in the
+// directory yet, if ZPU doesn't download their domain or doesn't write them
+// in one zpu_download_interval. It must be called with fileStatusLock held.
+func removeSnapshotFiles(listed map[string]bool) {
+	maxAge := time.Duration(config.ZpeConfig.Get().ZpuDownloadInterval) * time.Second
+	var domains map[string]bool
+	for name, fileStatus := range fileStatusMap {
+		if fileStatus.snapshotLoadTime.IsZero() {
+			continue
+		}
+		// the file of the directory replaces the snapshot
+		if listed[name] {
+			fileStatus.snapshotLoadTime = time.Time{}
+			continue
+		}
+		if domains == nil {
+			domains = downloadedDomains()
+		}
+		if domains[fileStatus.domainName] && time.Since(fileStatus.snapshotLoadTime) <= maxAge {
+			continue
+		}
+
+		delete(fileStatusMap, name)
+		generation++
+		logger.Info(fmt.Sprintf("policy file %s of the snapshot is removed, domain: %s", name,
+			fileStatus.domainName))
+		if fileStatus.isValidPolFile && fileStatus.domainName != "" {
+			DomainStandardRoleAllowMap[fileStatus.domainName] = new(RoleMap)
+			DomainWildcardRoleAllowMap[fileStatus.domainName] = new(RoleMap)
+			DomainStandardRoleDenyMap[fileStatus.domainName] = new(RoleMap)
+			DomainWildcardRoleDenyMap[fileStatus.domainName] = new(RoleMap)
+		}
+	}
+}
+
+// downloadedDomains returns the domains that ZPU downloads, the domain list
+// of ZPU config and the subscribed domains.
+func downloadedDomains() map[string]bool {
+	domainList := ""
+	if zpuConfig := config.ZpuConfig.Get(); zpuConfig != nil {
+		domainList = zpuConfig.DomainList
+	}
+	domains := make(map[string]bool)
+	for _, domain := range strings.Split(downloader.Subscriptions.DomainList(domainList), ",") {
+		domains[domain] = true
+	}
+	return domains
+}
+
+// LoadDirectory loads the policy files of the directory by LoadDB.
+func LoadDirectory(dir string) error {
+	files, err := common.LoadFileStatus(dir)
+	if err != nil {
+		return err
+	}
+	LoadDB(dir, files)
+	return nil
+}
+
+// LastLoadTime returns the time of the last LoadDB call, zero if policy
+// files were never loaded.
+func LastLoadTime() time.Time {
+	fileStatusLock.RLock()
+	defer fileStatusLock.RUnlock()
+	return lastLoadTime
+}
+
 // Loads and parses the given file. It will create the domain assertion
 // list per role and put it into the domain policy maps(domRoleMap, domWildcardRoleMap).
-func loadFile(file os.FileInfo) error {
+func loadFile(dir string, file os.FileInfo, verify bool) error {
 
-	path := PolicyDirectory + "/" + file.Name()
+	path := dir + "/" + file.Name()
 	fileInfo, err := os.Stat(path)
 	if err != nil {
 		return common.Errorf("unable to load file info: %s, error: %s", path, err)
@@ -183,52 +341,31 @@ func loadFile(file os.FileInfo) error {
 		return common.Errorf("unable to decode policy file: %s", path)
 	}
 
-	// first let's verify the ZTS signature for our policy file
-	signedPolicyData := domainSignedPolicyData.SignedPolicyData
+	return loadPolicy(fileInfo.Name(), path, domainSignedPolicyData, verify)
+}
 
-	verified := false
-	input, err := zpuUtil.ToCanonicalString(signedPolicyData)
-	if err != nil {
-		return common.Errorf("unable to convert to string, error: %s", err.Error())
-	}
+// loadPolicy verifies the signed policy data of a policy file and puts its
+// assertions into the domain policy maps. The signatures are not verified
+// if verify is false.
+func loadPolicy(fileName, path string, domainSignedPolicyData *zts.DomainSignedPolicyData, verify bool) error {
+	var err error
 
-	pubKey := config.KeyStore.GetZtsPublicKey(domainSignedPolicyData.KeyId)
-	ztsKey, err := new(zmssvctoken.YBase64).DecodeString(pubKey)
-	if err != nil {
-		return common.Errorf("verification of data with zts key having id: '%s' failed, error: %s",
-			domainSignedPolicyData.KeyId, err.Error())
-	}
-	err = common.Verify(input, domainSignedPolicyData.Signature, string(ztsKey))
-	if err == nil {
-		verified = true
-	} else {
-		logger.Error("invalid policy, error: " + err.Error())
+	// first let's verify the ZTS signature for our policy file
+	signedPolicyData := domainSignedPolicyData.SignedPolicyData
+	if signedPolicyData == nil {
+		return common.Errorf("policy file has no signed policy data: %s", path)
 	}
 
-	var policyData *zts.PolicyData
-	if verified {
-		policyData = signedPolicyData.PolicyData
-
-		inputPolicy, err := zpuUtil.ToCanonicalString(policyData)
-		if err != nil {
-			return common.Errorf("unable to convert to string, error: %s", err)
-		}
-
-		zmsKey, err := new(zmssvctoken.YBase64).DecodeString(config.KeyStore.GetZmsPublicKey(signedPolicyData.ZmsKeyId))
-		if err != nil {
-			return common.Errorf("verification of data with zms key having id:'%s' failed, error: %s",
-				signedPolicyData.ZmsKeyId, err)
-		}
-
-		err = common.Verify(inputPolicy, signedPolicyData.ZmsSignature, string(zmsKey))
-		if err != nil {
-			verified = false
+	policyData := signedPolicyData.PolicyData
+	if verify {
+		if policyData, err = verifiedPolicyData(domainSignedPolicyData); err != nil {
+			return err
 		}
 	}
 
-	if !verified || policyData == nil {
+	if policyData == nil {
 		//	mark this file as an invalid file
-		fileStatus := fileStatusMap[fileInfo.Name()]
+		fileStatus := fileStatusMap[fileName]
 		if fileStatus != nil {
 			fileStatus.isValidPolFile = false
 		}
@@ -241,6 +378,7 @@ func loadFile(file os.FileInfo) error {
 	roleWildcardAllowMap := make(map[string][]map[string]interface{})
 	roleStandardDenyMap := make(map[string][]map[string]interface{})
 	roleWildcardDenyMap := make(map[string][]map[string]interface{})
+	var assertions AssertionCounts
 	for _, policy := range policyData.Policies {
 		for _, assertion := range policy.Assertions {
 			strAssert := make(map[string]interface{})
@@ -258,13 +396,20 @@ func loadFile(file os.FileInfo) error {
 			matchStruct := getMatchObject(pRoleName)
 			strAssert[common.ZpeRoleMatchStruct] = matchStruct
 
+			if reflect.TypeOf(matchStruct).Name() == "ZpeMatchEqual" {
+				assertions.Standard++
+			} else {
+				assertions.Wildcard++
+			}
 			if assertion.Effect != nil && assertion.Effect.String() == "DENY" {
+				assertions.Deny++
 				if reflect.TypeOf(matchStruct).Name() == "ZpeMatchEqual" {
 					computeIfAbsent(pRoleName, roleStandardDenyMap, strAssert)
 				} else {
 					computeIfAbsent(pRoleName, roleWildcardDenyMap, strAssert)
 				}
 			} else {
+				assertions.Allow++
 				if reflect.TypeOf(matchStruct).Name() == "ZpeMatchEqual" {
 					computeIfAbsent(pRoleName, roleStandardAllowMap, strAssert)
 				} else {
@@ -275,10 +420,13 @@ func loadFile(file os.FileInfo) error {
 		}
 	}
 
-	fileStatus := fileStatusMap[fileInfo.Name()]
+	fileStatus := fileStatusMap[fileName]
 	if fileStatus != nil {
 		fileStatus.isValidPolFile = true
 		fileStatus.domainName = domainName
+		fileStatus.expires = signedPolicyData.Expires.Time
+		fileStatus.assertions = assertions
+		fileStatus.policy = domainSignedPolicyData
 	}
 
 	expires := signedPolicyData.Expires.UnixNano()
@@ -291,6 +439,59 @@ func loadFile(file os.FileInfo) error {
 	return nil
 }
 
+// verifiedPolicyData verifies the ZTS signature of the policy file and the
+// ZMS signature of its policy data. It fails if a signature is invalid.
+func verifiedPolicyData(domainSignedPolicyData *zts.DomainSignedPolicyData) (*zts.PolicyData, error) {
+	verified, err := verifyZtsSignature(domainSignedPolicyData)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, common.Errorf("invalid policy, zts signature is not verified, key id: %s",
+			domainSignedPolicyData.KeyId)
+	}
+
+	signedPolicyData := domainSignedPolicyData.SignedPolicyData
+	if verified, err = verifyZmsSignature(signedPolicyData); err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, common.Errorf("invalid policy, zms signature is not verified, key id: %s",
+			signedPolicyData.ZmsKeyId)
+	}
+	return signedPolicyData.PolicyData, nil
+}
+
+// verifyZtsSignature verifies the ZTS signature of the signed policy data.
+func verifyZtsSignature(domainSignedPolicyData *zts.DomainSignedPolicyData) (bool, error) {
+	input, err := zpuUtil.ToCanonicalString(domainSignedPolicyData.SignedPolicyData)
+	if err != nil {
+		return false, common.Errorf("unable to convert to string, error: %s", err.Error())
+	}
+
+	ztsVerifier, err := config.KeyStore.GetZtsVerifier(domainSignedPolicyData.KeyId)
+	if err != nil {
+		return false, common.Errorf("verification of data with zts key having id: '%s' failed, error: %s",
+			domainSignedPolicyData.KeyId, err.Error())
+	}
+	return ztsVerifier.Verify(input, domainSignedPolicyData.Signature) == nil, nil
+}
+
+// verifyZmsSignature verifies the ZMS signature of the policy data.
+func verifyZmsSignature(signedPolicyData *zts.SignedPolicyData) (bool, error) {
+	inputPolicy, err := zpuUtil.ToCanonicalString(signedPolicyData.PolicyData)
+	if err != nil {
+		return false, common.Errorf("unable to convert to string, error: %s", err)
+	}
+
+	zmsVerifier, err := config.KeyStore.GetZmsVerifier(signedPolicyData.ZmsKeyId)
+	if err != nil {
+		return false, common.Errorf("verification of data with zms key having id:'%s' failed, error: %s",
+			signedPolicyData.ZmsKeyId, err)
+	}
+	return zmsVerifier.Verify(inputPolicy, signedPolicyData.ZmsSignature) == nil, nil
+}
+
 // this method will check if there is a slice for the
 // key then append new item to that slice, else create
 // new slice and append new item to that
@@ -309,10 +510,13 @@ func computeIfAbsent(key string, roleMap map[string][]map[string]interface{}, ma
 func CleanupRoleTokenCache() {
 	//is it time to cleanup
 	now := common.CurrentTimeMillis()
-	if now < int64(time.Duration(config.ZpeConfig.Properties.CleanupTokenInterval)*time.Microsecond)+lastTokenCleanup {
+	if now < int64(time.Duration(config.ZpeConfig.Get().CleanupTokenInterval)*time.Microsecond)+lastTokenCleanup {
 		return
 	}
 
+	roleTokenLock.Lock()
+	defer roleTokenLock.Unlock()
+
 	expired := make([]string, 0)
 	for key, roleToken := range RoleTokenCacheMap {
 		if roleToken == nil {
@@ -331,3 +535,86 @@ func CleanupRoleTokenCache() {
 	// update last cleanup time
 	lastTokenCleanup = now
 }
+
+// ReloadAll forces the next LoadDB call to load and verify all policy
+// files again, even if they were not modified.
+func ReloadAll() {
+	atomic.StoreInt32(&reloadAll, 1)
+}
+
+// InvalidPolicyFiles returns the sorted names of the policy files that
+// LoadDB couldn't decode or verify.
+func InvalidPolicyFiles() []string {
+	fileStatusLock.RLock()
+	defer fileStatusLock.RUnlock()
+
+	invalid := make([]string, 0)
+	for name, fileStatus := range fileStatusMap {
+		if !fileStatus.isValidPolFile {
+			invalid = append(invalid, name)
+		}
+	}
+	sort.Strings(invalid)
+	return invalid
+}
+
+// DomainStatuses returns the load status of the policy files that LoadDB
+// has seen, sorted by domain and file name.
+func DomainStatuses() []DomainStatus {
+	fileStatusLock.RLock()
+	defer fileStatusLock.RUnlock()
+
+	statuses := make([]DomainStatus, 0, len(fileStatusMap))
+	for _, fileStatus := range fileStatusMap {
+		statuses = append(statuses, DomainStatus{Domain: fileStatus.domainName, FileName: fileStatus.fileName,
+			LastLoadTime: fileStatus.lastLoadTime, Valid: fileStatus.isValidPolFile, Expires: fileStatus.expires,
+			Assertions: fileStatus.assertions, LastError: fileStatus.lastError})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Domain != statuses[j].Domain {
+			return statuses[i].Domain < statuses[j].Domain
+		}
+		return statuses[i].FileName < statuses[j].FileName
+	})
+	return statuses
+}
+
+// GetRoleToken returns the cached RoleToken of a signed token.
+func GetRoleToken(signedToken string) (*token.RoleToken, bool) {
+	roleTokenLock.RLock()
+	defer roleTokenLock.RUnlock()
+	roleToken, ok := RoleTokenCacheMap[signedToken]
+	return roleToken, ok
+}
+
+// PutRoleToken caches a validated RoleToken.
+func PutRoleToken(signedToken string, roleToken *token.RoleToken) {
+	roleTokenLock.Lock()
+	defer roleTokenLock.Unlock()
+	RoleTokenCacheMap[signedToken] = roleToken
+}
+
+// DeleteRoleToken removes a RoleToken from cache.
+func DeleteRoleToken(signedToken string) {
+	roleTokenLock.Lock()
+	defer roleTokenLock.Unlock()
+	delete(RoleTokenCacheMap, signedToken)
+}
+
+// FlushRoleTokenCache removes all cached RoleTokens and returns the number
+// of removed tokens. Cached tokens were validated with the previous public
+// keys, so flush the cache when the public keys change.
+func FlushRoleTokenCache() int {
+	roleTokenLock.Lock()
+	defer roleTokenLock.Unlock()
+	flushed := len(RoleTokenCacheMap)
+	RoleTokenCacheMap = make(map[string]*token.RoleToken)
+	return flushed
+}
+
+// RoleTokenCacheSize returns the number of cached RoleTokens.
+func RoleTokenCacheSize() int {
+	roleTokenLock.RLock()
+	defer roleTokenLock.RUnlock()
+	return len(RoleTokenCacheMap)
+}
@@ -32,6 +32,11 @@ const (
 	configDirPrefix = "config"
 	policyDirPrefix = "policy"
 	polFile         = "test.pol"
+
+	// testPolicy is a policy file of sys.auth domain, signed by the keys
+	// of testAthenzConfig
+	testPolicy       = `{"signedPolicyData":{"expires":"2017-06-09T06:11:12.125Z","modified" : "2017-06-02T06:11:12.125Z","policyData":{"domain":"sys.auth","policies":[{"assertions":[{"action":"*","effect":"ALLOW","resource":"*","role":"sys.auth:role.admin"},{"action":"*","effect":"DENY","resource":"*","role":"sys.auth:role.non-admin"}],"name":"sys.auth:policy.admin"}]},"zmsKeyId":"0","zmsSignature":"Y2HuXmgL86PL1WnleGFHwPmNEqUdWgDxmmIsDnF5f5oqakacqTtwt9JNqDV9nuJ7LnKl3zsZoDQSAtcHMu4IGA--"},"signature":"XJnQ4t33D4yr7NtUjLaWhXULFr76z.z0p3QV4uCkA5KR9L4liVRmICYwVmnXxvHAlImKlKLv7sbIHNsjBfGfCw--","keyId": "0"}`
+	testAthenzConfig = `{"zmsUrl":"https://dev.zms.athenzcompany.com:4443/","ztsUrl":"https://dev.zts.athenzcompany.com:4443/","ztsPublicKeys":[{"id":"0","key":"LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUZ3d0RRWUpLb1pJaHZjTkFRRUJCUUFEU3dBd1NBSkJBTHpmU09UUUpmRW0xZW00TDNza3lOVlEvYngwTU9UcQphK1J3T0gzWmNNS3lvR3hPSm85QXllUmE2RlhNbXZKSkdZczVQMzRZc3pGcG5qMnVBYmkyNG5FQ0F3RUFBUT09Ci0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"},{"id":"1","key": "LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUlHZk1BMEdDU3FHU0liM0RRRUJBUVVBQTRHTkFEQ0JpUUtCZ1FETGlLY1hjUDlrMWRJcGU4bm1OS3pBaWpGcApuY0VWbEFveS8xcHordE5ETjExcDQ0MTJEREhXejhFSUNiVkE0RE16Wm1ta09URFdlUDBQSWdnNTg0RlF1SGpsCmsyOWU4VjJXT3pqQWZybGlad0dKbm1mdlBhb3FOQkNhZDI3cWFubm1MOVU3cTcvSEdRWmpMeGdoaXhGa0FtczEKaHFlbnlkb2JSVkhheHV3cDB3SURBUUFCCi0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"}],"zmsPublicKeys":[{"id":"0","key":"LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUZ3d0RRWUpLb1pJaHZjTkFRRUJCUUFEU3dBd1NBSkJBTHpmU09UUUpmRW0xZW00TDNza3lOVlEvYngwTU9UcQphK1J3T0gzWmNNS3lvR3hPSm85QXllUmE2RlhNbXZKSkdZczVQMzRZc3pGcG5qMnVBYmkyNG5FQ0F3RUFBUT09Ci0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"},{"id":"1","key":"LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUZ3d0RRWUpLb1pJaHZjTkFRRUJCUUFEU3dBd1NBSkJBTHpmU09UUUpmRW0xZW00TDNza3lOVlEvYngwTU9UcQphK1J3T0gzWmNNS3lvR3hPSm85QXllUmE2RlhNbXZKSkdZczVQMzRZc3pGcG5qMnVBYmkyNG5FQ0F3RUFBUT09Ci0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"}]}`
 )
 
 func setup() {
@@ -71,7 +76,7 @@ func TestGetMatchObject(t *testing.T) {
 
 func TestLoadDBNull(t *testing.T) {
 	setup()
-	LoadDB(nil)
+	LoadDB("", nil)
 }
 
 func TestLoadDB(t *testing.T) {
@@ -86,10 +91,9 @@ func TestLoadDB(t *testing.T) {
 			common.Fatal(err.Error())
 		}
 	}()
-	PolicyDirectory = policyDir
 
 	policyPath := policyDir + "/" + polFile
-	err = common.CreateFile(policyPath, `{"signedPolicyData":{"expires":"2017-06-09T06:11:12.125Z","modified" : "2017-06-02T06:11:12.125Z","policyData":{"domain":"sys.auth","policies":[{"assertions":[{"action":"*","effect":"ALLOW","resource":"*","role":"sys.auth:role.admin"},{"action":"*","effect":"DENY","resource":"*","role":"sys.auth:role.non-admin"}],"name":"sys.auth:policy.admin"}]},"zmsKeyId":"0","zmsSignature":"Y2HuXmgL86PL1WnleGFHwPmNEqUdWgDxmmIsDnF5f5oqakacqTtwt9JNqDV9nuJ7LnKl3zsZoDQSAtcHMu4IGA--"},"signature":"XJnQ4t33D4yr7NtUjLaWhXULFr76z.z0p3QV4uCkA5KR9L4liVRmICYwVmnXxvHAlImKlKLv7sbIHNsjBfGfCw--","keyId": "0"}`)
+	err = common.CreateFile(policyPath, testPolicy)
 	a.NoError(err)
 	err = os.MkdirAll(policyDir+string(os.PathSeparator)+"test-dir", 0755)
 	a.NoError(err)
@@ -97,12 +101,20 @@ func TestLoadDB(t *testing.T) {
 	// check if zms and zts public keys not exist input must
 	// be invalid
 	files, _ := common.LoadFileStatus(policyDir)
-	LoadDB(files)
+	LoadDB(policyDir, files)
 	a.Len(DomainWildcardRoleDenyMap, 0)
 	a.Len(DomainStandardRoleAllowMap, 0)
 	a.Len(DomainWildcardRoleAllowMap, 0)
 	a.Len(DomainStandardRoleDenyMap, 0)
 	a.False(fileStatusMap[polFile].isValidPolFile)
+	a.Equal([]string{polFile}, InvalidPolicyFiles())
+	statuses := DomainStatuses()
+	a.Len(statuses, 1)
+	a.Equal(polFile, statuses[0].FileName)
+	a.Empty(statuses[0].Domain)
+	a.False(statuses[0].Valid)
+	a.False(statuses[0].LastLoadTime.IsZero())
+	a.Contains(statuses[0].LastError, "zts key having id: '0'")
 
 	// use athenz config file to verify input and signature
 	// and then cache the policies in memory
@@ -110,27 +122,36 @@ func TestLoadDB(t *testing.T) {
 	a.Nil(err)
 	defer common.RemoveAll(configDir)
 	configPath := configDir + "/athenz.json"
-	err = common.CreateFile(configPath, `{"zmsUrl":"https://dev.zms.athenzcompany.com:4443/","ztsUrl":"https://dev.zts.athenzcompany.com:4443/","ztsPublicKeys":[{"id":"0","key":"LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUZ3d0RRWUpLb1pJaHZjTkFRRUJCUUFEU3dBd1NBSkJBTHpmU09UUUpmRW0xZW00TDNza3lOVlEvYngwTU9UcQphK1J3T0gzWmNNS3lvR3hPSm85QXllUmE2RlhNbXZKSkdZczVQMzRZc3pGcG5qMnVBYmkyNG5FQ0F3RUFBUT09Ci0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"},{"id":"1","key": "LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUlHZk1BMEdDU3FHU0liM0RRRUJBUVVBQTRHTkFEQ0JpUUtCZ1FETGlLY1hjUDlrMWRJcGU4bm1OS3pBaWpGcApuY0VWbEFveS8xcHordE5ETjExcDQ0MTJEREhXejhFSUNiVkE0RE16Wm1ta09URFdlUDBQSWdnNTg0RlF1SGpsCmsyOWU4VjJXT3pqQWZybGlad0dKbm1mdlBhb3FOQkNhZDI3cWFubm1MOVU3cTcvSEdRWmpMeGdoaXhGa0FtczEKaHFlbnlkb2JSVkhheHV3cDB3SURBUUFCCi0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"}],"zmsPublicKeys":[{"id":"0","key":"LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUZ3d0RRWUpLb1pJaHZjTkFRRUJCUUFEU3dBd1NBSkJBTHpmU09UUUpmRW0xZW00TDNza3lOVlEvYngwTU9UcQphK1J3T0gzWmNNS3lvR3hPSm85QXllUmE2RlhNbXZKSkdZczVQMzRZc3pGcG5qMnVBYmkyNG5FQ0F3RUFBUT09Ci0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"},{"id":"1","key":"LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUZ3d0RRWUpLb1pJaHZjTkFRRUJCUUFEU3dBd1NBSkJBTHpmU09UUUpmRW0xZW00TDNza3lOVlEvYngwTU9UcQphK1J3T0gzWmNNS3lvR3hPSm85QXllUmE2RlhNbXZKSkdZczVQMzRZc3pGcG5qMnVBYmkyNG5FQ0F3RUFBUT09Ci0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"}]}`)
+	err = common.CreateFile(configPath, testAthenzConfig)
 	a.Nil(err)
 	if err := config.LoadGlobalAthenzConfig(configPath); err != nil {
 		common.Fatalf("unable to load config, %s: ", err)
 	}
 
 	files, _ = common.LoadFileStatus(policyDir)
-	LoadDB(files)
+	LoadDB(policyDir, files)
 	a.True(fileStatusMap[polFile].isValidPolFile)
+	a.Empty(InvalidPolicyFiles())
+	statuses = DomainStatuses()
+	a.Len(statuses, 1)
+	a.Equal("sys.auth", statuses[0].Domain)
+	a.True(statuses[0].Valid)
+	a.Empty(statuses[0].LastError)
+	a.Equal(AssertionCounts{Allow: 1, Deny: 1, Standard: 2}, statuses[0].Assertions)
+	a.Equal(time.Date(2017, 6, 9, 6, 11, 12, 125000000, time.UTC), statuses[0].Expires.UTC())
 
 	// load same policy file
 	files, _ = common.LoadFileStatus(policyDir)
-	LoadDB(files)
+	LoadDB(policyDir, files)
 
 	// remove policy file
 	if err = os.Remove(policyPath); err != nil {
 		common.Fatal(err.Error())
 	}
-	LoadDB(files)
+	LoadDB(policyDir, files)
 	_, ok := fileStatusMap[polFile]
 	a.False(ok)
+	a.Empty(DomainStatuses())
 }
 
 func TestCleanupRoleTokenCache(t *testing.T) {
@@ -180,3 +201,24 @@ func TestCleanupRoleTokenCache(t *testing.T) {
 	_, ok = RoleTokenCacheMap["role4"]
 	a.True(ok)
 }
+
+func TestFlushRoleTokenCache(t *testing.T) {
+	a := assert.New(t)
+	PutRoleToken("token1", &token.RoleToken{})
+	_, ok := GetRoleToken("token1")
+	a.True(ok)
+
+	FlushRoleTokenCache()
+	_, ok = GetRoleToken("token1")
+	a.False(ok)
+}
+
+func TestRoleTokenCacheSize(t *testing.T) {
+	a := assert.New(t)
+	FlushRoleTokenCache()
+	PutRoleToken("token1", &token.RoleToken{})
+	a.Equal(1, RoleTokenCacheSize())
+
+	a.Equal(1, FlushRoleTokenCache())
+	a.Equal(0, RoleTokenCacheSize())
+}
@@ -0,0 +1,257 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 7:40 PM
+ *
+ * Description:
+ * ServiceTokenCache caches the role and access tokens that the agent
+ * gets from ZTS for its own service. A cached token is returned until a fraction
+ * of its lifetime remains, then it's refreshed in the background while
+ * the cached token is still returned. If ZTS is down, the cached token
+ * is returned until it expires.
+ *
+ */
+
+package cache
+
+import (
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/clock"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTokenRefreshFraction is the default fraction of the token
+	// lifetime that triggers the refresh.
+	DefaultTokenRefreshFraction = 0.25
+
+	// tokenRetryInterval is the minimum time between two failed refreshes
+	// of the same token.
+	tokenRetryInterval = 10 * time.Second
+)
+
+type (
+	// ServiceTokenKey identifies a role or access token request.
+	ServiceTokenKey struct {
+		Domain string
+		// Roles is a comma separated list of role names
+		Roles string
+		// MinExpiry and MaxExpiry are in seconds
+		MinExpiry         int32
+		MaxExpiry         int32
+		ProxyForPrincipal string
+		// IDTokenService is only set for access tokens with ID token
+		IDTokenService string
+	}
+
+	// ServiceToken is a role or access token that ZTS issued.
+	ServiceToken struct {
+		Token string
+		// IDToken and TokenType are only set for access tokens
+		IDToken   string
+		TokenType string
+		// ExpiryTime is in unix seconds
+		ExpiryTime int64
+	}
+
+	// ServiceTokenFetcher gets a new token from ZTS.
+	ServiceTokenFetcher func(key ServiceTokenKey) (*ServiceToken, error)
+
+	// ServiceTokenCache the interface that wraps token cache.
+	ServiceTokenCache interface {
+		// Get returns the cached token of the key, or gets a new one if
+		// there is no valid token in cache
+		Get(key ServiceTokenKey) (*ServiceToken, error)
+		// Flush removes all cached tokens
+		Flush()
+	}
+
+	serviceTokenCache struct {
+		fetch ServiceTokenFetcher
+		// refreshFraction returns the fraction of token lifetime that
+		// triggers the refresh, it's read on each fetch to follow config
+		// changes
+		refreshFraction func() float64
+		clock           clock.Clock
+
+		mu      sync.Mutex
+		entries map[ServiceTokenKey]*serviceTokenEntry
+		flight  singleFlight
+	}
+
+	serviceTokenEntry struct {
+		token       *ServiceToken
+		fetchedAt   time.Time
+		refreshAt   time.Time
+		lastUsed    time.Time
+		lastFailure time.Time
+		timer       clock.Timer
+		// refreshing is set while a background refresh of the entry is
+		// started, so callers in the refresh window don't start another one
+		refreshing bool
+	}
+)
+
+// NewServiceTokenCache creates new instance of ServiceTokenCache type. A
+// refreshFraction out of (0, 1) range is replaced by DefaultTokenRefreshFraction.
+func NewServiceTokenCache(fetch ServiceTokenFetcher, refreshFraction func() float64) ServiceTokenCache {
+	return &serviceTokenCache{
+		fetch:           fetch,
+		refreshFraction: refreshFraction,
+		clock:           clock.Real,
+		entries:         make(map[ServiceTokenKey]*serviceTokenEntry),
+	}
+}
+
+// Get returns the cached token of the key. If the token is in its refresh
+// window, a background refresh is started and the cached token is returned.
+// If there is no valid token, Get waits for a new token. Concurrent calls
+// for the same key share one ZTS request.
+func (c *serviceTokenCache) Get(key ServiceTokenKey) (*ServiceToken, error) {
+	now := c.clock.Now()
+
+	c.mu.Lock()
+	if entry, ok := c.entries[key]; ok && entry.isValid(now) {
+		entry.lastUsed = now
+		if !entry.refreshing && !now.Before(entry.refreshAt) && now.Sub(entry.lastFailure) >= tokenRetryInterval {
+			entry.refreshing = true
+			go c.refreshInBackground(key)
+		}
+		token := entry.token
+		c.mu.Unlock()
+		return token, nil
+	}
+	c.mu.Unlock()
+
+	token, err := c.refresh(key)
+	if err != nil {
+		// another caller may have got a token in the meantime
+		if token, ok := c.cached(key); ok {
+			return token, nil
+		}
+		return nil, err
+	}
+	return token, nil
+}
+
+// Flush removes all cached tokens.
+func (c *serviceTokenCache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range c.entries {
+		entry.stopTimer()
+	}
+	c.entries = make(map[ServiceTokenKey]*serviceTokenEntry)
+}
+
+// cached returns the valid cached token of the key.
+func (c *serviceTokenCache) cached(key ServiceTokenKey) (*ServiceToken, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok && entry.isValid(c.clock.Now()) {
+		return entry.token, true
+	}
+	return nil, false
+}
+
+// refresh gets a new token and caches it. Only one refresh per key is in
+// flight, duplicate callers share its result.
+func (c *serviceTokenCache) refresh(key ServiceTokenKey) (*ServiceToken, error) {
+	return c.flight.do(key, func() (*ServiceToken, error) {
+		token, err := c.fetch(key)
+		now := c.clock.Now()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err != nil {
+			if entry, ok := c.entries[key]; ok {
+				entry.lastFailure = now
+				entry.refreshing = false
+			}
+			return nil, err
+		}
+		c.store(key, token, now)
+		return token, nil
+	})
+}
+
+// refreshInBackground refreshes the token and logs the failures, the cached
+// token is still returned until it expires.
+func (c *serviceTokenCache) refreshInBackground(key ServiceTokenKey) {
+	if _, err := c.refresh(key); err != nil {
+		logger.Error(fmt.Sprintf("unable to refresh token of domain: %s, roles: %s, error: %s",
+			key.Domain, key.Roles, err.Error()))
+	}
+}
+
+// store caches the token and schedules its refresh. Tokens that are already
+// expired are not cached. c.mu must be held.
+func (c *serviceTokenCache) store(key ServiceTokenKey, token *ServiceToken, now time.Time) {
+	if previous, ok := c.entries[key]; ok {
+		previous.stopTimer()
+	}
+
+	expiry := time.Unix(token.ExpiryTime, 0)
+	if !now.Before(expiry) {
+		delete(c.entries, key)
+		return
+	}
+
+	fraction := c.refreshFraction()
+	if fraction <= 0 || fraction >= 1 {
+		fraction = DefaultTokenRefreshFraction
+	}
+	lifetime := expiry.Sub(now)
+	entry := &serviceTokenEntry{
+		token:     token,
+		fetchedAt: now,
+		refreshAt: expiry.Add(-time.Duration(float64(lifetime) * fraction)),
+		lastUsed:  now,
+	}
+
+	// refresh the token proactively, so the next caller doesn't wait
+	entry.timer = c.clock.AfterFunc(entry.refreshAt.Sub(now), func() { c.onRefreshTime(key, entry) })
+	c.entries[key] = entry
+}
+
+// onRefreshTime refreshes the token of an entry if it's still cached and it
+// was used since it was fetched. Unused tokens expire on their own. A failed
+// refresh is retried while the cached token is valid.
+func (c *serviceTokenCache) onRefreshTime(key ServiceTokenKey, entry *serviceTokenEntry) {
+	c.mu.Lock()
+	if c.entries[key] != entry || !entry.lastUsed.After(entry.fetchedAt) {
+		c.mu.Unlock()
+		return
+	}
+	c.mu.Unlock()
+
+	if _, err := c.refresh(key); err != nil {
+		logger.Error(fmt.Sprintf("unable to refresh token of domain: %s, roles: %s, error: %s",
+			key.Domain, key.Roles, err.Error()))
+
+		c.mu.Lock()
+		if c.entries[key] == entry && entry.isValid(c.clock.Now().Add(tokenRetryInterval)) {
+			entry.timer = c.clock.AfterFunc(tokenRetryInterval, func() { c.onRefreshTime(key, entry) })
+		}
+		c.mu.Unlock()
+	}
+}
+
+// isValid checks the token is not expired at the input time.
+func (e *serviceTokenEntry) isValid(now time.Time) bool {
+	return now.Before(time.Unix(e.token.ExpiryTime, 0))
+}
+
+// stopTimer stops the scheduled refresh of the entry.
+func (e *serviceTokenEntry) stopTimer() {
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+}
@@ -0,0 +1,224 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 8:30 PM
+ *
+ * Description:
+ *
+ */
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/clock"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	testTokenKey = ServiceTokenKey{Domain: "sports", Roles: "admin", MinExpiry: 600, MaxExpiry: 3600}
+)
+
+// fakeTokenFetcher is a ServiceTokenFetcher that issues tokens with the
+// input lifetime and fails when failing is set.
+type fakeTokenFetcher struct {
+	lifetime time.Duration
+	calls    int32
+	failing  atomic.Value
+	// release blocks the fetch until it's closed, if it's set
+	release chan struct{}
+	clock   *clock.Fake
+}
+
+func (f *fakeTokenFetcher) fetch(key ServiceTokenKey) (*ServiceToken, error) {
+	if f.release != nil {
+		<-f.release
+	}
+	calls := atomic.AddInt32(&f.calls, 1)
+	if failing, _ := f.failing.Load().(bool); failing {
+		return nil, errors.New("zts is down")
+	}
+	return &ServiceToken{
+		Token:      fmt.Sprintf("token-%d", calls),
+		ExpiryTime: f.clock.Now().Add(f.lifetime).Unix(),
+	}, nil
+}
+
+func (f *fakeTokenFetcher) callCount() int {
+	return int(atomic.LoadInt32(&f.calls))
+}
+
+// newTestServiceTokenCache creates a cache with a fake clock, the refresh
+// timers only fire when the clock is moved forward.
+func newTestServiceTokenCache(fraction float64) (*serviceTokenCache, *fakeTokenFetcher) {
+	fetcher := &fakeTokenFetcher{lifetime: time.Hour, clock: clock.NewFake(time.Now())}
+	c := NewServiceTokenCache(fetcher.fetch, func() float64 { return fraction }).(*serviceTokenCache)
+	c.clock = fetcher.clock
+	return c, fetcher
+}
+
+// waitingCallers returns the number of callers that wait for the in flight
+// fetch of the key.
+func waitingCallers(c *serviceTokenCache, key ServiceTokenKey) int {
+	c.flight.mu.Lock()
+	defer c.flight.mu.Unlock()
+	if call, ok := c.flight.calls[key]; ok {
+		return call.dups
+	}
+	return 0
+}
+
+func TestServiceTokenCache_Get(t *testing.T) {
+	a := assert.New(t)
+	c, zts := newTestServiceTokenCache(0.25)
+	defer c.Flush()
+
+	token, err := c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-1", token.Token)
+
+	// other keys have their own tokens
+	zts.clock.Add(40 * time.Minute)
+	readerKey := ServiceTokenKey{Domain: "sports", Roles: "reader"}
+	token, err = c.Get(readerKey)
+	a.NoError(err)
+	a.Equal("token-2", token.Token)
+
+	// in the refresh window the cached token is returned and a new one is
+	// fetched in the background, the refresh timer skipped it because it
+	// wasn't used
+	zts.clock.Add(6 * time.Minute)
+	a.Equal(2, zts.callCount())
+	token, err = c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-1", token.Token)
+	a.Eventually(func() bool {
+		token, _ := c.Get(testTokenKey)
+		return token.Token == "token-3"
+	}, time.Second, time.Millisecond)
+
+	// cached token is returned before the refresh window
+	token, err = c.Get(readerKey)
+	a.NoError(err)
+	a.Equal("token-2", token.Token)
+	a.Equal(3, zts.callCount())
+
+	// flushed tokens are fetched again
+	c.Flush()
+	token, err = c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-4", token.Token)
+}
+
+func TestServiceTokenCache_GetWhenZtsIsDown(t *testing.T) {
+	a := assert.New(t)
+	c, zts := newTestServiceTokenCache(0.5)
+	defer c.Flush()
+
+	zts.failing.Store(true)
+	_, err := c.Get(testTokenKey)
+	a.Error(err)
+
+	zts.failing.Store(false)
+	token, err := c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-2", token.Token)
+
+	// the still valid token is returned while refresh fails
+	zts.failing.Store(true)
+	zts.clock.Add(45 * time.Minute)
+	token, err = c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-2", token.Token)
+	a.Eventually(func() bool { return zts.callCount() == 3 }, time.Second, 10*time.Millisecond)
+
+	// failed refresh is not retried before retry interval, Get doesn't
+	// start another background refresh
+	token, err = c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-2", token.Token)
+	a.Equal(3, zts.callCount())
+
+	// expired token is not returned
+	zts.clock.Add(20 * time.Minute)
+	_, err = c.Get(testTokenKey)
+	a.Error(err)
+}
+
+func TestServiceTokenCache_GetConcurrently(t *testing.T) {
+	a := assert.New(t)
+	c, zts := newTestServiceTokenCache(0.25)
+	defer c.Flush()
+	zts.release = make(chan struct{})
+
+	const callers = 20
+	var wg sync.WaitGroup
+	tokens := make([]*ServiceToken, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = c.Get(testTokenKey)
+		}(i)
+	}
+	// let the callers join the in flight request
+	a.Eventually(func() bool { return waitingCallers(c, testTokenKey) == callers-1 }, time.Second,
+		time.Millisecond)
+	close(zts.release)
+	wg.Wait()
+
+	a.Equal(1, zts.callCount())
+	for _, token := range tokens {
+		a.NotNil(token)
+		a.Equal("token-1", token.Token)
+	}
+}
+
+func TestServiceTokenCache_ProactiveRefresh(t *testing.T) {
+	a := assert.New(t)
+	c, zts := newTestServiceTokenCache(0.5)
+	defer c.Flush()
+
+	token, err := c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-1", token.Token)
+
+	// unused token is not refreshed
+	zts.clock.Add(31 * time.Minute)
+	a.Equal(1, zts.callCount())
+
+	// used token is refreshed before it expires
+	c.Flush()
+	_, err = c.Get(testTokenKey)
+	a.NoError(err)
+	zts.clock.Add(time.Minute)
+	_, err = c.Get(testTokenKey)
+	a.NoError(err)
+	zts.clock.Add(30 * time.Minute)
+	a.Equal(3, zts.callCount())
+	token, err = c.Get(testTokenKey)
+	a.NoError(err)
+	a.Equal("token-3", token.Token)
+
+	// failed refresh is retried while the cached token is valid
+	zts.clock.Add(time.Minute)
+	_, err = c.Get(testTokenKey)
+	a.NoError(err)
+	zts.failing.Store(true)
+	zts.clock.Add(29 * time.Minute)
+	a.Equal(4, zts.callCount())
+	zts.failing.Store(false)
+	zts.clock.Add(tokenRetryInterval)
+	a.Equal(5, zts.callCount())
+}
@@ -0,0 +1,65 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 7:30 PM
+ *
+ * Description:
+ * singleFlight makes sure only one call is in flight for a key,
+ * duplicate callers wait for it and share its result.
+ *
+ */
+
+package cache
+
+import "sync"
+
+type (
+	// singleFlight dedupes concurrent calls with the same key.
+	singleFlight struct {
+		mu    sync.Mutex
+		calls map[ServiceTokenKey]*flightCall
+	}
+
+	// flightCall is an in flight or completed call.
+	flightCall struct {
+		wg    sync.WaitGroup
+		token *ServiceToken
+		err   error
+		// dups is the number of callers that wait for the call
+		dups int
+	}
+)
+
+// do calls fn and returns its result. If a call with the same key is in
+// flight, do waits for it and returns its result instead of calling fn.
+func (g *singleFlight) do(key ServiceTokenKey, fn func() (*ServiceToken, error)) (*ServiceToken, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[ServiceTokenKey]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		call.dups++
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.token, call.err
+	}
+	call := new(flightCall)
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.token, call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	return call.token, call.err
+}
@@ -0,0 +1,196 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 5:10 PM
+ *
+ * Description:
+ * A policy snapshot is the verified content of the loaded policy files.
+ * It's loaded at startup before the policy directory, so access checks
+ * have policies even if the policy directory is empty and ZTS is
+ * unreachable. The snapshot is gzip compressed JSON after a header line
+ * with its SHA-256 checksum:
+ *   athenz-agent-snapshot v1 sha256:<hex>
+ * The checksum only detects corruption. Public keys are not stored in the
+ * snapshot, its policies are verified again by the ZTS and ZMS signatures
+ * with the keys of athenz config and the public keys cache, like policy
+ * files.
+ *
+ */
+
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotVersion      = 1
+	snapshotHeaderPrefix = "athenz-agent-snapshot v1 sha256:"
+)
+
+var (
+	// savedGeneration is the generation of the last saved or loaded
+	// snapshot, it's guarded by fileStatusLock
+	savedGeneration uint64
+)
+
+type (
+	// policySnapshot is the content of a snapshot file.
+	policySnapshot struct {
+		Version   int            `json:"version"`
+		CreatedAt time.Time      `json:"created_at"`
+		Files     []snapshotFile `json:"files"`
+	}
+
+	// snapshotFile is a verified policy file of a snapshot.
+	snapshotFile struct {
+		Name    string                      `json:"name"`
+		ModTime time.Time                   `json:"mod_time"`
+		Policy  *zts.DomainSignedPolicyData `json:"policy"`
+	}
+)
+
+// SaveSnapshot writes the valid policy files into the snapshot file
+// atomically. It doesn't write the file if no policy
+// file is loaded or removed since the last snapshot.
+func SaveSnapshot(path string) error {
+	fileStatusLock.RLock()
+	current := generation
+	if current == savedGeneration && common.Exists(path) {
+		fileStatusLock.RUnlock()
+		return nil
+	}
+	snapshot := &policySnapshot{Version: snapshotVersion, CreatedAt: time.Now().UTC(),
+		Files: make([]snapshotFile, 0, len(fileStatusMap))}
+	for _, fileStatus := range fileStatusMap {
+		if fileStatus.isValidPolFile && fileStatus.policy != nil {
+			snapshot.Files = append(snapshot.Files, snapshotFile{Name: fileStatus.fileName,
+				ModTime: fileStatus.lastModifiedDate, Policy: fileStatus.policy})
+		}
+	}
+	fileStatusLock.RUnlock()
+
+	sort.Slice(snapshot.Files, func(i, j int) bool { return snapshot.Files[i].Name < snapshot.Files[j].Name })
+
+	data, err := encodeSnapshot(snapshot)
+	if err != nil {
+		return err
+	}
+	if err := common.WriteFileAtomically(path, data, 0600); err != nil {
+		return common.Errorf("unable to write snapshot, error: %s", err.Error())
+	}
+
+	fileStatusLock.Lock()
+	savedGeneration = current
+	fileStatusLock.Unlock()
+	logger.Info(fmt.Sprintf("policy snapshot saved to %s, files: %d", path, len(snapshot.Files)))
+	return nil
+}
+
+// LoadSnapshot verifies and loads the policy files of the snapshot that
+// LoadDB hasn't loaded yet, the public keys must be loaded before. A missing
+// snapshot is not an error. It returns the number of loaded policy files.
+// Later LoadDB calls load the files of the policy directory that are
+// modified after the snapshot, and remove the snapshot files that ZPU
+// doesn't write into the directory.
+func LoadSnapshot(path string) (int, error) {
+	if !common.Exists(path) {
+		return 0, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, common.Errorf("unable to read snapshot %s, error: %s", path, err.Error())
+	}
+	snapshot, err := decodeSnapshot(data)
+	if err != nil {
+		return 0, common.Errorf("invalid snapshot %s, error: %s", path, err.Error())
+	}
+
+	fileStatusLock.Lock()
+	defer fileStatusLock.Unlock()
+
+	loaded := 0
+	for _, file := range snapshot.Files {
+		if _, ok := fileStatusMap[file.Name]; ok || file.Policy == nil {
+			continue
+		}
+		now := time.Now()
+		fileStatus := &zpeFileStatus{fileName: file.Name, lastModifiedDate: file.ModTime, lastLoadTime: now,
+			snapshotLoadTime: now}
+		fileStatusMap[file.Name] = fileStatus
+		if err := loadPolicy(file.Name, path+":"+file.Name, file.Policy, true); err != nil {
+			fileStatus.isValidPolFile = false
+			fileStatus.lastError = err.Error()
+			logger.Error(err.Error())
+			continue
+		}
+		loaded++
+	}
+	// the snapshot doesn't need to be saved again
+	generation++
+	savedGeneration = generation
+	logger.Info(fmt.Sprintf("policy snapshot loaded from %s, created at: %s, files: %d", path,
+		snapshot.CreatedAt.Format(time.RFC3339), loaded))
+	return loaded, nil
+}
+
+// encodeSnapshot compresses the snapshot and adds the checksum header.
+func encodeSnapshot(snapshot *policySnapshot) ([]byte, error) {
+	var payload bytes.Buffer
+	zw := gzip.NewWriter(&payload)
+	if err := json.NewEncoder(zw).Encode(snapshot); err != nil {
+		return nil, common.Errorf("unable to encode snapshot, error: %s", err.Error())
+	}
+	if err := zw.Close(); err != nil {
+		return nil, common.Errorf("unable to compress snapshot, error: %s", err.Error())
+	}
+
+	checksum := sha256.Sum256(payload.Bytes())
+	data := []byte(snapshotHeaderPrefix + hex.EncodeToString(checksum[:]) + "\n")
+	return append(data, payload.Bytes()...), nil
+}
+
+// decodeSnapshot verifies the checksum of the snapshot and decodes it.
+func decodeSnapshot(data []byte) (*policySnapshot, error) {
+	header, err := bufio.NewReader(bytes.NewReader(data)).ReadString('\n')
+	if err != nil || !strings.HasPrefix(header, snapshotHeaderPrefix) {
+		return nil, common.Error("snapshot header is not found")
+	}
+	payload := data[len(header):]
+	checksum := sha256.Sum256(payload)
+	if strings.TrimSpace(strings.TrimPrefix(header, snapshotHeaderPrefix)) != hex.EncodeToString(checksum[:]) {
+		return nil, common.Error("snapshot checksum doesn't match")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, common.Errorf("unable to decompress snapshot, error: %s", err.Error())
+	}
+	defer zr.Close()
+	snapshot := new(policySnapshot)
+	if err := json.NewDecoder(zr).Decode(snapshot); err != nil {
+		return nil, common.Errorf("unable to decode snapshot, error: %s", err.Error())
+	}
+	if snapshot.Version != snapshotVersion {
+		return nil, common.Errorf("unsupported snapshot version: %d", snapshot.Version)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,142 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 5:50 PM
+ *
+ * Description:
+ *
+ */
+
+package cache
+
+import (
+	"encoding/json"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"github.com/stretchr/testify/assert"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	setup()
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", policyDirPrefix)
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	defer func() {
+		fileStatusLock.Lock()
+		delete(fileStatusMap, polFile)
+		delete(fileStatusMap, "forged.pol")
+		fileStatusLock.Unlock()
+		for _, domainMap := range []map[string]*RoleMap{DomainStandardRoleAllowMap, DomainWildcardRoleAllowMap,
+			DomainStandardRoleDenyMap, DomainWildcardRoleDenyMap} {
+			delete(domainMap, "sys.auth")
+		}
+	}()
+
+	a.NoError(common.CreateFile(dir+"/athenz.json", testAthenzConfig))
+	a.NoError(config.LoadGlobalAthenzConfig(dir + "/athenz.json"))
+	policyDir, snapshotPath := dir+"/policy", dir+"/snapshot/policy.snap"
+	a.NoError(common.CreateAllDirectories(policyDir))
+	a.NoError(common.CreateFile(policyDir+"/"+polFile, testPolicy))
+
+	a.NoError(LoadDirectory(policyDir))
+	a.NoError(SaveSnapshot(snapshotPath))
+	snapshot, err := ioutil.ReadFile(snapshotPath)
+	a.NoError(err)
+
+	// the agent restarts with an empty policy directory
+	a.NoError(os.Remove(policyDir + "/" + polFile))
+	fileStatusLock.Lock()
+	delete(fileStatusMap, polFile)
+	fileStatusLock.Unlock()
+	delete(DomainStandardRoleAllowMap, "sys.auth")
+
+	loaded, err := LoadSnapshot(snapshotPath)
+	a.NoError(err)
+	a.Equal(1, loaded)
+	a.Contains(DomainStandardRoleAllowMap, "sys.auth")
+
+	// files of the snapshot are kept until the policy directory has them,
+	// while ZPU downloads their domain
+	downloader.Subscriptions.Add("sys.auth")
+	defer downloader.Subscriptions.Prune(func(string) bool { return false })
+	defer func(interval int64) {
+		config.ZpeConfig.Properties.ZpuDownloadInterval = interval
+	}(config.ZpeConfig.Properties.ZpuDownloadInterval)
+	config.ZpeConfig.Properties.ZpuDownloadInterval = 3600
+	a.NoError(LoadDirectory(policyDir))
+	ReloadAll()
+	a.NoError(LoadDirectory(policyDir))
+	statuses := DomainStatuses()
+	if a.Len(statuses, 1) {
+		a.Equal("sys.auth", statuses[0].Domain)
+		a.True(statuses[0].Valid)
+	}
+
+	// loaded files are not loaded again
+	loaded, err = LoadSnapshot(snapshotPath)
+	a.NoError(err)
+	a.Equal(0, loaded)
+
+	// files of the snapshot are removed if ZPU doesn't write them in one
+	// download interval
+	fileStatusLock.Lock()
+	fileStatusMap[polFile].snapshotLoadTime = time.Now().Add(-2 * time.Hour)
+	fileStatusLock.Unlock()
+	a.NoError(LoadDirectory(policyDir))
+	a.Empty(DomainStatuses())
+	a.Empty(DomainStandardRoleAllowMap["sys.auth"].RoleDataMap)
+
+	// or if their domain isn't downloaded anymore
+	loaded, err = LoadSnapshot(snapshotPath)
+	a.NoError(err)
+	a.Equal(1, loaded)
+	downloader.Subscriptions.Prune(func(string) bool { return false })
+	ReloadAll()
+	a.NoError(LoadDirectory(policyDir))
+	a.Empty(DomainStatuses())
+
+	loaded, err = LoadSnapshot(dir + "/missing.snap")
+	a.NoError(err)
+	a.Equal(0, loaded)
+
+	// the checksum of a forged snapshot matches, but its policies are not
+	// signed by the keys of athenz config
+	forged := new(zts.DomainSignedPolicyData)
+	a.NoError(json.Unmarshal([]byte(testPolicy), forged))
+	forged.Signature = "forged"
+	forgedSnapshot, err := encodeSnapshot(&policySnapshot{Version: snapshotVersion,
+		Files: []snapshotFile{{Name: "forged.pol", Policy: forged}}})
+	a.NoError(err)
+	a.NoError(ioutil.WriteFile(dir+"/forged.snap", forgedSnapshot, 0600))
+	loaded, err = LoadSnapshot(dir + "/forged.snap")
+	a.NoError(err)
+	a.Equal(0, loaded)
+	a.Equal([]string{"forged.pol"}, InvalidPolicyFiles())
+
+	snapshot[len(snapshot)-1] ^= 0xff
+	a.NoError(ioutil.WriteFile(snapshotPath, snapshot, 0600))
+	_, err = LoadSnapshot(snapshotPath)
+	if a.Error(err) {
+		a.Contains(err.Error(), "checksum doesn't match")
+	}
+
+	a.NoError(ioutil.WriteFile(snapshotPath, []byte("{}"), 0600))
+	_, err = LoadSnapshot(snapshotPath)
+	if a.Error(err) {
+		a.Contains(err.Error(), "header is not found")
+	}
+}
@@ -0,0 +1,130 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:10 AM
+ *
+ * Description:
+ * Clock tells the time and schedules functions, so the caches that expire
+ * and refresh entries can be tested without sleeping. Real uses the time
+ * package, and Fake only moves forward by Add, which runs the functions
+ * that became due.
+ *
+ */
+
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// Real is the Clock of the time package.
+	Real Clock = realClock{}
+)
+
+type (
+	// Clock is the interface that wraps the time functions of caches.
+	Clock interface {
+		// Now returns the current time.
+		Now() time.Time
+		// AfterFunc calls f in its own goroutine after d, like
+		// time.AfterFunc.
+		AfterFunc(d time.Duration, f func()) Timer
+	}
+
+	// Timer is a scheduled function call.
+	Timer interface {
+		// Stop prevents the call, it returns false if the call already
+		// happened or was stopped.
+		Stop() bool
+	}
+
+	realClock struct{}
+
+	// Fake is a Clock for tests. Its time only moves by Add, and the
+	// scheduled functions run in Add.
+	Fake struct {
+		mu     sync.Mutex
+		now    time.Time
+		timers []*fakeTimer
+	}
+
+	fakeTimer struct {
+		clock *Fake
+		at    time.Time
+		f     func()
+	}
+)
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// NewFake creates a Fake clock at the input time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the time of the clock.
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f at d after the time of the clock.
+func (c *Fake) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Add moves the clock forward and calls the functions that became due in
+// their scheduled order. They run in the caller goroutine, so their effects
+// are visible when Add returns.
+func (c *Fake) Add(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	due := make([]*fakeTimer, 0)
+	pending := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.at.After(c.now) {
+			pending = append(pending, timer)
+		} else {
+			due = append(due, timer)
+		}
+	}
+	c.timers = pending
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool { return due[i].at.Before(due[j].at) })
+	for _, timer := range due {
+		timer.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:25 AM
+ *
+ * Description:
+ *
+ */
+
+package clock
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	a := assert.New(t)
+	start := time.Now()
+	c := NewFake(start)
+	a.Equal(start, c.Now())
+
+	calls := make([]string, 0)
+	c.AfterFunc(2*time.Minute, func() { calls = append(calls, "second") })
+	c.AfterFunc(time.Minute, func() { calls = append(calls, "first") })
+	stopped := c.AfterFunc(time.Minute, func() { calls = append(calls, "stopped") })
+	a.True(stopped.Stop())
+	a.False(stopped.Stop())
+
+	c.Add(30 * time.Second)
+	a.Empty(calls)
+
+	c.Add(2 * time.Minute)
+	a.Equal([]string{"first", "second"}, calls)
+	a.Equal(start.Add(150*time.Second), c.Now())
+
+	c.Add(time.Hour)
+	a.Len(calls, 2)
+}
@@ -0,0 +1,232 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 11:20 AM
+ *
+ * Description:
+ *
+ */
+
+package athenzagent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/urfave/cli"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type (
+	// domainResult is an item of domains command output.
+	domainResult struct {
+		Domain       string           `json:"domain"`
+		FileName     string           `json:"file_name"`
+		Valid        bool             `json:"valid"`
+		LastLoadTime *time.Time       `json:"last_load_time,omitempty"`
+		ExpiryTime   *time.Time       `json:"expiry_time,omitempty"`
+		Expired      bool             `json:"expired"`
+		Assertions   assertionsResult `json:"assertions"`
+		LastError    string           `json:"last_error,omitempty"`
+	}
+
+	assertionsResult struct {
+		Allow    int32 `json:"allow"`
+		Deny     int32 `json:"deny"`
+		Standard int32 `json:"standard"`
+		Wildcard int32 `json:"wildcard"`
+	}
+
+	domainResults []*domainResult
+
+	// statusResult is the output of status command.
+	statusResult struct {
+		StartTime               *time.Time `json:"start_time,omitempty"`
+		LogLevel                string     `json:"log_level"`
+		Domains                 []string   `json:"domains"`
+		SubscribedDomains       []string   `json:"subscribed_domains"`
+		LoadedDomains           int32      `json:"loaded_domains"`
+		InvalidPolicyFiles      []string   `json:"invalid_policy_files"`
+		CachedRoleTokens        int32      `json:"cached_role_tokens"`
+		LastPolicyDownloadTime  *time.Time `json:"last_policy_download_time,omitempty"`
+		LastPolicyDownloadError string     `json:"last_policy_download_error,omitempty"`
+		LastCacheLoadTime       *time.Time `json:"last_cache_load_time,omitempty"`
+	}
+
+	// flushResult is the output of flush-tokens command.
+	flushResult struct {
+		FlushedTokens int `json:"flushed_tokens"`
+	}
+)
+
+func listDomains() error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	domains, err := c.ListDomains(context.Background())
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	results := newDomainResults(domains)
+	return output(results, results.String)
+}
+
+func refreshPolicies(domains []string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	refreshed, err := c.RefreshPolicies(context.Background(), domains)
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	results := newDomainResults(refreshed)
+	return output(results, results.String)
+}
+
+func reloadCache() error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	domains, err := c.ReloadCache(context.Background())
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	results := newDomainResults(domains)
+	return output(results, results.String)
+}
+
+func flushTokenCache() error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	flushed, err := c.FlushTokenCache(context.Background())
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	return output(&flushResult{FlushedTokens: flushed}, func() string {
+		return fmt.Sprintf("%d role tokens flushed", flushed)
+	})
+}
+
+func agentStatus() error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	resp, err := c.GetStatus(context.Background())
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	result := &statusResult{StartTime: unixTime(resp.StartTime), LogLevel: resp.LogLevel, Domains: resp.Domains,
+		SubscribedDomains: resp.SubscribedDomains,
+		LoadedDomains:     resp.LoadedDomains, InvalidPolicyFiles: resp.InvalidPolicyFiles,
+		CachedRoleTokens: resp.CachedRoleTokens, LastPolicyDownloadTime: unixTime(resp.LastPolicyDownloadTime),
+		LastPolicyDownloadError: resp.LastPolicyDownloadError, LastCacheLoadTime: unixTime(resp.LastCacheLoadTime)}
+	return output(result, result.String)
+}
+
+// newDomainResults converts the domain statuses of the agent.
+func newDomainResults(domains []*msg.DomainStatus) domainResults {
+	results := make(domainResults, 0, len(domains))
+	for _, domain := range domains {
+		result := &domainResult{Domain: domain.Domain, FileName: domain.FileName, Valid: domain.Valid,
+			LastLoadTime: unixTime(domain.LastLoadTime), ExpiryTime: unixTime(domain.ExpiryTime),
+			LastError: domain.LastError}
+		if result.ExpiryTime != nil {
+			result.Expired = result.ExpiryTime.Before(time.Now())
+		}
+		if assertions := domain.Assertions; assertions != nil {
+			result.Assertions = assertionsResult{Allow: assertions.Allow, Deny: assertions.Deny,
+				Standard: assertions.Standard, Wildcard: assertions.Wildcard}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// unixTime converts unix seconds to UTC time, 0 means no time.
+func unixTime(seconds int64) *time.Time {
+	if seconds == 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
+
+// String returns a table of the domains, one line per policy file.
+func (r domainResults) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	timeOf := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	_, _ = fmt.Fprintln(w, "DOMAIN\tFILE\tVALID\tLOADED\tEXPIRES\tALLOW/DENY\tERROR")
+	for _, result := range r {
+		domain, expires := result.Domain, timeOf(result.ExpiryTime)
+		if domain == "" {
+			domain = "-"
+		}
+		if result.Expired {
+			expires += " (expired)"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\t%d/%d\t%s\n", domain, result.FileName, result.Valid,
+			timeOf(result.LastLoadTime), expires, result.Assertions.Allow, result.Assertions.Deny,
+			result.LastError)
+	}
+	_ = w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// String returns the fields of the status in aligned lines.
+func (r *statusResult) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	timeOf := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	_, _ = fmt.Fprintf(w, "start time:\t%s\n", timeOf(r.StartTime))
+	_, _ = fmt.Fprintf(w, "log level:\t%s\n", r.LogLevel)
+	_, _ = fmt.Fprintf(w, "domains:\t%s\n", strings.Join(r.Domains, ", "))
+	_, _ = fmt.Fprintf(w, "subscribed domains:\t%s\n", strings.Join(r.SubscribedDomains, ", "))
+	_, _ = fmt.Fprintf(w, "loaded domains:\t%d\n", r.LoadedDomains)
+	_, _ = fmt.Fprintf(w, "invalid policy files:\t%s\n", strings.Join(r.InvalidPolicyFiles, ", "))
+	_, _ = fmt.Fprintf(w, "cached role tokens:\t%d\n", r.CachedRoleTokens)
+	_, _ = fmt.Fprintf(w, "last policy download:\t%s\n", timeOf(r.LastPolicyDownloadTime))
+	if r.LastPolicyDownloadError != "" {
+		_, _ = fmt.Fprintf(w, "last policy download error:\t%s\n", r.LastPolicyDownloadError)
+	}
+	_, _ = fmt.Fprintf(w, "last cache load:\t%s\n", timeOf(r.LastCacheLoadTime))
+	_ = w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,123 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 7:20 AM
+ *
+ * Description:
+ * decode parses a role token offline, the agent isn't called and the
+ * signature isn't verified.
+ *
+ */
+
+package athenzagent
+
+import (
+	"bytes"
+	"fmt"
+	roletoken "github.com/hamed-yousefi/athenz-agent/token"
+	"github.com/urfave/cli"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// decodedToken is the output of decode command.
+type decodedToken struct {
+	Version         string     `json:"version"`
+	Domain          string     `json:"domain"`
+	Roles           []string   `json:"roles"`
+	CompleteRoleSet bool       `json:"complete_role_set"`
+	Principal       string     `json:"principal,omitempty"`
+	HostName        string     `json:"host_name,omitempty"`
+	IPAddress       string     `json:"ip_address,omitempty"`
+	KeyID           string     `json:"key_id,omitempty"`
+	Salt            string     `json:"salt,omitempty"`
+	GenerationTime  *time.Time `json:"generation_time,omitempty"`
+	ExpiryTime      *time.Time `json:"expiry_time,omitempty"`
+	Expired         bool       `json:"expired"`
+	Signed          bool       `json:"signed"`
+}
+
+func decode(signedToken string) error {
+	if signedToken == "" {
+		signedToken = token
+	}
+	if signedToken == "-" {
+		in, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return cli.NewExitError(fmt.Sprintf("unable to read token, error: %s", err.Error()), exitFailed)
+		}
+		signedToken = strings.TrimSpace(string(in))
+	}
+
+	decoded, err := decodeToken(signedToken)
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	return output(decoded, decoded.String)
+}
+
+// decodeToken parses the role token, times of the token are in nanoseconds
+// like token.RoleToken validation expects.
+func decodeToken(signedToken string) (*decodedToken, error) {
+	roleToken, err := roletoken.NewRoleToken(signedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := &decodedToken{Version: roleToken.Version, Domain: roleToken.Domain, Roles: roleToken.RoleNames,
+		CompleteRoleSet: roleToken.DomainCompleteRoleSet, Principal: roleToken.Principal,
+		HostName: roleToken.HostName, IPAddress: roleToken.IPAddress, KeyID: roleToken.KeyId,
+		Salt: roleToken.Salt, Signed: roleToken.Signature != ""}
+	if roleToken.GenerationTime != 0 {
+		generationTime := time.Unix(0, roleToken.GenerationTime).UTC()
+		decoded.GenerationTime = &generationTime
+	}
+	if roleToken.ExpiryTime != 0 {
+		expiryTime := time.Unix(0, roleToken.ExpiryTime).UTC()
+		decoded.ExpiryTime = &expiryTime
+		decoded.Expired = expiryTime.Before(time.Now())
+	}
+	return decoded, nil
+}
+
+// String returns the fields of the token in aligned lines.
+func (d *decodedToken) String() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	field := func(name, value string) {
+		if value != "" {
+			_, _ = fmt.Fprintf(w, "%s:\t%s\n", name, value)
+		}
+	}
+	timeOf := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	field("version", d.Version)
+	field("domain", d.Domain)
+	field("roles", strings.Join(d.Roles, ", "))
+	field("complete role set", fmt.Sprint(d.CompleteRoleSet))
+	field("principal", d.Principal)
+	field("host name", d.HostName)
+	field("ip address", d.IPAddress)
+	field("key id", d.KeyID)
+	field("salt", d.Salt)
+	field("generation time", timeOf(d.GenerationTime))
+	field("expiry time", timeOf(d.ExpiryTime))
+	field("expired", fmt.Sprint(d.Expired))
+	field("signed", fmt.Sprint(d.Signed))
+	_ = w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
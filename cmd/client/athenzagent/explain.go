@@ -0,0 +1,139 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 7:35 AM
+ *
+ * Description:
+ * explain checks an access and describes the status with the problems
+ * of the role token and the resource that can be found offline.
+ *
+ */
+
+package athenzagent
+
+import (
+	"context"
+	"fmt"
+	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/urfave/cli"
+	"strings"
+	"time"
+)
+
+var (
+	// statusDescriptions describes access statuses of the agent.
+	statusDescriptions = map[msg.AccessStatus]string{
+		msg.AccessStatus_ALLOW: "an allow assertion of the token roles matches the access and resource, " +
+			"and no deny assertion does",
+		msg.AccessStatus_DENY:                    "a deny assertion of the token roles matches the access and resource",
+		msg.AccessStatus_DENY_ROLE_TOKEN_EXPIRED: "the role token is expired",
+		msg.AccessStatus_DENY_ROLE_TOKEN_INVALID: "the role token has no domain or roles, or its signature " +
+			"isn't verified by the ZTS public keys of athenz config",
+		msg.AccessStatus_DENY_INVALID_PARAMETERS: "access or resource is empty",
+		msg.AccessStatus_DENY_DOMAIN_MISMATCH:    "the domain of the resource isn't the domain of the role token",
+		msg.AccessStatus_DENY_DOMAIN_NOT_FOUND: "the agent has no policies of the role token domain, " +
+			"check the domains of zpu config",
+		msg.AccessStatus_DENY_NO_MATCH:     "no assertion of the token roles matches the access and resource",
+		msg.AccessStatus_DENY_DOMAIN_EMPTY: "the policies of the role token domain have no assertions",
+		msg.AccessStatus_DENY_DOMAIN_EXPIRED: "the policies of the role token domain are expired, " +
+			"check that zpu updates them, or set a stale_policies rule of zpe config",
+		msg.AccessStatus_DENY_DOMAIN_LOADING: "the policies of the role token domain are being downloaded, " +
+			"retry the check later",
+	}
+)
+
+// explanation is the output of explain command.
+type explanation struct {
+	Status      string        `json:"status,omitempty"`
+	Code        *int32        `json:"code,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Access      string        `json:"access"`
+	Resource    string        `json:"resource"`
+	Token       *decodedToken `json:"token,omitempty"`
+	Problems    []string      `json:"problems"`
+}
+
+func explain(offline bool) error {
+	if token == "" || access == "" || resource == "" {
+		return cli.NewExitError("token, access and resource are required", exitFailed)
+	}
+
+	e := &explanation{Access: access, Resource: resource, Problems: []string{}}
+	decoded, err := decodeToken(token)
+	if err != nil {
+		e.Problems = append(e.Problems, "the role token can't be parsed, error: "+err.Error())
+	} else {
+		e.Token = decoded
+		e.Problems = append(e.Problems, tokenProblems(decoded, resource)...)
+	}
+
+	if !offline {
+		c, err := newClient()
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+
+		accessStatus, err := c.CheckAccess(context.Background(), token, access, resource)
+		if err != nil {
+			return cli.NewExitError(err.Error(), exitFailed)
+		}
+		code := int32(accessStatus)
+		e.Status, e.Code, e.Description = accessStatus.String(), &code, statusDescriptions[accessStatus]
+	}
+
+	if err := output(e, e.String); err != nil {
+		return err
+	}
+	if e.Code != nil {
+		return statusExitError(msg.AccessStatus(*e.Code))
+	}
+	return nil
+}
+
+// tokenProblems returns the problems of the role token and the resource
+// that deny the access without the policies.
+func tokenProblems(decoded *decodedToken, resource string) []string {
+	var problems []string
+	if decoded.Expired {
+		problems = append(problems, fmt.Sprintf("the role token expired at %s",
+			decoded.ExpiryTime.Format(time.RFC3339)))
+	}
+	if !decoded.Signed {
+		problems = append(problems, "the role token has no signature")
+	}
+
+	// the agent compares lower case resources, a resource without domain
+	// prefix is in the token domain
+	resource = strings.ToLower(resource)
+	if i := strings.Index(resource, ":"); i != -1 && resource[:i] != decoded.Domain {
+		problems = append(problems, fmt.Sprintf("the resource domain '%s' isn't the role token domain '%s'",
+			resource[:i], decoded.Domain))
+	}
+	return problems
+}
+
+// String returns the status, the description and the problems in lines.
+func (e *explanation) String() string {
+	var lines []string
+	if e.Status != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", e.Status, e.Description))
+	}
+	lines = append(lines, fmt.Sprintf("access: %s, resource: %s", e.Access, e.Resource))
+	if e.Token != nil {
+		lines = append(lines, "", "role token:", e.Token.String())
+	}
+	if len(e.Problems) > 0 {
+		lines = append(lines, "", "problems:")
+		for _, problem := range e.Problems {
+			lines = append(lines, "- "+problem)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
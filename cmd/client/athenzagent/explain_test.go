@@ -0,0 +1,116 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:10 AM
+ *
+ * Description:
+ *
+ */
+
+package athenzagent
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// roleToken returns a role token of sports domain that expires at expiry,
+// times of role tokens are in nanoseconds.
+func roleToken(expiry time.Time, signature string) string {
+	signedToken := "v=Z1;d=sports;r=reader,writer;p=user.jane;a=salt;t=" +
+		strconv.FormatInt(expiry.Add(-time.Hour).UnixNano(), 10) + ";e=" + strconv.FormatInt(expiry.UnixNano(), 10) +
+		";k=0"
+	if signature != "" {
+		signedToken += ";s=" + signature
+	}
+	return signedToken
+}
+
+func TestDecodeToken(t *testing.T) {
+	a := assert.New(t)
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	decoded, err := decodeToken(roleToken(expiry, "sig"))
+	if a.NoError(err) {
+		a.Equal("Z1", decoded.Version)
+		a.Equal("sports", decoded.Domain)
+		a.Equal([]string{"reader", "writer"}, decoded.Roles)
+		a.Equal("user.jane", decoded.Principal)
+		a.Equal("0", decoded.KeyID)
+		if a.NotNil(decoded.ExpiryTime) {
+			a.True(expiry.Equal(*decoded.ExpiryTime))
+		}
+		a.False(decoded.Expired)
+		a.True(decoded.Signed)
+		a.Contains(decoded.String(), "roles:")
+	}
+
+	for _, signedToken := range []string{"", "v=Z1;r=reader", "v=Z1;d=sports", "v=Z1;d=sports;r=reader;e=now"} {
+		_, err := decodeToken(signedToken)
+		a.Error(err, signedToken)
+	}
+}
+
+func TestTokenProblems(t *testing.T) {
+	a := assert.New(t)
+	valid := roleToken(time.Now().Add(time.Hour), "sig")
+	expired := roleToken(time.Now().Add(-time.Hour), "sig")
+
+	for _, tc := range []struct {
+		name     string
+		token    string
+		resource string
+		problems []string
+	}{
+		{name: "valid", token: valid, resource: "sports:articles"},
+		{name: "upper case domain", token: valid, resource: "Sports:Articles"},
+		{name: "empty suffix", token: valid, resource: "sports:"},
+		{name: "no domain prefix", token: valid, resource: "articles"},
+		{name: "domain mismatch", token: valid, resource: "weather:articles",
+			problems: []string{"the resource domain 'weather' isn't the role token domain 'sports'"}},
+		{name: "empty domain", token: valid, resource: ":articles",
+			problems: []string{"the resource domain '' isn't the role token domain 'sports'"}},
+		{name: "expired", token: expired, resource: "sports:articles", problems: []string{"the role token expired at"}},
+		{name: "unsigned", token: roleToken(time.Now().Add(time.Hour), ""), resource: "sports:articles",
+			problems: []string{"the role token has no signature"}},
+	} {
+		decoded, err := decodeToken(tc.token)
+		if !a.NoError(err, tc.name) {
+			continue
+		}
+		problems := tokenProblems(decoded, tc.resource)
+		if a.Len(problems, len(tc.problems), tc.name) {
+			for i, problem := range tc.problems {
+				a.Contains(problems[i], problem, tc.name)
+			}
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	a := assert.New(t)
+	defer func(t, ac, r string) { token, access, resource = t, ac, r }(token, access, resource)
+
+	token, access, resource = "", "read", "sports:articles"
+	err := explain(true)
+	a.Equal(exitFailed, exitCode(err))
+
+	// offline explain has no status, so the exit code is 0 with problems
+	token, resource = roleToken(time.Now().Add(-time.Hour), "sig"), "weather:articles"
+	a.NoError(explain(true))
+	token = "invalid"
+	a.NoError(explain(true))
+
+	e := &explanation{Status: "DENY_NO_MATCH", Description: "no assertion", Access: "read",
+		Resource: "sports:articles", Problems: []string{"the role token has no signature"}}
+	a.Equal("DENY_NO_MATCH: no assertion\naccess: read, resource: sports:articles\n\nproblems:\n"+
+		"- the role token has no signature", e.String())
+}
@@ -15,25 +15,187 @@
 package athenzagent
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/hamed-yousefi/athenz-agent/common"
-	"github.com/hamed-yousefi/athenz-agent/common/log"
+	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
 	"github.com/hamed-yousefi/athenz-agent/grpc/client"
+	"github.com/urfave/cli"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
 )
 
-func run() {
-	log.NewLogrusInitializer().InitialLog(log.Info)
-	logger := log.GetLogger(common.GolangFileName())
+const (
+	// exit codes of the commands, 0 means the access is allowed
+	exitDenied = 1
+	exitFailed = 2
+)
+
+type (
+	// checkResult is the output of check command.
+	checkResult struct {
+		Status   string `json:"status"`
+		Code     int32  `json:"code"`
+		Access   string `json:"access"`
+		Resource string `json:"resource"`
+		// StalePolicyMode is set if the policies of the domain are expired
+		StalePolicyMode string `json:"stale_policy_mode,omitempty"`
+	}
+
+	// tokenResult is the output of token command.
+	tokenResult struct {
+		Token string `json:"token"`
+	}
+)
+
+func check(method, path string, headers []string) error {
+	if method == "" && (token == "" || access == "" || resource == "") {
+		return cli.NewExitError("token, access and resource are required, or method and path of the HTTP request",
+			exitFailed)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	result, err := checkAccess(c, method, path, headers)
+	if err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	if err := output(result, func() string {
+		if result.StalePolicyMode != "" {
+			return fmt.Sprintf("%s (access: %s, resource: %s, stale policy mode: %s)", result.Status,
+				result.Access, result.Resource, result.StalePolicyMode)
+		}
+		return fmt.Sprintf("%s (access: %s, resource: %s)", result.Status, result.Access, result.Resource)
+	}); err != nil {
+		return err
+	}
+	return statusExitError(msg.AccessStatus(result.Code))
+}
+
+// checkAccess checks the access of token, access and resource flags, or the
+// HTTP request if method is set.
+func checkAccess(c client.Client, method, path string, headers []string) (*checkResult, error) {
+	ctx := context.Background()
+	if method == "" {
+		accessStatus, err := c.CheckAccess(ctx, token, access, resource)
+		if err != nil {
+			return nil, err
+		}
+		return &checkResult{Status: accessStatus.String(), Code: int32(accessStatus), Access: access,
+			Resource: resource}, nil
+	}
+
+	parsedHeaders, err := parseHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+	req := &msg.HTTPAccessCheckRequest{Token: token, Method: method, Path: path, Headers: parsedHeaders}
+	resp, err := c.CheckHTTPAccess(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	result := &checkResult{Status: resp.AccessCheckStatus.String(), Code: int32(resp.AccessCheckStatus),
+		Access: resp.Access, Resource: resp.Resource}
+	if resp.StalePolicyMode != msg.StalePolicyMode_FRESH {
+		result.StalePolicyMode = resp.StalePolicyMode.String()
+	}
+	return result, nil
+}
+
+// parseHeaders parses the header flags in name=value format, the value may
+// be empty and contain "=".
+func parseHeaders(headers []string) (map[string]string, error) {
+	parsed := make(map[string]string, len(headers))
+	for _, header := range headers {
+		i := strings.Index(header, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("invalid header '%s', expected name=value", header)
+		}
+		parsed[header[:i]] = header[i+1:]
+	}
+	return parsed, nil
+}
+
+func serviceToken(domain string, roles []string, minExpiry, maxExpiry int, proxyForPrincipal string) error {
+	if domain == "" {
+		return cli.NewExitError("domain is required", exitFailed)
+	}
 
-	if token == "" || access == "" || resource == "" || port == "" {
-		logger.Fatal("token, access, resource or port is empty, please set value for all of them")
+	c, err := newClient()
+	if err != nil {
+		return err
 	}
+	defer c.Close()
 
-	val, err := client.CheckAccessWithClient(token, access, resource, host, port)
+	signedToken, err := c.GetServiceToken(context.Background(), &msg.ServiceTokenRequest{Domain: domain,
+		Roles: roles, MinExpiryTime: int32(minExpiry), MaxExpiryTime: int32(maxExpiry),
+		ProxyForPrincipal: proxyForPrincipal})
 	if err != nil {
-		logger.Fatalf("error when calling agent's client, error: %s", err.Error())
+		return cli.NewExitError(err.Error(), exitFailed)
 	}
+	return output(&tokenResult{Token: signedToken}, func() string {
+		return signedToken
+	})
+}
 
-	logger.Info(fmt.Sprintf("resource: %s, access: %s, access_status: %d", resource, access, val))
-	fmt.Printf("Response from server: %d\n", val)
+// newClient creates a client of the agent by the connection flags. It
+// doesn't retry, the command fails if the agent is unavailable.
+func newClient() (client.Client, error) {
+	opts := []client.Option{client.WithTimeout(timeout), client.WithRetry(1, 0)}
+	address := net.JoinHostPort(host, port)
+	if socket != "" {
+		opts = append(opts, client.WithUnixSocket(socket))
+	} else if port == "" {
+		return nil, cli.NewExitError("port or socket is required", exitFailed)
+	}
+	if certFile != "" || keyFile != "" {
+		opts = append(opts, client.WithTLSFiles(certFile, keyFile, caFile))
+	}
+	if serverName != "" {
+		opts = append(opts, client.WithServerName(serverName))
+	}
+	if secretFile != "" {
+		secret, err := ioutil.ReadFile(secretFile)
+		if err != nil {
+			return nil, cli.NewExitError(fmt.Sprintf("unable to read secret file, error: %s", err.Error()),
+				exitFailed)
+		}
+		opts = append(opts, client.WithSecret(strings.TrimSpace(string(secret))))
+	}
+
+	c, err := client.New(address, opts...)
+	if err != nil {
+		return nil, cli.NewExitError(err.Error(), exitFailed)
+	}
+	return c, nil
+}
+
+// output writes v in JSON if json flag is set, otherwise the text of the
+// function.
+func output(v interface{}, text func() string) error {
+	if !jsonOutput {
+		fmt.Println(text())
+		return nil
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return cli.NewExitError(err.Error(), exitFailed)
+	}
+	return nil
+}
+
+// statusExitError returns nil for ALLOW and an exit error without message
+// for denied statuses, the status is already printed.
+func statusExitError(accessStatus msg.AccessStatus) error {
+	if accessStatus == msg.AccessStatus_ALLOW {
+		return nil
+	}
+	return cli.NewExitError("", exitDenied)
 }
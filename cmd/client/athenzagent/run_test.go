@@ -0,0 +1,145 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:25 AM
+ *
+ * Description:
+ *
+ */
+
+package athenzagent
+
+import (
+	"bytes"
+	"context"
+	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/grpc/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"testing"
+)
+
+// fakeClient returns the statuses of its fields, the other calls of
+// client.Client panic.
+type fakeClient struct {
+	client.Client
+	accessStatus msg.AccessStatus
+	httpRequest  *msg.HTTPAccessCheckRequest
+}
+
+func (f *fakeClient) CheckAccess(_ context.Context, _, _, _ string) (msg.AccessStatus, error) {
+	return f.accessStatus, nil
+}
+
+func (f *fakeClient) CheckHTTPAccess(_ context.Context,
+	req *msg.HTTPAccessCheckRequest) (*msg.HTTPAccessCheckResponse, error) {
+
+	f.httpRequest = req
+	return &msg.HTTPAccessCheckResponse{AccessCheckStatus: f.accessStatus, Access: "read",
+		Resource: "sports:articles", StalePolicyMode: msg.StalePolicyMode_FAIL_OPEN}, nil
+}
+
+// exitCode returns the exit code of a command error, 0 for nil.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(cli.ExitCoder); ok {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func TestParseHeaders(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		headers []string
+		parsed  map[string]string
+		err     bool
+	}{
+		{headers: nil, parsed: map[string]string{}},
+		{headers: []string{"Accept=*/*", "Athenz-Role-Auth=v=Z1;d=sports"},
+			parsed: map[string]string{"Accept": "*/*", "Athenz-Role-Auth": "v=Z1;d=sports"}},
+		{headers: []string{"X-Empty="}, parsed: map[string]string{"X-Empty": ""}},
+		{headers: []string{"Accept"}, err: true},
+		{headers: []string{"=value"}, err: true},
+	} {
+		parsed, err := parseHeaders(tc.headers)
+		if tc.err {
+			a.Error(err, tc.headers)
+			continue
+		}
+		a.NoError(err, tc.headers)
+		a.Equal(tc.parsed, parsed, tc.headers)
+	}
+}
+
+func TestCheckAccess(t *testing.T) {
+	a := assert.New(t)
+	c := &fakeClient{accessStatus: msg.AccessStatus_DENY_NO_MATCH}
+
+	result, err := checkAccess(c, "GET", "/v1/articles", []string{"Accept=*/*"})
+	if a.NoError(err) {
+		a.Equal(&checkResult{Status: "DENY_NO_MATCH", Code: int32(msg.AccessStatus_DENY_NO_MATCH), Access: "read",
+			Resource: "sports:articles", StalePolicyMode: "FAIL_OPEN"}, result)
+		a.Equal(map[string]string{"Accept": "*/*"}, c.httpRequest.Headers)
+	}
+	_, err = checkAccess(c, "GET", "/v1/articles", []string{"Accept"})
+	a.Error(err)
+
+	c.accessStatus = msg.AccessStatus_ALLOW
+	result, err = checkAccess(c, "", "", nil)
+	if a.NoError(err) {
+		a.Equal("ALLOW", result.Status)
+		a.Empty(result.StalePolicyMode)
+	}
+}
+
+func TestExitCodes(t *testing.T) {
+	a := assert.New(t)
+	for _, tc := range []struct {
+		accessStatus msg.AccessStatus
+		code         int
+	}{
+		{msg.AccessStatus_ALLOW, 0},
+		{msg.AccessStatus_DENY, exitDenied},
+		{msg.AccessStatus_DENY_NO_MATCH, exitDenied},
+		{msg.AccessStatus_DENY_ROLE_TOKEN_EXPIRED, exitDenied},
+		{msg.AccessStatus_DENY_DOMAIN_LOADING, exitDenied},
+	} {
+		a.Equal(tc.code, exitCode(statusExitError(tc.accessStatus)), tc.accessStatus.String())
+	}
+
+	defer func(t, ac, r, p, s string) { token, access, resource, port, socket = t, ac, r, p, s }(token, access,
+		resource, port, socket)
+	token, access, resource, port, socket = "", "", "", "", ""
+	a.Equal(exitFailed, exitCode(check("", "", nil)))
+	token, access, resource = "v=Z1;d=sports;r=reader", "read", "sports:articles"
+	// the agent address is missing
+	a.Equal(exitFailed, exitCode(check("", "", nil)))
+	a.Equal(exitFailed, exitCode(decode("v=Z1")))
+	a.Equal(exitFailed, exitCode(serviceToken("", nil, 0, 0, "")))
+}
+
+func TestBuildCLI(t *testing.T) {
+	a := assert.New(t)
+	helpFlag := cli.HelpFlag
+	app := BuildCLI()
+	a.Equal(helpFlag, cli.HelpFlag)
+
+	var out bytes.Buffer
+	app.Writer = &out
+	a.NoError(app.Run([]string{"client", "help"}))
+	a.Contains(out.String(), "--host value, -h value")
+	a.Contains(out.String(), "--help")
+
+	out.Reset()
+	a.NoError(app.Run([]string{"client", "help", "decode"}))
+	a.Contains(out.String(), "decode [token]")
+}
@@ -15,7 +15,9 @@
 package athenzagent
 
 import (
+	"github.com/hamed-yousefi/athenz-agent/common/log"
 	"github.com/urfave/cli"
+	"time"
 )
 
 var (
@@ -24,21 +26,42 @@ var (
 	resource string
 	host     string
 	port     string
+	// socket is the unix socket of the agent, it replaces host and port
+	socket     string
+	certFile   string
+	keyFile    string
+	caFile     string
+	serverName string
+	secretFile string
+	timeout    time.Duration
+	jsonOutput bool
 )
 
-// BuildCLI is the main entry point for the cadence server
+// BuildCLI is the main entry point for the athenz-agent client
 func BuildCLI() *cli.App {
-
 	app := cli.NewApp()
 	app.Name = "AthenzAgent"
-	app.Usage = "AthenzAgent server"
+	app.Usage = "AthenzAgent client"
 	app.Version = "0.0.1"
+	// -h is the host flag, the help flag of the app only has the long name
+	// and the global cli.HelpFlag of the commands isn't changed
+	app.HideHelp = true
+	// logs are written to stdout, only fatal ones are kept to not mix them
+	// with the output
+	app.Before = func(c *cli.Context) error {
+		if c.Bool("help") {
+			cli.ShowAppHelpAndExit(c, 0)
+		}
+		log.NewLogrusInitializer().InitialLog(log.Fatal)
+		return nil
+	}
 
 	app.Flags = []cli.Flag{
 		cli.StringFlag{
 			Name:        "token, t",
 			Value:       "",
 			Usage:       "RoleToken for client mode",
+			EnvVar:      "ATHENZ_ROLE_TOKEN",
 			Destination: &token,
 		},
 		cli.StringFlag{
@@ -55,7 +78,7 @@ func BuildCLI() *cli.App {
 		},
 		cli.StringFlag{
 			Name:        "host, h",
-			Value:       "",
+			Value:       "127.0.0.1",
 			Usage:       "gRPC server address that client wants to connect",
 			Destination: &host,
 		},
@@ -65,15 +88,172 @@ func BuildCLI() *cli.App {
 			Usage:       "gRPC server port number that client wants to connect",
 			Destination: &port,
 		},
+		cli.StringFlag{
+			Name:        "socket, s",
+			Usage:       "Unix socket path of the agent, replaces host and port",
+			Destination: &socket,
+		},
+		cli.StringFlag{
+			Name:        "cert",
+			Usage:       "Client certificate file for mutual TLS",
+			Destination: &certFile,
+		},
+		cli.StringFlag{
+			Name:        "key",
+			Usage:       "Client private key file for mutual TLS",
+			Destination: &keyFile,
+		},
+		cli.StringFlag{
+			Name:        "ca",
+			Usage:       "CA certificate file to verify the agent certificate, system roots are used if empty",
+			Destination: &caFile,
+		},
+		cli.StringFlag{
+			Name:        "server-name",
+			Usage:       "Expected name of the agent certificate, the host is used if empty",
+			Destination: &serverName,
+		},
+		cli.StringFlag{
+			Name:        "secret-file",
+			Usage:       "File of the shared secret of server authorization rules",
+			Destination: &secretFile,
+		},
+		cli.DurationFlag{
+			Name:        "timeout",
+			Value:       5 * time.Second,
+			Usage:       "Timeout of the agent call",
+			Destination: &timeout,
+		},
+		cli.BoolFlag{
+			Name:        "json, j",
+			Usage:       "Print the output in JSON",
+			Destination: &jsonOutput,
+		},
+		cli.BoolFlag{
+			Name:  "help",
+			Usage: "show help",
+		},
 	}
 
 	app.Commands = []cli.Command{
 		{
-			Name:    "client",
-			Aliases: []string{""},
-			Usage:   "check an access to a resource by client api",
-			Action: func(c *cli.Context) {
-				run()
+			Name:    "check",
+			Aliases: []string{"client"},
+			Usage:   "check an access to a resource, exit code is 0 if it's allowed, 1 if it's denied and 2 on errors",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "method, m",
+					Usage: "HTTP method, checks the HTTP request mapped by http_mappings instead of access and resource",
+				},
+				cli.StringFlag{
+					Name:  "path",
+					Usage: "HTTP path of the request, with query",
+				},
+				cli.StringSliceFlag{
+					Name:  "header",
+					Usage: "HTTP header of the request in name=value format, can be repeated",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return check(c.String("method"), c.String("path"), c.StringSlice("header"))
+			},
+		},
+		{
+			Name:  "token",
+			Usage: "get a role token of the agent service",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "domain, d",
+					Usage: "domain of the role token",
+				},
+				cli.StringSliceFlag{
+					Name:  "role",
+					Usage: "role of the role token, can be repeated, all roles of the domain if empty",
+				},
+				cli.IntFlag{
+					Name:  "min-expiry",
+					Usage: "min expiry time of the token in seconds, 0 uses token_expiration_min of zpe config",
+				},
+				cli.IntFlag{
+					Name:  "max-expiry",
+					Usage: "max expiry time of the token in seconds, 0 uses token_expiration_max of zpe config",
+				},
+				cli.StringFlag{
+					Name:  "proxy-for-principal",
+					Usage: "principal that the token is requested for",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return serviceToken(c.String("domain"), c.StringSlice("role"), c.Int("min-expiry"),
+					c.Int("max-expiry"), c.String("proxy-for-principal"))
+			},
+		},
+		{
+			Name:      "decode",
+			Usage:     "decode a role token offline and print its fields, the signature isn't verified",
+			ArgsUsage: "[token]",
+			Action: func(c *cli.Context) error {
+				return decode(c.Args().First())
+			},
+		},
+		{
+			Name:  "explain",
+			Usage: "check an access and explain the result with the role token fields",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "offline",
+					Usage: "don't call the agent, only check the role token and the resource",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return explain(c.Bool("offline"))
+			},
+		},
+		{
+			Name:  "domains",
+			Usage: "list the policy files of the agent with their domain, load time, validity, expiry and last error",
+			Action: func(c *cli.Context) error {
+				return listDomains()
+			},
+		},
+		{
+			Name:      "refresh",
+			Usage:     "download and load the policy files of the domains now, all domains of the agent by default",
+			ArgsUsage: "[domain]...",
+			Action: func(c *cli.Context) error {
+				return refreshPolicies(c.Args())
+			},
+		},
+		{
+			Name:  "reload",
+			Usage: "verify and load all policy files of the agent again",
+			Action: func(c *cli.Context) error {
+				return reloadCache()
+			},
+		},
+		{
+			Name:  "flush-tokens",
+			Usage: "remove the cached role tokens of the agent",
+			Action: func(c *cli.Context) error {
+				return flushTokenCache()
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "print the status of the agent caches and the last policy download",
+			Action: func(c *cli.Context) error {
+				return agentStatus()
+			},
+		},
+		{
+			Name:      "help",
+			Usage:     "show the commands or the help of a command",
+			ArgsUsage: "[command]",
+			Action: func(c *cli.Context) error {
+				if c.Args().Present() {
+					return cli.ShowCommandHelp(c, c.Args().First())
+				}
+				return cli.ShowAppHelp(c)
 			},
 		},
 	}
@@ -0,0 +1,99 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 10:30 AM
+ *
+ * Description:
+ *
+ */
+
+package athenzagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/urfave/cli"
+	"os"
+	"time"
+)
+
+// inspectPolicies prints the report of each policy file. It returns an exit
+// error if a file can't be decoded or its signatures aren't verified, or if
+// it has warnings and strict is set.
+func inspectPolicies(paths []string, jsonOutput, strict bool) error {
+	log.NewLogrusInitializer().InitialLog(log.Fatal)
+
+	if len(paths) == 0 {
+		return cli.NewExitError("policy files are required", 1)
+	}
+	if err := loadKeyStore(); err != nil {
+		fmt.Fprintf(os.Stderr, "public keys are not loaded, signatures are not verified: %s\n", err.Error())
+	}
+
+	failed := false
+	reports := make([]*cache.PolicyReport, 0, len(paths))
+	for _, path := range paths {
+		report, err := cache.InspectPolicyFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			failed = true
+			continue
+		}
+		if !report.Verified() || (strict && len(report.Warnings) > 0) {
+			failed = true
+		}
+		reports = append(reports, report)
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+	} else {
+		for i, report := range reports {
+			if i > 0 {
+				fmt.Println()
+			}
+			printPolicyReport(report)
+		}
+	}
+
+	if failed {
+		return cli.NewExitError("policy inspection failed", 1)
+	}
+	return nil
+}
+
+func printPolicyReport(report *cache.PolicyReport) {
+	expires := report.Expires.Format(time.RFC3339)
+	if report.Expired {
+		expires += " (expired)"
+	}
+
+	fmt.Printf("file: %s\n", report.File)
+	fmt.Printf("domain: %s\n", report.Domain)
+	fmt.Printf("modified: %s\n", report.Modified.Format(time.RFC3339))
+	fmt.Printf("expires: %s\n", expires)
+	fmt.Printf("zts signature: %s, key id: %s\n", report.ZtsSignature, report.ZtsKeyID)
+	fmt.Printf("zms signature: %s, key id: %s\n", report.ZmsSignature, report.ZmsKeyID)
+	fmt.Printf("policies: %d\n", report.Policies)
+	fmt.Printf("assertions: %d allow, %d deny, %d standard, %d wildcard\n", report.Assertions.Allow,
+		report.Assertions.Deny, report.Assertions.Standard, report.Assertions.Wildcard)
+	if len(report.Warnings) == 0 {
+		return
+	}
+	fmt.Println("warnings:")
+	for _, warning := range report.Warnings {
+		fmt.Printf("- %s\n", warning)
+	}
+}
@@ -17,13 +17,16 @@ package athenzagent
 import (
 	"context"
 	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/cache"
 	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/hamed-yousefi/athenz-agent/common/log"
 	"github.com/hamed-yousefi/athenz-agent/config"
 	"github.com/hamed-yousefi/athenz-agent/grpc/api"
 	"github.com/hamed-yousefi/athenz-agent/grpc/server"
 	"github.com/hamed-yousefi/athenz-agent/monitor"
+	"strings"
 	"sync"
+	"time"
 )
 
 func run() {
@@ -33,21 +36,67 @@ func run() {
 
 	loadConfigs()
 	logInit := log.NewLogrusInitializer()
-	logInit.InitialLog(log.GetLevel(config.AgentConfig.Properties.Log.GetLevel())).
-		SetupRotation(config.AgentConfig.Properties.Log)
+	logProperties := config.AgentConfig.Get().Log
+	rotator := logInit.InitialLog(log.GetLevel(logProperties.GetLevel()))
+	rotator.SetupRotation(logProperties)
 
 	logger := log.GetLogger(common.GolangFileName())
 
+	// apply log config changes at runtime
+	config.AgentConfig.OnLogChange(func(provider common.LogConfigProvider) {
+		level, err := log.ParseLevel(provider.GetLevel())
+		if err != nil {
+			logger.Error("unable to reload log config, error: " + err.Error())
+			return
+		}
+		logInit.SetLevel(level)
+		rotator.SetupRotation(provider)
+		logger.Info("log config reloaded, level: " + level.String())
+	})
+
+	// notify the components that depend on reloaded configs
+	config.KeyStore.OnChange(func() {
+		// cached tokens and loaded policies were verified by the previous keys
+		cache.FlushRoleTokenCache()
+		cache.ReloadAll()
+		config.ZpuConfig.Reload()
+	})
+	// compile http_mappings before the first request and after each change
+	api.CompileHTTPMappings()
+	config.ZpeConfig.OnChange(api.CompileHTTPMappings)
+	serverProperties := config.AgentConfig.Get().Server
+	config.AgentConfig.OnChange(func() {
+		properties := config.AgentConfig.Get().Server
+		if properties.Port != serverProperties.Port || properties.Address != serverProperties.Address ||
+			properties.SocketPath != serverProperties.SocketPath || properties.HTTPPort != serverProperties.HTTPPort ||
+			properties.MetricsAddress != serverProperties.MetricsAddress {
+			logger.Error("server port, address, socket path, http port or metrics address change requires restart, " +
+				"port: " + serverProperties.Port + ", address: " + serverProperties.Address + ", socket path: " +
+				serverProperties.SocketPath + ", http port: " + serverProperties.HTTPPort + ", metrics address: " +
+				serverProperties.MetricsAddress)
+		}
+	})
+
 	// make new directory for metric file, if it doesn't exist
-	if err := common.CreateAllDirectories(config.ZpuConfig.Properties.MetricsDir); err != nil {
+	if err := common.CreateAllDirectories(config.ZpuConfig.Get().MetricsDir); err != nil {
 		logger.Fatalf("cannot create metrics directory, error: %s", err.Error())
 	}
 
 	// make new directory for policy files, if it doesn't exist
-	if err := common.CreateAllDirectories(config.ZpuConfig.Properties.PolicyFileDir); err != nil {
+	if err := common.CreateAllDirectories(config.ZpuConfig.Get().PolicyFileDir); err != nil {
 		logger.Fatalf("cannot create policy directory, error: %s" + err.Error())
 	}
 
+	// load the policies of the last run before the gRPC server accepts
+	// requests, the cache monitor reconciles them with the policy files.
+	// They are verified by the keys of athenz config and the keys cache.
+	if snapshotFile := config.ZpeConfig.Get().PolicySnapshotFile; snapshotFile != "" {
+		monitor.LoadCachedKeys()
+		if _, err := cache.LoadSnapshot(snapshotFile); err != nil {
+			logger.Error("unable to load policy snapshot, error: " + err.Error())
+		}
+	}
+
 	// ZPU channel, it's pipeline for sending error
 	cacheChan := make(chan string)
 	// gRPC server channel, it's pipeline for sending error
@@ -64,20 +113,55 @@ func run() {
 	ctx, cancel := context.WithCancel(ctx)
 
 	permissionService := &api.PermissionService{}
+	adminService := &api.AdminService{LogInitializer: logInit, StartTime: time.Now()}
 
 	// start policy downloader
 	go monitor.NewZpuMonitor().Start(downloaderChan)
 	// start caching policy files into memory
 	go monitor.NewCacheMonitor().Start(cacheChan)
+	// start refreshing ZTS and ZMS public keys
+	go monitor.NewKeyMonitor().Start(cacheChan)
+	// get service identity certificate before starting the gRPC server,
+	// it may use the certificate for mTLS, and refresh it before expiry
+	identityMonitor := monitor.NewIdentityMonitor()
+	if err := identityMonitor.Init(); err != nil {
+		logger.Error("unable to get service identity certificate, error: " + err.Error())
+	}
+	go identityMonitor.Start(cacheChan)
 
 	// start gRPC server in a goroutine
 	waitGrp.Add(1)
 	go func() {
-		if err := server.RunServer(ctx, permissionService, config.AgentConfig.Properties.Server.Port, &waitGrp); err != nil {
+		if err := server.RunServer(ctx, permissionService, config.AgentConfig.Get().Server.Port, &waitGrp,
+			server.WithAdminService(adminService), server.WithExtAuthzService(&api.ExtAuthzService{}),
+			server.WithCredentialReload(identityMonitor.OnChange)); err != nil {
 			serverStatusChan <- fmt.Sprintf("%s> gRPC server failed to start, error: %s", common.FuncName(), err.Error())
 		}
 	}()
 
+	// start HTTP/JSON gateway in a goroutine, if it's enabled
+	if httpPort := config.AgentConfig.Get().Server.HTTPPort; httpPort != "" {
+		waitGrp.Add(1)
+		go func() {
+			if err := server.RunHTTPGateway(ctx, permissionService, httpPort, &waitGrp,
+				server.WithCredentialReload(identityMonitor.OnChange)); err != nil {
+				serverStatusChan <- fmt.Sprintf("%s> HTTP gateway failed to start, error: %s", common.FuncName(),
+					err.Error())
+			}
+		}()
+	}
+
+	// start metrics listener in a goroutine, if it's enabled
+	if metricsAddress, _ := config.AgentConfig.Get().Server.MetricsListenAddress(); metricsAddress != "" {
+		waitGrp.Add(1)
+		go func() {
+			if err := server.RunMetricsServer(ctx, metricsAddress, &waitGrp); err != nil {
+				serverStatusChan <- fmt.Sprintf("%s> metrics listener failed to start, error: %s", common.FuncName(),
+					err.Error())
+			}
+		}()
+	}
+
 	// os.Signal notifier goroutine
 	go func() {
 		waitGrp.Wait()
@@ -118,24 +202,71 @@ func run() {
 
 }
 
-// loadConfigs loads all configurations
+// loadConfigs loads and validates all configurations. It exits if any
+// configuration is invalid and prints all problems at once.
 func loadConfigs() {
 	// use function name in logs and errors
 	funcName := common.FuncName()
 
+	if errs := loadAndValidateConfigs(); len(errs) > 0 {
+		messages := make([]string, 0, len(errs))
+		for _, err := range errs {
+			messages = append(messages, err.Error())
+		}
+		common.Fatalf("%s> invalid configuration:\n%s", funcName, strings.Join(messages, "\n"))
+	}
+}
+
+// loadAndValidateConfigs loads all configurations into global config
+// variables and validates them. It returns all load and validation errors.
+func loadAndValidateConfigs() []error {
+	if unifiedConfPath != "" {
+		return loadAndValidateUnifiedConfig()
+	}
+
+	errs := make([]error, 0)
+
 	if err := config.LoadGlobalAgentConfig(agentConfPath); err != nil {
-		common.Fatalf("%s> unable to read agent config file, error: %s", funcName, err.Error())
+		errs = append(errs, common.Errorf("unable to read agent config file, error: %s", err.Error()))
+	} else if err := config.AgentConfig.Validate(); err != nil {
+		errs = append(errs, err)
 	}
 
 	if err := config.LoadGlobalZpeConfig(zpeConfigPath); err != nil {
-		common.Fatalf("%s> unable to open zpe config file, error: %s", funcName, err.Error())
+		errs = append(errs, common.Errorf("unable to open zpe config file, error: %s", err.Error()))
+	} else if err := config.ZpeConfig.Validate(); err != nil {
+		errs = append(errs, err)
 	}
 
 	if err := config.LoadGlobalAthenzConfig(athenzConfigPath); err != nil {
-		common.Fatalf("%s> unable to open athenz config file, error: %s", funcName, err.Error())
+		errs = append(errs, common.Errorf("unable to open athenz config file, error: %s", err.Error()))
+	} else if err := config.KeyStore.Validate(); err != nil {
+		errs = append(errs, err)
 	}
 
 	if err := config.LoadGlobalZpuConfig(athenzConfigPath, zpuConfigPath); err != nil {
-		common.Fatalf("%s> unable to open zpu config file, error: %s", funcName, err.Error())
+		errs = append(errs, common.Errorf("unable to open zpu config file, error: %s", err.Error()))
+	} else if err := config.ZpuConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// loadAndValidateUnifiedConfig loads unified configuration file into global
+// config variables and validates them. It returns all load and validation
+// errors.
+func loadAndValidateUnifiedConfig() []error {
+	if err := config.LoadGlobalUnifiedConfig(unifiedConfPath); err != nil {
+		return []error{common.Errorf("unable to read unified config file, error: %s", err.Error())}
+	}
+
+	errs := make([]error, 0)
+	for _, validator := range []interface{ Validate() error }{
+		config.AgentConfig, config.ZpeConfig, config.KeyStore, config.ZpuConfig} {
+		if err := validator.Validate(); err != nil {
+			errs = append(errs, err)
+		}
 	}
+	return errs
 }
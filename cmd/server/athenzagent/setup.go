@@ -16,6 +16,7 @@ package athenzagent
 
 import (
 	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/policytest"
 	"github.com/urfave/cli"
 )
 
@@ -24,6 +25,8 @@ var (
 	zpuConfigPath    string
 	zpeConfigPath    string
 	agentConfPath    string
+	// unifiedConfPath replaces all config files above, if it's set
+	unifiedConfPath string
 )
 
 // BuildCLI is the main entry point for the cadence server
@@ -63,6 +66,12 @@ func BuildCLI() *cli.App {
 			EnvVar:      config.EnvKeyAgentConfigPath,
 			Destination: &agentConfPath,
 		},
+		cli.StringFlag{
+			Name:        "config, f",
+			Usage:       "Unified configuration file path, replaces all other configuration files",
+			EnvVar:      config.EnvKeyUnifiedConfigPath,
+			Destination: &unifiedConfPath,
+		},
 	}
 
 	app.Commands = []cli.Command{
@@ -74,6 +83,71 @@ func BuildCLI() *cli.App {
 				run()
 			},
 		},
+		{
+			Name:  "validate-config",
+			Usage: "validate configuration files and print all problems",
+			Action: func(c *cli.Context) error {
+				return validateConfig()
+			},
+		},
+		{
+			Name:  "convert-config",
+			Usage: "convert agent, zpe, zpu and athenz configuration files into one unified file",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Value: config.DefaultUnifiedConfigPath,
+					Usage: "unified configuration file path, JSON if it has .json extension, otherwise TOML",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return convertConfig(c.String("output"))
+			},
+		},
+		{
+			Name:  "test-policies",
+			Usage: "evaluate test cases of a YAML or JSON file against the policy files of a directory, offline",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "policy-dir, d",
+					Usage: "directory of the policy files (.pol)",
+				},
+				cli.StringFlag{
+					Name:  "cases, t",
+					Usage: "YAML or JSON file of test cases",
+				},
+				cli.BoolFlag{
+					Name:  "skip-verification",
+					Usage: "load policy files without verifying their signatures by athenz config public keys",
+				},
+				cli.BoolFlag{
+					Name:  "ignore-expiry",
+					Usage: "evaluate the policies of expired policy files",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return testPolicies(c.String("policy-dir"), c.String("cases"), policytest.Options{
+					SkipVerification: c.Bool("skip-verification"), IgnoreExpiry: c.Bool("ignore-expiry")})
+			},
+		},
+		{
+			Name:      "inspect",
+			Usage:     "report the signatures and assertions of policy files and lint their assertions",
+			ArgsUsage: "<policy file>...",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "json, j",
+					Usage: "print the reports in JSON",
+				},
+				cli.BoolFlag{
+					Name:  "strict",
+					Usage: "fail if a policy file has warnings",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return inspectPolicies(c.Args(), c.Bool("json"), c.Bool("strict"))
+			},
+		},
 	}
 
 	return app
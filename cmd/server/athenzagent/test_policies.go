@@ -0,0 +1,84 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:00 AM
+ *
+ * Description:
+ *
+ */
+
+package athenzagent
+
+import (
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/policytest"
+	"github.com/urfave/cli"
+	"strings"
+)
+
+// testPolicies loads the policy files of policyDir and evaluates the cases
+// of casesPath, it prints the result of each case. It returns an exit error
+// if a case fails. Signatures are verified by the public keys of athenz
+// config unless opts skips the verification.
+func testPolicies(policyDir, casesPath string, opts policytest.Options) error {
+	// invalid policy files are reported by LoadPolicies, logs would be
+	// mixed with the results
+	log.NewLogrusInitializer().InitialLog(log.Fatal)
+
+	if policyDir == "" || casesPath == "" {
+		return cli.NewExitError("policy directory and cases file are required", 1)
+	}
+	if !opts.SkipVerification {
+		if err := loadKeyStore(); err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+	}
+
+	cases, err := policytest.LoadCases(casesPath)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	domains, err := policytest.LoadPolicies(policyDir, opts)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+	fmt.Printf("loaded domains: %s\n", strings.Join(domains, ", "))
+
+	failed := 0
+	for _, result := range policytest.Run(cases, opts) {
+		if result.Passed {
+			fmt.Printf("PASS %s\n", result.Case)
+			continue
+		}
+		failed++
+		fmt.Printf("FAIL %s: expected %s, got %s\n", result.Case, strings.ToUpper(result.Case.Expect),
+			result.Status)
+	}
+
+	fmt.Printf("%d cases, %d passed, %d failed\n", len(cases), len(cases)-failed, failed)
+	if failed > 0 {
+		return cli.NewExitError("policy test failed", 1)
+	}
+	return nil
+}
+
+// loadKeyStore loads the public keys of the unified config file, or the
+// athenz config file.
+func loadKeyStore() error {
+	if unifiedConfPath != "" {
+		if err := config.LoadGlobalUnifiedConfig(unifiedConfPath); err != nil {
+			return fmt.Errorf("unable to read unified config file, error: %s", err.Error())
+		}
+	} else if err := config.LoadGlobalAthenzConfig(athenzConfigPath); err != nil {
+		return fmt.Errorf("unable to open athenz config file, error: %s", err.Error())
+	}
+	return config.KeyStore.Validate()
+}
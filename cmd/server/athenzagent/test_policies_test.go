@@ -0,0 +1,87 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:50 AM
+ *
+ * Description:
+ *
+ */
+
+package athenzagent
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/policytest"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sportsPolicy = `{"signedPolicyData":{"expires":"%s","modified":"2020-06-02T06:11:12.125Z",
+"policyData":{"domain":"sports","policies":[{"name":"sports:policy.readers","assertions":[
+{"action":"read","effect":"ALLOW","resource":"sports:articles.*","role":"sports:role.reader"}]}]},
+"zmsSignature":"unsigned","zmsKeyId":"0"},"signature":"unsigned","keyId":"0"}`
+
+func TestTestPolicies(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "test-policies")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"policies/sports.pol": strings.Replace(sportsPolicy, "%s", "2099-01-01T00:00:00.000Z", 1),
+		"expired/old.pol":     strings.Replace(sportsPolicy, "%s", "2020-01-01T00:00:00.000Z", 1),
+		"pass.yaml":           "- {domain: sports, roles: [reader], action: read, resource: articles.x, expect: ALLOW}",
+		"fail.yaml":           "- {domain: sports, roles: [reader], action: write, resource: articles.x, expect: ALLOW}",
+		"invalid.yaml":        "- {domain: sports, roles: [reader], action: read, resource: articles.x, expect: MAYBE}",
+	}
+	for name, content := range files {
+		a.NoError(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		a.NoError(ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	defer func(athenz, unified string) {
+		athenzConfigPath, unifiedConfPath = athenz, unified
+	}(athenzConfigPath, unifiedConfPath)
+	athenzConfigPath, unifiedConfPath = filepath.Join(dir, "missing.conf"), ""
+
+	// policy files are cached by name and modification time, the expired
+	// file has another name
+	skip := policytest.Options{SkipVerification: true}
+	for _, tc := range []struct {
+		name      string
+		policyDir string
+		cases     string
+		opts      policytest.Options
+		code      int
+	}{
+		{name: "pass", policyDir: "policies", cases: "pass.yaml", opts: skip},
+		{name: "failed case", policyDir: "policies", cases: "fail.yaml", opts: skip, code: 1},
+		{name: "invalid cases", policyDir: "policies", cases: "invalid.yaml", opts: skip, code: 1},
+		{name: "missing cases", policyDir: "policies", cases: "missing.yaml", opts: skip, code: 1},
+		{name: "no policy files", policyDir: "missing", cases: "pass.yaml", opts: skip, code: 1},
+		{name: "no athenz config", policyDir: "policies", cases: "pass.yaml", code: 1},
+		{name: "expired", policyDir: "expired", cases: "pass.yaml", opts: skip, code: 1},
+		{name: "ignore expiry", policyDir: "expired", cases: "pass.yaml",
+			opts: policytest.Options{SkipVerification: true, IgnoreExpiry: true}},
+	} {
+		err := testPolicies(filepath.Join(dir, tc.policyDir), filepath.Join(dir, tc.cases), tc.opts)
+		if tc.code == 0 {
+			a.NoError(err, tc.name)
+			continue
+		}
+		if exitErr, ok := err.(cli.ExitCoder); a.True(ok, tc.name) {
+			a.Equal(tc.code, exitErr.ExitCode(), tc.name)
+		}
+	}
+
+	err = testPolicies("", filepath.Join(dir, "pass.yaml"), skip)
+	a.Error(err)
+}
@@ -0,0 +1,49 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 2:10 PM
+ *
+ * Description:
+ *
+ */
+
+package athenzagent
+
+import (
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/urfave/cli"
+)
+
+// validateConfig loads all configuration files and prints all problems.
+// It returns an exit error if any configuration is invalid.
+func validateConfig() error {
+	errs := loadAndValidateConfigs()
+	if len(errs) == 0 {
+		fmt.Println("configuration is valid")
+		return nil
+	}
+
+	for _, err := range errs {
+		fmt.Println(err.Error())
+	}
+	return cli.NewExitError("configuration is invalid", 1)
+}
+
+// convertConfig writes the legacy configuration files into one unified
+// configuration file.
+func convertConfig(outPath string) error {
+	if err := config.ConvertLegacyConfig(agentConfPath, zpeConfigPath, athenzConfigPath,
+		zpuConfigPath, outPath); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	fmt.Println("unified configuration is written to " + outPath)
+	return nil
+}
@@ -68,10 +68,16 @@ type (
 		// InitialLog creates a log object internally and
 		// returns a log rotator object for optional extra configuration.
 		InitialLog(level Level) Rotator
+		// SetLevel changes the log level at runtime.
+		SetLevel(level Level)
+		// CurrentLevel returns the active log level.
+		CurrentLevel() Level
 	}
 
 	// Rotator the interface to wrap log rotation config.
 	Rotator interface {
+		// SetupRotation creates a rotating output writer. Calling it again
+		// replaces the previous writer with a new one.
 		SetupRotation(provider common.LogConfigProvider)
 	}
 )
@@ -86,9 +92,19 @@ func (l Level) String() string {
 
 // GetLevel convert a string to a log level. If log level wasn't valid calls log.Fatalf.
 func GetLevel(in string) Level {
+	level, err := ParseLevel(in)
+	if err != nil {
+		common.Fatal(err.Error())
+	}
+	return level
+}
+
+// ParseLevel converts a string to a log level. It returns an error if the
+// input is not a valid log level.
+func ParseLevel(in string) (Level, error) {
 	level, ok := string2Level[strings.ToLower(in)]
 	if !ok {
-		common.Fatalf("invalid input, level: %s", in)
+		return 0, common.Errorf("invalid input, level: %s", in)
 	}
-	return level
+	return level, nil
 }
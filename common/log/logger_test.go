@@ -38,3 +38,13 @@ func TestGetLevel(t *testing.T) {
 	a.Equal(Debug, GetLevel("deBug"))
 	a.Equal(Trace, GetLevel("tracE"))
 }
+
+func TestParseLevel(t *testing.T) {
+	a := assert.New(t)
+	level, err := ParseLevel("DEBUG")
+	a.NoError(err)
+	a.Equal(Debug, level)
+
+	_, err = ParseLevel("verbose")
+	a.Error(err)
+}
@@ -29,7 +29,8 @@ const (
 
 var (
 	singleton sync.Once
-	log       = new(logrusInitializer)
+	// the default logger writes to stderr until InitialLog is called
+	log = &logrusInitializer{logger: logrus.New()}
 )
 
 type (
@@ -46,6 +47,10 @@ type (
 	// that stored in logrusInitializer
 	logrusInitializer struct {
 		logger *logrus.Logger
+		// mu guards writer, it's the current log rotation writer that must
+		// be closed when rotation config changes at runtime.
+		mu     sync.Mutex
+		writer io.Closer
 	}
 
 	// logrusLogRotator is an implementation of Rotator for logrus.
@@ -111,9 +116,10 @@ func NewLogrusInitializer() Initializer {
 // a logrus instance. This initialization happens just once in entire
 // application lifecycle.
 func (l *logrusInitializer) InitialLog(level Level) Rotator {
-	// initial logrus log just once in entire application lifecycle
+	// initial logrus log just once in entire application lifecycle, the
+	// default logger is configured instead of replaced because loggers of
+	// other goroutines may already use it
 	singleton.Do(func() {
-		log.logger = logrus.New()
 		log.logger.SetFormatter(&logrus.JSONFormatter{})
 		lvl, err := logrus.ParseLevel(level.String())
 		if err != nil {
@@ -131,6 +137,27 @@ func (l *logrusInitializer) InitialLog(level Level) Rotator {
 	}
 }
 
+// SetLevel changes logrus log level at runtime. It is safe to call SetLevel
+// concurrently with logging.
+func (l *logrusInitializer) SetLevel(level Level) {
+	lvl, err := logrus.ParseLevel(level.String())
+	if err != nil {
+		l.logger.Error("unable to parse input log level to logrus log level")
+		return
+	}
+	l.logger.SetLevel(lvl)
+}
+
+// CurrentLevel returns the active log level.
+func (l *logrusInitializer) CurrentLevel() Level {
+	level, err := ParseLevel(l.logger.GetLevel().String())
+	if err != nil {
+		// logrus has more levels than Logger, e.g. panic and warn.
+		return Info
+	}
+	return level
+}
+
 // SetupRotation creates a custom output writer for log.
 func (r *logrusLogRotator) SetupRotation(provider common.LogConfigProvider) {
 
@@ -155,4 +182,15 @@ func (r *logrusLogRotator) SetupRotation(provider common.LogConfigProvider) {
 	// write logs in both file and stout
 	multiWriter := io.MultiWriter(writer, os.Stdout)
 	r.logrusInit.logger.SetOutput(multiWriter)
+
+	// close the previous writer if rotation is reconfigured at runtime
+	r.logrusInit.mu.Lock()
+	previous := r.logrusInit.writer
+	r.logrusInit.writer = writer
+	r.logrusInit.mu.Unlock()
+	if previous != nil {
+		if err := previous.Close(); err != nil {
+			r.logrusInit.logger.Error(err)
+		}
+	}
 }
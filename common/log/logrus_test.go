@@ -103,3 +103,26 @@ func TestLevels(t *testing.T) {
 	logger.Debug("test debug")
 	logger.Trace("test trace")
 }
+
+func TestLogrusInitializer_SetLevel(t *testing.T) {
+	a := assert.New(t)
+	logInit := NewLogrusInitializer()
+	logInit.InitialLog(Info)
+
+	logInit.SetLevel(Debug)
+	a.Equal(Debug, logInit.CurrentLevel())
+
+	logInit.SetLevel(Error)
+	a.Equal(Error, logInit.CurrentLevel())
+}
+
+func TestLogrusLogRotator_SetupRotationTwice(t *testing.T) {
+	a := assert.New(t)
+	rotator := NewLogrusInitializer().InitialLog(Info)
+	rotator.SetupRotation(newLogConfigProviderTest(logPath))
+	rotator.SetupRotation(newLogConfigProviderTest(logPath + "2"))
+	_, err := os.Stat(logPath + "2")
+	a.NoError(err)
+	GetLogger(common.GolangFileName()).Info("Hello again!")
+	tearDown()
+}
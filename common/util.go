@@ -21,6 +21,7 @@ import (
 	"github.com/yahoo/athenz/utils/zpe-updater/util"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -96,6 +97,28 @@ func CreateFile(fileName, content string) error {
 	return nil
 }
 
+// WriteFileAtomically writes data into a temporary file in the same directory
+// and renames it, so readers never see a partially written file. Missing
+// directories are created.
+func WriteFileAtomically(fileName string, data []byte, perm os.FileMode) error {
+	if err := CreateAllDirectories(filepath.Dir(fileName)); err != nil {
+		return err
+	}
+
+	tmpFile := fileName + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, perm); err != nil {
+		return Errorf("unable to write file: %s, error: %s", tmpFile, err.Error())
+	}
+	// WriteFile doesn't change the mode of an existing file
+	if err := os.Chmod(tmpFile, perm); err != nil {
+		return Errorf("unable to change file mode: %s, error: %s", tmpFile, err.Error())
+	}
+	if err := os.Rename(tmpFile, fileName); err != nil {
+		return Errorf("unable to write file: %s, error: %s", fileName, err.Error())
+	}
+	return nil
+}
+
 // RemoveAll removes the target path and all subdirectories.
 func RemoveAll(path string) error {
 	err := os.RemoveAll(path)
@@ -162,6 +162,23 @@ func TestCreateAllDirectoriesExist(t *testing.T) {
 	_ = os.RemoveAll("tmp")
 }
 
+func TestWriteFileAtomically(t *testing.T) {
+	a := assert.New(t)
+	defer os.RemoveAll("tmp")
+
+	fileName := "tmp/certs/service.key.pem"
+	a.NoError(WriteFileAtomically(fileName, []byte("first"), 0600))
+	a.NoError(WriteFileAtomically(fileName, []byte("second"), 0600))
+
+	data, err := ioutil.ReadFile(fileName)
+	a.NoError(err)
+	a.Equal("second", string(data))
+	info, err := os.Stat(fileName)
+	a.NoError(err)
+	a.Equal(os.FileMode(0600), info.Mode().Perm())
+	a.False(Exists(fileName + ".tmp"))
+}
+
 func TestGetGolangFileName(t *testing.T) {
 	a := assert.New(t)
 	filename := GolangFileName()
@@ -17,14 +17,33 @@
 package config
 
 import (
+	"fmt"
 	"github.com/alecthomas/units"
 	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
 	convertor "github.com/xhit/go-str2duration/v2"
-	"math/rand"
+	"net"
+	"os"
+	"os/user"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+const (
+	// defaultSocketMode allows the agent user and group to connect
+	defaultSocketMode os.FileMode = 0660
+	// defaults of the log rotation properties that Validate rejects
+	defaultLogMaxAge       = 720 * time.Hour
+	defaultLogRotationTime = 24 * time.Hour
+	defaultLogMaxSize      = 20 * units.MiB
+	// defaultMetricsHost is the host of metrics listener if metrics_address
+	// has no host, metrics are not authorized so they stay on loopback
+	defaultMetricsHost = "127.0.0.1"
+)
+
 var (
 	// AgentConfig is a global variable of AgentConfiguration type. It holds agent's
 	// configuration in runtime.
@@ -38,6 +57,11 @@ type (
 		loader Loader
 		// Properties holds agent's properties
 		Properties *agentProperties
+
+		lock         sync.RWMutex
+		logMu        sync.Mutex
+		logListeners []func(provider common.LogConfigProvider)
+		publisher
 	}
 
 	agentProperties struct {
@@ -56,8 +80,58 @@ type (
 	// ServerProperties is a struct that represents grpc server information.
 	ServerProperties struct {
 		Name string
+		// Port is the TCP port, empty means no TCP listener
 		Port string
-		MtlsProperties
+		// Address is the host that TCP listeners bind to, empty means all
+		// interfaces
+		Address string
+		// HTTPPort is the port of HTTP/JSON gateway, empty disables it
+		HTTPPort string `mapstructure:"http_port"`
+		// MetricsAddress is the host:port or the port of the listener that
+		// serves expvar metrics on /debug/vars, the host defaults to
+		// 127.0.0.1 and empty disables it
+		MetricsAddress   string `mapstructure:"metrics_address"`
+		MtlsProperties   `mapstructure:",squash"`
+		SocketProperties `mapstructure:",squash"`
+		// Authorization allows callers to call RPCs, RPCs without a rule are
+		// denied, except the admin RPCs of the agent user on unix socket
+		Authorization []MethodRule `mapstructure:"authorization"`
+	}
+
+	// MethodRule allows callers to call a RPC. A caller is allowed if it
+	// matches any of the caller properties.
+	MethodRule struct {
+		// Method is the RPC name, e.g. GetServiceToken, or the full method
+		// name. The service name, e.g. AthenzAgentAdmin, matches all RPCs of
+		// the service and "*" matches all RPCs
+		Method string `mapstructure:"method"`
+		// Callers are the common names of verified mTLS client
+		// certificates, "*" matches any verified client certificate
+		Callers []string `mapstructure:"callers"`
+		// UIDs and GIDs are the peer ids of unix socket connections
+		UIDs []int `mapstructure:"uids"`
+		GIDs []int `mapstructure:"gids"`
+		// SecretFile has the shared secret that callers send in metadata
+		SecretFile string `mapstructure:"secret_file"`
+		// AllowAny allows all callers, even without any credential
+		AllowAny bool `mapstructure:"allow_any"`
+	}
+
+	// SocketProperties is a struct that stores unix domain socket listener
+	// configurations.
+	SocketProperties struct {
+		// SocketPath empty means no unix socket listener
+		SocketPath string `mapstructure:"socket_path"`
+		// SocketMode is the octal file mode of the socket, default is 0660
+		SocketMode string `mapstructure:"socket_mode"`
+		// SocketOwner and SocketGroup are names or ids, empty keeps the
+		// agent user and group
+		SocketOwner string `mapstructure:"socket_owner"`
+		SocketGroup string `mapstructure:"socket_group"`
+		// SocketAllowedUIDs and SocketAllowedGIDs restrict the peers of the
+		// socket by SO_PEERCRED, empty lists allow all peers
+		SocketAllowedUIDs []int `mapstructure:"socket_allowed_uids"`
+		SocketAllowedGIDs []int `mapstructure:"socket_allowed_gids"`
 	}
 
 	// MtlsProperties is a struct that stores mutual TLS configurations.
@@ -91,27 +165,173 @@ func LoadGlobalAgentConfig(filePath string) error {
 }
 
 // LoadAgentConfig reads config file from a specific address and loads it into
-//a AgentConfiguration object
+// a AgentConfiguration object
 func LoadAgentConfig(agentConfig *AgentConfiguration, filePath string) error {
 
 	// load config properties into agentProperties
-	agentConfig.Properties = new(agentProperties)
+	properties := new(agentProperties)
 	agentConfig.loader = NewConfigLoader()
-	if err := agentConfig.loader.LoadConfig(agentConfig.Properties, filePath); err != nil {
+	if err := agentConfig.loader.LoadConfig(properties, filePath); err != nil {
 		return common.Errorf("unable to load config from %s : %s", filePath, err.Error())
 	}
-
-	if agentConfig.Properties.Server.Port == "" {
-		rand.Seed(time.Now().UnixNano())
-		agentConfig.Properties.Server.Port = strconv.Itoa(rand.Intn(55000) + 10000)
-	}
+	agentConfig.set(properties)
 
 	// use default configuration for config loader
 	agentConfig.loader.WithDefaultConfig()
+	agentConfig.loader.OnChange(agentConfig.reload)
 
 	return nil
 }
 
+// OnLogChange registers a function that will be called with the new log
+// properties every time log section of the config file changes at runtime.
+func (c *AgentConfiguration) OnLogChange(fn func(provider common.LogConfigProvider)) {
+	c.logMu.Lock()
+	defer c.logMu.Unlock()
+	c.logListeners = append(c.logListeners, fn)
+}
+
+// Get returns the current agent properties. Use Get instead of Properties
+// field when the config file may be reloaded at runtime.
+func (c *AgentConfiguration) Get() *agentProperties {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.Properties
+}
+
+// set swaps the current agent properties.
+func (c *AgentConfiguration) set(properties *agentProperties) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.Properties = properties
+}
+
+// reload reads the changed config file, validates it and swaps the current
+// properties with the new one. Log listeners are notified only if the log
+// section was changed. Invalid changes are rejected.
+func (c *AgentConfiguration) reload() {
+	properties := new(agentProperties)
+	if err := c.loader.Reload(properties); err != nil {
+		logger.Error("unable to reload agent config, error: " + err.Error())
+		return
+	}
+
+	if err := properties.validate(c.loader.UnusedKeys()); err != nil {
+		logger.Error("agent config change rejected, error: " + err.Error())
+		return
+	}
+
+	c.lock.Lock()
+	current := c.Properties
+	if reflect.DeepEqual(properties, current) {
+		c.lock.Unlock()
+		return
+	}
+	c.Properties = properties
+	c.lock.Unlock()
+	logger.Info("agent config reloaded")
+
+	if properties.Log != current.Log {
+		c.logMu.Lock()
+		listeners := make([]func(provider common.LogConfigProvider), len(c.logListeners))
+		copy(listeners, c.logListeners)
+		c.logMu.Unlock()
+
+		for _, fn := range listeners {
+			fn(properties.Log)
+		}
+	}
+
+	c.publish()
+}
+
+// Validate checks all agent properties and returns a ValidationError that
+// contains all problems, or nil if the config is valid.
+func (c *AgentConfiguration) Validate() error {
+	return c.Get().validate(c.loader.UnusedKeys())
+}
+
+// validate checks server and log properties. Unknown keys are reported as
+// problems too, they are usually typos.
+func (p *agentProperties) validate(unknownKeys []string) error {
+	v := newValidationError("agent")
+	v.unknownKeys(unknownKeys)
+
+	if p.Server.Port != "" {
+		if port, err := strconv.Atoi(p.Server.Port); err != nil || port < 1 || port > 65535 {
+			v.addf("server.port: invalid port '%s', expected a number between 1 and 65535", p.Server.Port)
+		}
+	} else if p.Server.SocketPath == "" {
+		v.addf("server.port or server.socket_path is required")
+	}
+	if p.Server.Address != "" && p.Server.Port == "" && p.Server.HTTPPort == "" {
+		v.addf("server.port is required when server.address is set")
+	}
+	if p.Server.HTTPPort != "" {
+		if port, err := strconv.Atoi(p.Server.HTTPPort); err != nil || port < 1 || port > 65535 {
+			v.addf("server.http_port: invalid port '%s', expected a number between 1 and 65535", p.Server.HTTPPort)
+		} else if p.Server.HTTPPort == p.Server.Port {
+			v.addf("server.http_port must be different from server.port")
+		}
+	}
+	if _, err := p.Server.MetricsListenAddress(); err != nil {
+		v.addf("server.metrics_address: invalid address '%s', expected host:port or port", p.Server.MetricsAddress)
+	}
+	p.Server.SocketProperties.validate(v)
+	for i, rule := range p.Server.Authorization {
+		if rule.Method == "" {
+			v.addf("server.authorization[%d].method is required", i)
+		}
+		if !rule.AllowAny && len(rule.Callers) == 0 && len(rule.UIDs) == 0 && len(rule.GIDs) == 0 &&
+			rule.SecretFile == "" {
+			v.addf("server.authorization[%d] must have callers, uids, gids, secret_file or allow_any", i)
+		}
+		if rule.SecretFile != "" {
+			v.fileExists(fmt.Sprintf("server.authorization[%d].secret_file", i), rule.SecretFile)
+		}
+	}
+
+	if !p.Server.MtlsProperties.IsEmpty() {
+		mtlsKeys := map[string]string{
+			"server.ca_path":  p.Server.CaPath,
+			"server.crt_path": p.Server.CrtPath,
+			"server.key_path": p.Server.PrivateKeyPath,
+		}
+		for _, key := range []string{"server.ca_path", "server.crt_path", "server.key_path"} {
+			if mtlsKeys[key] == "" {
+				v.addf("%s is required when mTLS is enabled", key)
+				continue
+			}
+			v.fileExists(key, mtlsKeys[key])
+		}
+	}
+
+	if _, err := log.ParseLevel(p.Log.Level); err != nil {
+		v.addf("log.level: invalid level '%s', expected one of fatal, error, info, debug, trace", p.Log.Level)
+	}
+	if p.Log.Path == "" {
+		v.addf("log.path is required")
+	}
+	if p.Log.FilenamePattern == "" {
+		v.addf("log.filename_pattern is required")
+	}
+	durations := map[string]string{"log.max_age": p.Log.MaxAge, "log.rotation_time": p.Log.RotationTime}
+	for _, key := range []string{"log.max_age", "log.rotation_time"} {
+		if d, err := convertor.ParseDuration(durations[key]); err != nil {
+			v.addf("%s: invalid duration '%s', e.g. 24h or 30d", key, durations[key])
+		} else if d <= 0 {
+			v.addf("%s must be positive, value: %s", key, durations[key])
+		}
+	}
+	if size, err := units.ParseBase2Bytes(p.Log.MaxSize); err != nil {
+		v.addf("log.max_size: invalid size '%s', e.g. 20MB", p.Log.MaxSize)
+	} else if size <= 0 {
+		v.addf("log.max_size must be positive, value: %s", p.Log.MaxSize)
+	}
+
+	return v.orNil()
+}
+
 // GetLevel returns log level.
 func (p logProperties) GetLevel() string {
 	return p.Level
@@ -123,30 +343,33 @@ func (p logProperties) GetPath() string {
 }
 
 // GetMaxAge returns the max age of a log file before it gets purged from the file system.
+// An invalid value, which Validate rejects, returns the default 720h.
 func (p logProperties) GetMaxAge() time.Duration {
 	maxAge, err := convertor.ParseDuration(p.MaxAge)
-	if err != nil {
-		common.Fatalf("invalid input, MaxAge: %s", p.MaxAge)
+	if err != nil || maxAge <= 0 {
+		return defaultLogMaxAge
 	}
 
 	return maxAge
 }
 
-// GetRotationTime return the time between rotation.
+// GetRotationTime return the time between rotation. An invalid value, which
+// Validate rejects, returns the default 24h.
 func (p logProperties) GetRotationTime() time.Duration {
 	rotationTime, err := convertor.ParseDuration(p.RotationTime)
-	if err != nil {
-		common.Fatalf("invalid input, RotationTime: %s", p.RotationTime)
+	if err != nil || rotationTime <= 0 {
+		return defaultLogRotationTime
 	}
 
 	return rotationTime
 }
 
-// GetMaxSize returns the log file size between rotation.
+// GetMaxSize returns the log file size between rotation. An invalid value,
+// which Validate rejects, returns the default 20MB.
 func (p logProperties) GetMaxSize() int64 {
 	byteCount, err := units.ParseBase2Bytes(p.MaxSize)
-	if err != nil {
-		common.Fatalf("invalid input, MaxSize: %s", p.MaxSize)
+	if err != nil || byteCount <= 0 {
+		return int64(defaultLogMaxSize)
 	}
 
 	return int64(byteCount)
@@ -157,6 +380,139 @@ func (p logProperties) GetFilenamePattern() string {
 	return p.FilenamePattern
 }
 
+// MetricsListenAddress returns the host:port of metrics listener, or empty
+// string if metrics are disabled. An address without host, e.g. "9093" or
+// ":9093", is bound to 127.0.0.1.
+func (p ServerProperties) MetricsListenAddress() (string, error) {
+	if p.MetricsAddress == "" {
+		return "", nil
+	}
+	host, port, err := net.SplitHostPort(p.MetricsAddress)
+	if err != nil {
+		host, port = "", p.MetricsAddress
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", common.Errorf("invalid address '%s', expected host:port or port", p.MetricsAddress)
+	}
+	if host == "" {
+		host = defaultMetricsHost
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// validate checks unix socket properties.
+func (p SocketProperties) validate(v *ValidationError) {
+	if p.SocketPath == "" {
+		if p.SocketMode != "" || p.SocketOwner != "" || p.SocketGroup != "" || len(p.SocketAllowedUIDs) > 0 ||
+			len(p.SocketAllowedGIDs) > 0 {
+			v.addf("server.socket_path is required when other socket keys are set")
+		}
+		return
+	}
+	if _, err := p.FileMode(); err != nil {
+		v.addf("server.socket_mode: invalid mode '%s', expected an octal number, e.g. 0660", p.SocketMode)
+	}
+	if p.SocketOwner != "" {
+		if _, err := lookupUID(p.SocketOwner); err != nil {
+			v.addf("server.socket_owner: unknown user '%s'", p.SocketOwner)
+		}
+	}
+	if p.SocketGroup != "" {
+		if _, err := lookupGID(p.SocketGroup); err != nil {
+			v.addf("server.socket_group: unknown group '%s'", p.SocketGroup)
+		}
+	}
+}
+
+// FileMode returns the file mode of the socket.
+func (p SocketProperties) FileMode() (os.FileMode, error) {
+	if p.SocketMode == "" {
+		return defaultSocketMode, nil
+	}
+	mode, err := strconv.ParseUint(p.SocketMode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, common.Errorf("invalid socket mode '%s', expected an octal number, e.g. 0660", p.SocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// Ownership returns the uid and gid of the socket owner and group, -1 means
+// not changed.
+func (p SocketProperties) Ownership() (int, int, error) {
+	uid, gid := -1, -1
+	var err error
+	if p.SocketOwner != "" {
+		if uid, err = lookupUID(p.SocketOwner); err != nil {
+			return -1, -1, err
+		}
+	}
+	if p.SocketGroup != "" {
+		if gid, err = lookupGID(p.SocketGroup); err != nil {
+			return -1, -1, err
+		}
+	}
+	return uid, gid, nil
+}
+
+// AllowsPeer checks the peer process of a socket connection may connect.
+func (p SocketProperties) AllowsPeer(uid, gid uint32) bool {
+	if !p.IsRestricted() {
+		return true
+	}
+	for _, id := range p.SocketAllowedUIDs {
+		if uint32(id) == uid {
+			return true
+		}
+	}
+	for _, id := range p.SocketAllowedGIDs {
+		if uint32(id) == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRestricted returns true if peers of the socket are restricted.
+func (p SocketProperties) IsRestricted() bool {
+	return len(p.SocketAllowedUIDs) > 0 || len(p.SocketAllowedGIDs) > 0
+}
+
+// Matches checks the rule applies to the full method name of a RPC, e.g.
+// "/athenz.agent.api.command.v1.AthenzAgent/GetServiceToken".
+func (r MethodRule) Matches(fullMethod string) bool {
+	slash := strings.LastIndex(fullMethod, "/")
+	if r.Method == "*" || r.Method == fullMethod || r.Method == fullMethod[slash+1:] {
+		return true
+	}
+	// service name without package, e.g. AthenzAgentAdmin
+	service := fullMethod[:slash]
+	return slash > 0 && r.Method == service[strings.LastIndex(service, ".")+1:]
+}
+
+// lookupUID returns the id of a user name or the number itself.
+func lookupUID(owner string) (int, error) {
+	if id, err := strconv.Atoi(owner); err == nil {
+		return id, nil
+	}
+	u, err := user.Lookup(owner)
+	if err != nil {
+		return -1, common.Errorf("unknown socket owner '%s', error: %s", owner, err.Error())
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// lookupGID returns the id of a group name or the number itself.
+func lookupGID(group string) (int, error) {
+	if id, err := strconv.Atoi(group); err == nil {
+		return id, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return -1, common.Errorf("unknown socket group '%s', error: %s", group, err.Error())
+	}
+	return strconv.Atoi(g.Gid)
+}
+
 // IsEmpty checks if MtlsProperties has value or not. If not returns true else
 // returns false.
 func (p MtlsProperties) IsEmpty() bool {
@@ -16,7 +16,11 @@
 package config
 
 import (
+	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -47,3 +51,289 @@ func TestLogProperties_GetMaxAge(t *testing.T) {
 	a.Equal(time.Duration(720)*time.Hour, config.Properties.Log.GetMaxAge())
 	a.Equal(int64(20971520), config.Properties.Log.GetMaxSize())
 }
+
+func TestLogProperties_InvalidValues(t *testing.T) {
+	a := assert.New(t)
+	properties := logProperties{MaxAge: "forever", RotationTime: "-1h", MaxSize: "big"}
+
+	a.Equal(720*time.Hour, properties.GetMaxAge())
+	a.Equal(24*time.Hour, properties.GetRotationTime())
+	a.Equal(int64(20971520), properties.GetMaxSize())
+}
+
+func TestAgentConfiguration_OnLogChange(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	content, err := ioutil.ReadFile(filePath)
+	a.NoError(err)
+	configPath := dir + "/agent.toml"
+	a.NoError(CreateFile(configPath, string(content)))
+
+	config := new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, configPath))
+
+	changed := make(chan string, 1)
+	config.OnLogChange(func(provider common.LogConfigProvider) {
+		changed <- provider.GetLevel()
+	})
+
+	a.NoError(ioutil.WriteFile(configPath,
+		[]byte(strings.Replace(string(content), `level = "info"`, `level = "debug"`, 1)), 0644))
+
+	select {
+	case level := <-changed:
+		a.Equal("debug", level)
+		a.Equal("debug", config.Properties.Log.Level)
+	case <-time.After(5 * time.Second):
+		a.Fail("log change was not notified")
+	}
+}
+
+func TestAgentConfiguration_Validate(t *testing.T) {
+	a := assert.New(t)
+	config := new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, filePath))
+	a.NoError(config.Validate())
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/agent.toml"
+	a.NoError(CreateFile(configPath, `[server]
+port = "90910"
+ca_path = "missing-ca.pem"
+timeout = "1s"
+
+[log]
+path = "logs"
+level = "loud"
+max_age = "forever"
+max_size = "20MB"
+filename_pattern = ".%Y-%m-%d"
+rotation_time = "0s"`))
+
+	config = new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, configPath))
+	a.Equal("missing-ca.pem", config.Properties.Server.CaPath)
+
+	err = config.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"unknown key 'server.timeout'",
+		"server.port: invalid port '90910', expected a number between 1 and 65535",
+		"server.ca_path: file 'missing-ca.pem' is not accessible, stat missing-ca.pem: no such file or directory",
+		"server.crt_path is required when mTLS is enabled",
+		"server.key_path is required when mTLS is enabled",
+		"log.level: invalid level 'loud', expected one of fatal, error, info, debug, trace",
+		"log.max_age: invalid duration 'forever', e.g. 24h or 30d",
+		"log.rotation_time must be positive, value: 0s",
+	}, err.(*ValidationError).Problems)
+}
+
+func TestAgentConfiguration_ValidateSocket(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/agent.toml"
+	a.NoError(CreateFile(configPath, `[server]
+address = "127.0.0.1"
+socket_mode = "0999"
+socket_owner = "no-such-user"
+socket_allowed_uids = [1000]
+
+[log]
+path = "logs"
+level = "info"
+max_age = "720h"
+max_size = "20MB"
+filename_pattern = ".%Y-%m-%d"
+rotation_time = "24h"`))
+
+	config := new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, configPath))
+	a.Equal("", config.Properties.Server.Port)
+
+	err = config.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"server.port or server.socket_path is required",
+		"server.port is required when server.address is set",
+		"server.socket_path is required when other socket keys are set",
+	}, err.(*ValidationError).Problems)
+
+	a.NoError(CreateFile(configPath, `[server]
+socket_path = "var/run/athenz-agent.sock"
+socket_mode = "0999"
+socket_owner = "no-such-user"
+socket_group = "0"
+socket_allowed_uids = [1000]
+socket_allowed_gids = [2000]
+
+[log]
+path = "logs"
+level = "info"
+max_age = "720h"
+max_size = "20MB"
+filename_pattern = ".%Y-%m-%d"
+rotation_time = "24h"`))
+
+	config = new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, configPath))
+	err = config.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"server.socket_mode: invalid mode '0999', expected an octal number, e.g. 0660",
+		"server.socket_owner: unknown user 'no-such-user'",
+	}, err.(*ValidationError).Problems)
+
+	socket := config.Properties.Server.SocketProperties
+	a.Equal([]int{1000}, socket.SocketAllowedUIDs)
+	a.True(socket.IsRestricted())
+	a.True(socket.AllowsPeer(1000, 1000))
+	a.True(socket.AllowsPeer(0, 2000))
+	a.False(socket.AllowsPeer(0, 0))
+	a.True(SocketProperties{}.AllowsPeer(0, 0))
+
+	socket.SocketMode = ""
+	mode, err := socket.FileMode()
+	a.NoError(err)
+	a.Equal(os.FileMode(0660), mode)
+
+	socket.SocketOwner = "1000"
+	uid, gid, err := socket.Ownership()
+	a.NoError(err)
+	a.Equal(1000, uid)
+	a.Equal(0, gid)
+}
+
+func TestAgentConfiguration_Authorization(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/agent.toml"
+	a.NoError(CreateFile(configPath, `[server]
+port = "9091"
+
+[[server.authorization]]
+method = "GetServiceToken"
+callers = ["sports.frontend"]
+uids = [1000]
+secret_file = "missing-secret"
+
+[[server.authorization]]
+method = "CheckAccessWithToken"
+allow_any = true
+
+[[server.authorization]]
+callers = []
+
+[log]
+path = "logs"
+level = "info"
+max_age = "720h"
+max_size = "20MB"
+filename_pattern = ".%Y-%m-%d"
+rotation_time = "24h"`))
+
+	config := new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, configPath))
+	rules := config.Properties.Server.Authorization
+	a.Len(rules, 3)
+	a.Equal(MethodRule{Method: "GetServiceToken", Callers: []string{"sports.frontend"}, UIDs: []int{1000},
+		SecretFile: "missing-secret"}, rules[0])
+	a.True(rules[1].AllowAny)
+
+	err = config.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"server.authorization[0].secret_file: file 'missing-secret' is not accessible, " +
+			"stat missing-secret: no such file or directory",
+		"server.authorization[2].method is required",
+		"server.authorization[2] must have callers, uids, gids, secret_file or allow_any",
+	}, err.(*ValidationError).Problems)
+
+	a.True(rules[0].Matches("/athenz.agent.api.command.v1.AthenzAgent/GetServiceToken"))
+	a.False(rules[0].Matches("/athenz.agent.api.command.v1.AthenzAgent/GetAccessToken"))
+	a.True(MethodRule{Method: "*"}.Matches("/athenz.agent.api.command.v1.AthenzAgentAdmin/SetLogLevel"))
+	a.True(MethodRule{Method: "/athenz.agent.api.command.v1.AthenzAgentAdmin/SetLogLevel"}.
+		Matches("/athenz.agent.api.command.v1.AthenzAgentAdmin/SetLogLevel"))
+}
+
+func TestMethodRule_MatchesService(t *testing.T) {
+	a := assert.New(t)
+	a.True(MethodRule{Method: "AthenzAgentAdmin"}.Matches("/athenz.agent.api.command.v1.AthenzAgentAdmin/ReloadCache"))
+	a.False(MethodRule{Method: "AthenzAgent"}.Matches("/athenz.agent.api.command.v1.AthenzAgentAdmin/ReloadCache"))
+}
+
+func TestAgentConfiguration_ValidateHTTPPort(t *testing.T) {
+	a := assert.New(t)
+	config := new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, filePath))
+
+	config.Properties.Server.HTTPPort = "9092"
+	a.NoError(config.Validate())
+
+	config.Properties.Server.HTTPPort = "9091"
+	err := config.Validate()
+	a.Error(err)
+	a.Equal([]string{"server.http_port must be different from server.port"}, err.(*ValidationError).Problems)
+
+	config.Properties.Server.HTTPPort = "http"
+	err = config.Validate()
+	a.Error(err)
+	a.Equal([]string{"server.http_port: invalid port 'http', expected a number between 1 and 65535"},
+		err.(*ValidationError).Problems)
+}
+
+func TestAgentConfiguration_ValidateMetricsAddress(t *testing.T) {
+	a := assert.New(t)
+	config := new(AgentConfiguration)
+	a.NoError(LoadAgentConfig(config, filePath))
+
+	config.Properties.Server.MetricsAddress = "127.0.0.1:9093"
+	a.NoError(config.Validate())
+
+	config.Properties.Server.MetricsAddress = "9093x"
+	err := config.Validate()
+	a.Error(err)
+	a.Equal([]string{"server.metrics_address: invalid address '9093x', expected host:port or port"},
+		err.(*ValidationError).Problems)
+}
+
+func TestServerProperties_MetricsListenAddress(t *testing.T) {
+	a := assert.New(t)
+	tests := []struct {
+		address  string
+		expected string
+		err      bool
+	}{
+		{address: "", expected: ""},
+		{address: "9093", expected: "127.0.0.1:9093"},
+		{address: ":9093", expected: "127.0.0.1:9093"},
+		{address: "0.0.0.0:9093", expected: "0.0.0.0:9093"},
+		{address: "[::1]:9093", expected: "[::1]:9093"},
+		{address: "localhost:", err: true},
+		{address: "localhost:0", err: true},
+		{address: "localhost", err: true},
+	}
+	for _, test := range tests {
+		address, err := ServerProperties{MetricsAddress: test.address}.MetricsListenAddress()
+		if test.err {
+			a.Error(err, test.address)
+			continue
+		}
+		a.NoError(err, test.address)
+		a.Equal(test.expected, address, test.address)
+	}
+}
@@ -0,0 +1,127 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 6:10 PM
+ *
+ * Description:
+ * keyRegistry caches parsed ZTS and ZMS public keys, so tokens and
+ * policy files are verified without decoding and parsing the PEM
+ * public key every time.
+ *
+ */
+
+package config
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/yahoo/athenz/libs/go/zmssvctoken"
+	"sync"
+)
+
+const (
+	// ZtsService is the service name of ZTS public keys in key registry
+	ZtsService = "zts"
+	// ZmsService is the service name of ZMS public keys in key registry
+	ZmsService = "zms"
+)
+
+type (
+	// keyRegistry holds ready to use verifiers keyed by service and key id.
+	keyRegistry struct {
+		mu        sync.RWMutex
+		verifiers map[registryKey]registryEntry
+	}
+
+	registryKey struct {
+		service string
+		id      string
+	}
+
+	// registryEntry keeps the encoded key next to its verifier, a cached
+	// verifier is only used if the key with the same id didn't change.
+	registryEntry struct {
+		key      string
+		verifier zmssvctoken.Verifier
+	}
+)
+
+// get returns the cached verifier of the encoded key.
+func (r *keyRegistry) get(service, id, key string) (zmssvctoken.Verifier, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.verifiers[registryKey{service: service, id: id}]
+	if !ok || entry.key != key {
+		return nil, false
+	}
+	return entry.verifier, true
+}
+
+// put caches the verifier of the encoded key.
+func (r *keyRegistry) put(service, id, key string, verifier zmssvctoken.Verifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.verifiers == nil {
+		r.verifiers = make(map[registryKey]registryEntry)
+	}
+	r.verifiers[registryKey{service: service, id: id}] = registryEntry{key: key, verifier: verifier}
+}
+
+// clear removes all cached verifiers, so the verifiers of removed keys
+// don't stay in memory.
+func (r *keyRegistry) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verifiers = nil
+}
+
+// GetZtsVerifier returns a verifier for ZTS public key with the input id.
+func (config *AthenzConfiguration) GetZtsVerifier(id string) (zmssvctoken.Verifier, error) {
+	return config.GetVerifier(ZtsService, id)
+}
+
+// GetZmsVerifier returns a verifier for ZMS public key with the input id.
+func (config *AthenzConfiguration) GetZmsVerifier(id string) (zmssvctoken.Verifier, error) {
+	return config.GetVerifier(ZmsService, id)
+}
+
+// GetVerifier returns a verifier for the public key of a service, ZtsService
+// or ZmsService, with the input id. Each key is decoded and parsed once, the
+// verifier is reused until the key changes.
+func (config *AthenzConfiguration) GetVerifier(service, id string) (zmssvctoken.Verifier, error) {
+	var key string
+	switch service {
+	case ZtsService:
+		key = config.GetZtsPublicKey(id)
+	case ZmsService:
+		key = config.GetZmsPublicKey(id)
+	default:
+		return nil, common.Errorf("unknown public key service: %s", service)
+	}
+	if key == "" {
+		return nil, common.Errorf("no %s public key with id: '%s'", service, id)
+	}
+
+	if verifier, ok := config.keys.get(service, id, key); ok {
+		return verifier, nil
+	}
+
+	pem, err := new(zmssvctoken.YBase64).DecodeString(key)
+	if err != nil {
+		return nil, common.Errorf("unable to decode %s public key with id: '%s', error: %s",
+			service, id, err.Error())
+	}
+	verifier, err := zmssvctoken.NewVerifier(pem)
+	if err != nil {
+		return nil, common.Errorf("unable to parse %s public key with id: '%s', error: %s",
+			service, id, err.Error())
+	}
+
+	config.keys.put(service, id, key, verifier)
+	return verifier, nil
+}
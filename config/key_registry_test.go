@@ -0,0 +1,190 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 6:35 PM
+ *
+ * Description:
+ *
+ */
+
+package config
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/yahoo/athenz/libs/go/zmssvctoken"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const (
+	testSignedData = "v=Z1;d=sports;r=admin;a=aAkjbbDMhnLX;t=1442191203;e=1442194803"
+)
+
+// newTestKeyPair creates a RSA key pair and returns the ybase64 encoded
+// public key and a signature of testSignedData.
+func newTestKeyPair(t testing.TB) (string, string) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDer, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDer})
+	privateKeyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	signer, err := zmssvctoken.NewSigner(privateKeyPem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature, err := signer.Sign(testSignedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return new(zmssvctoken.YBase64).EncodeToString(publicKeyPem), signature
+}
+
+// newTestKeyStore loads an athenz config with the input ZTS and ZMS key.
+func newTestKeyStore(t testing.TB, ztsKey, zmsKey string) *AthenzConfiguration {
+	dir, err := ioutil.TempDir("", testConfigDirPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configPath := dir + "/" + testAthenzConfigFile
+	content := `{"ztsUrl":"https://zts.athenz.io:4443/",
+		"ztsPublicKeys":[{"id":"0","key":"` + ztsKey + `"}],
+		"zmsPublicKeys":[{"id":"0","key":"` + zmsKey + `"}]}`
+	if err := CreateFile(configPath, content); err != nil {
+		t.Fatal(err)
+	}
+	keyStore := newAthenzConfiguration()
+	if err := LoadAthenzConfig(keyStore, configPath); err != nil {
+		t.Fatal(err)
+	}
+	return keyStore
+}
+
+func TestAthenzConfiguration_GetVerifier(t *testing.T) {
+	a := assert.New(t)
+
+	ztsKey, ztsSignature := newTestKeyPair(t)
+	keyStore := newTestKeyStore(t, ztsKey, "invalidKey")
+
+	verifier, err := keyStore.GetZtsVerifier("0")
+	a.NoError(err)
+	a.NoError(verifier.Verify(testSignedData, ztsSignature))
+
+	// the parsed key is reused
+	cached, err := keyStore.GetZtsVerifier("0")
+	a.NoError(err)
+	a.True(verifier == cached)
+
+	_, err = keyStore.GetZtsVerifier("1")
+	a.Error(err)
+	a.Contains(err.Error(), "no zts public key with id: '1'")
+
+	_, err = keyStore.GetZmsVerifier("0")
+	a.Error(err)
+	a.Contains(err.Error(), "unable to decode zms public key with id: '0'")
+
+	_, err = keyStore.GetVerifier("zpe", "0")
+	a.Error(err)
+
+	// fetched keys are parsed too
+	fetchedKey, fetchedSignature := newTestKeyPair(t)
+	keyStore.SetFetchedKeys([]PublicKeys{{Id: "1", Key: fetchedKey}}, nil)
+	verifier, err = keyStore.GetZtsVerifier("1")
+	a.NoError(err)
+	a.NoError(verifier.Verify(testSignedData, fetchedSignature))
+
+	// a changed key with the same id is parsed again
+	rotatedKey, rotatedSignature := newTestKeyPair(t)
+	keyStore.SetFetchedKeys([]PublicKeys{{Id: "1", Key: rotatedKey}}, nil)
+	verifier, err = keyStore.GetZtsVerifier("1")
+	a.NoError(err)
+	a.NoError(verifier.Verify(testSignedData, rotatedSignature))
+	a.Error(verifier.Verify(testSignedData, fetchedSignature))
+}
+
+// BenchmarkVerify_DecodeAndParse verifies a signature the way it was done
+// before key registry, decoding and parsing the public key every time.
+func BenchmarkVerify_DecodeAndParse(b *testing.B) {
+	ztsKey, signature := newTestKeyPair(b)
+	keyStore := newTestKeyStore(b, ztsKey, ztsKey)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pem, err := new(zmssvctoken.YBase64).DecodeString(keyStore.GetZtsPublicKey("0"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := common.Verify(testSignedData, signature, string(pem)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkVerify_KeyRegistry verifies a signature using the cached verifier.
+func BenchmarkVerify_KeyRegistry(b *testing.B) {
+	ztsKey, signature := newTestKeyPair(b)
+	keyStore := newTestKeyStore(b, ztsKey, ztsKey)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		verifier, err := keyStore.GetZtsVerifier("0")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := verifier.Verify(testSignedData, signature); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetVerifier_KeyRegistry measures the key lookup alone, which is the
+// cost that key registry saves on every verification.
+func BenchmarkGetVerifier_KeyRegistry(b *testing.B) {
+	ztsKey, _ := newTestKeyPair(b)
+	keyStore := newTestKeyStore(b, ztsKey, ztsKey)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := keyStore.GetZtsVerifier("0"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkGetVerifier_DecodeAndParse measures decoding and parsing a public
+// key, which was done on every verification before key registry.
+func BenchmarkGetVerifier_DecodeAndParse(b *testing.B) {
+	ztsKey, _ := newTestKeyPair(b)
+	keyStore := newTestKeyStore(b, ztsKey, ztsKey)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		pem, err := new(zmssvctoken.YBase64).DecodeString(keyStore.GetZtsPublicKey("0"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := zmssvctoken.NewVerifier(pem); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
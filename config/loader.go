@@ -17,8 +17,13 @@ package config
 import (
 	"fmt"
 	"github.com/fsnotify/fsnotify"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const (
@@ -30,6 +35,8 @@ const (
 	EnvKeyZpuConfigPath = "ZPU_CONFIG_PATH"
 	// EnvKeyAgentConfigPath the environment variable key for agent config path
 	EnvKeyAgentConfigPath = "AGENT_CONFIG_PATH"
+	// EnvKeyUnifiedConfigPath the environment variable key for unified config path
+	EnvKeyUnifiedConfigPath = "ATHENZ_AGENT_CONFIG_PATH"
 
 	// DefaultZpeConfigPath the default value ZPE config
 	DefaultZpeConfigPath = "config/zpe.conf"
@@ -39,6 +46,12 @@ const (
 	DefaultZpuConfigPath = "config/zpu.conf"
 	// DefaultAgentConfigPath the default value for agent config
 	DefaultAgentConfigPath = "config/agent.json"
+	// DefaultUnifiedConfigPath the default value for unified config
+	DefaultUnifiedConfigPath = "config/athenz-agent.toml"
+)
+
+var (
+	logger = log.GetLogger(common.GolangFileName())
 )
 
 type (
@@ -49,6 +62,14 @@ type (
 		LoadConfig(config interface{}, filePath string) error
 		// WithDefaultConfig performs default configuration to config loader
 		WithDefaultConfig()
+		// OnChange registers a function that will be called every time the
+		// config file changes at runtime
+		OnChange(fn func())
+		// Reload unmarshalls the latest config file content to config object
+		Reload(config interface{}) error
+		// UnusedKeys returns the keys of the last loaded config file that
+		// don't match any field of the config object
+		UnusedKeys() []string
 	}
 
 	// viperLoader is the implementation of Loader interface for viper.
@@ -57,6 +78,24 @@ type (
 	// field it's possible to have custom setting per config files.
 	viperLoader struct {
 		v *viper.Viper
+
+		mu        sync.Mutex
+		listeners []func()
+		unused    []string
+	}
+
+	// sectionLoader is the implementation of Loader interface for a part of
+	// a unified config file. The parent loader reads and watches the file,
+	// sectionLoader only decodes its own keys.
+	sectionLoader struct {
+		parent *viperLoader
+		// section is the top level key that sectionLoader decodes. If it's
+		// empty, all top level keys except ignored ones are decoded.
+		section string
+		ignored []string
+
+		mu     sync.Mutex
+		unused []string
 	}
 )
 
@@ -78,10 +117,28 @@ func (c *viperLoader) LoadConfig(config interface{}, filePath string) error {
 	}
 
 	// unmarshall config to the input interface
-	if err := c.v.Unmarshal(config); err != nil {
+	return c.unmarshal(config)
+}
+
+// unmarshal decodes the loaded config into config object and keeps the
+// keys that were not decoded.
+func (c *viperLoader) unmarshal(config interface{}) error {
+	metadata := new(mapstructure.Metadata)
+	if err := c.v.Unmarshal(config, func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = metadata
+	}); err != nil {
 		return err
 	}
 
+	// viper keys are case insensitive, but mapstructure uses field names
+	// for nested keys
+	for i, key := range metadata.Unused {
+		metadata.Unused[i] = strings.ToLower(key)
+	}
+	sort.Strings(metadata.Unused)
+	c.mu.Lock()
+	c.unused = metadata.Unused
+	c.mu.Unlock()
 	return nil
 }
 
@@ -93,7 +150,7 @@ func (c *viperLoader) WithDefaultConfig() {
 
 	// load new config changes at runtime and notifies it
 	c.v.WatchConfig()
-	c.v.OnConfigChange(notify)
+	c.v.OnConfigChange(c.notifyAll)
 
 	// enable environment variable detection
 	c.v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
@@ -101,6 +158,116 @@ func (c *viperLoader) WithDefaultConfig() {
 	c.v.AutomaticEnv()
 }
 
+// OnChange registers a function that will be called every time the config
+// file changes at runtime.
+func (c *viperLoader) OnChange(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Reload unmarshalls the latest config file content to config object.
+func (c *viperLoader) Reload(config interface{}) error {
+	return c.unmarshal(config)
+}
+
+// UnusedKeys returns the keys of the last loaded config file that don't
+// match any field of the config object.
+func (c *viperLoader) UnusedKeys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unused
+}
+
+// newSectionLoader creates new instance of sectionLoader that decodes the
+// section key of the parent config file. If section is empty, the root of
+// config file except the ignored keys is decoded.
+func newSectionLoader(parent *viperLoader, section string, ignored ...string) Loader {
+	return &sectionLoader{parent: parent, section: section, ignored: ignored}
+}
+
+// LoadConfig decodes the section into config object. The config file must
+// be already loaded by the parent loader, so filePath is not used.
+func (s *sectionLoader) LoadConfig(config interface{}, filePath string) error {
+	return s.Reload(config)
+}
+
+// WithDefaultConfig does nothing, the parent loader watches the config file.
+func (s *sectionLoader) WithDefaultConfig() {
+}
+
+// OnChange registers a function that will be called every time the config
+// file changes at runtime.
+func (s *sectionLoader) OnChange(fn func()) {
+	s.parent.OnChange(fn)
+}
+
+// Reload unmarshalls the latest section content to config object.
+func (s *sectionLoader) Reload(config interface{}) error {
+	settings := s.parent.v.AllSettings()
+	var input interface{}
+	if s.section != "" {
+		input = settings[s.section]
+	} else {
+		for _, key := range s.ignored {
+			delete(settings, key)
+		}
+		input = settings
+	}
+
+	metadata := new(mapstructure.Metadata)
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Metadata:         metadata,
+		Result:           config,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(input); err != nil {
+		return err
+	}
+
+	prefix := ""
+	if s.section != "" {
+		prefix = s.section + "."
+	}
+	for i, key := range metadata.Unused {
+		metadata.Unused[i] = prefix + strings.ToLower(key)
+	}
+	sort.Strings(metadata.Unused)
+	s.mu.Lock()
+	s.unused = metadata.Unused
+	s.mu.Unlock()
+	return nil
+}
+
+// UnusedKeys returns the keys of the section that don't match any field of
+// the config object.
+func (s *sectionLoader) UnusedKeys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unused
+}
+
+// notifyAll notifies the new event to all registered listeners.
+func (c *viperLoader) notifyAll(e fsnotify.Event) {
+	notify(e)
+
+	c.mu.Lock()
+	listeners := make([]func(), len(c.listeners))
+	copy(listeners, c.listeners)
+	c.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
 // notify notifies the new event
 func notify(e fsnotify.Event) {
 	fmt.Printf("Config file changed: %s\n", e.Name)
@@ -0,0 +1,69 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 11:20 AM
+ *
+ * Description:
+ * publisher keeps the subscribers of a configuration and notifies
+ * them after the configuration was reloaded at runtime.
+ *
+ */
+
+package config
+
+import "sync"
+
+type (
+	// publisher notifies subscribers about configuration changes. Embed it
+	// in a configuration type to expose OnChange.
+	publisher struct {
+		mu          sync.Mutex
+		subscribers []*subscriber
+	}
+
+	// subscriber wraps a function, so it can be found on unsubscribe.
+	subscriber struct {
+		fn func()
+	}
+)
+
+// OnChange registers a function that will be called every time the
+// configuration is reloaded successfully at runtime. The returned function
+// unregisters it.
+func (p *publisher) OnChange(fn func()) func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := &subscriber{fn: fn}
+	p.subscribers = append(p.subscribers, s)
+	return func() { p.unsubscribe(s) }
+}
+
+// unsubscribe removes the subscriber, it's a no-op if it was removed before.
+func (p *publisher) unsubscribe(s *subscriber) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, subscriber := range p.subscribers {
+		if subscriber == s {
+			p.subscribers = append(p.subscribers[:i:i], p.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// publish calls all subscribers one by one.
+func (p *publisher) publish() {
+	p.mu.Lock()
+	subscribers := make([]*subscriber, len(p.subscribers))
+	copy(subscribers, p.subscribers)
+	p.mu.Unlock()
+
+	for _, s := range subscribers {
+		s.fn()
+	}
+}
@@ -0,0 +1,38 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 10:20 PM
+ *
+ * Description:
+ *
+ */
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPublisher_Unsubscribe(t *testing.T) {
+	a := assert.New(t)
+	p := new(publisher)
+
+	var calls []string
+	unsubscribeFirst := p.OnChange(func() { calls = append(calls, "first") })
+	p.OnChange(func() { calls = append(calls, "second") })
+	p.publish()
+	a.Equal([]string{"first", "second"}, calls)
+
+	calls = nil
+	unsubscribeFirst()
+	unsubscribeFirst()
+	p.publish()
+	a.Equal([]string{"second"}, calls)
+}
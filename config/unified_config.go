@@ -0,0 +1,305 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 3:10 PM
+ *
+ * Description:
+ * Unified config is an optional single config file that replaces the
+ * agent, ZPE, ZPU and athenz config files. Each section uses the keys
+ * of the legacy file it replaces:
+ *
+ *   [server], [log]  keys of agent config
+ *   [zpe]            keys of ZPE config
+ *   [zpu]            keys of ZPU config, except policyDir
+ *   [athenz]         keys of athenz config
+ *
+ * The policy directory is only set once by zpe.policy_files_dir.
+ *
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/pelletier/go-toml"
+	"github.com/spf13/viper"
+	"github.com/yahoo/athenz/libs/go/zmssvctoken"
+	"github.com/yahoo/athenz/utils/zpe-updater"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	sectionZpe    = "zpe"
+	sectionZpu    = "zpu"
+	sectionAthenz = "athenz"
+
+	// zpuPolicyDirKey is the ZPU config key that is replaced by
+	// zpe.policy_files_dir in unified config
+	zpuPolicyDirKey = "policyDir"
+)
+
+type (
+	// zpuProperties represents zpu section of unified config.
+	zpuProperties struct {
+		Domains        string `mapstructure:"domains"`
+		User           string `mapstructure:"user"`
+		TempPolicyDir  string `mapstructure:"tempPolicyDir"`
+		MetricsDir     string `mapstructure:"metricsDir"`
+		LogMaxSize     int    `mapstructure:"logMaxsize"`
+		LogMaxAge      int    `mapstructure:"logMaxage"`
+		LogMaxBackups  int    `mapstructure:"logMaxbackups"`
+		LogCompress    bool   `mapstructure:"logCompress"`
+		PrivateKeyFile string `mapstructure:"privateKeyFile"`
+		CertFile       string `mapstructure:"certFile"`
+		CaCertFile     string `mapstructure:"caCertFile"`
+		Proxy          bool   `mapstructure:"proxy"`
+	}
+)
+
+// LoadGlobalUnifiedConfig loads unified config file from input path into
+// the global variables AgentConfig, ZpeConfig, KeyStore and ZpuConfig.
+func LoadGlobalUnifiedConfig(filePath string) error {
+	return LoadUnifiedConfig(AgentConfig, ZpeConfig, KeyStore, ZpuConfig, filePath)
+}
+
+// LoadUnifiedConfig reads a unified config file and loads each section into
+// its configuration object. The file is watched once and all sections are
+// reloaded when it changes.
+func LoadUnifiedConfig(agentConfig *AgentConfiguration, zpeConfig *ZpeConfiguration,
+	athenzConfig *AthenzConfiguration, zpuConfig *ZpuConfiguration, filePath string) error {
+
+	root := &viperLoader{v: viper.New()}
+	if err := root.LoadConfig(&map[string]interface{}{}, filePath); err != nil {
+		return common.Errorf("unable to load config from %s : %s", filePath, err.Error())
+	}
+
+	// agent properties are at the root of unified config
+	agentProps := new(agentProperties)
+	agentConfig.loader = newSectionLoader(root, "", sectionZpe, sectionZpu, sectionAthenz)
+	if err := agentConfig.loader.LoadConfig(agentProps, filePath); err != nil {
+		return common.Errorf("unable to load agent properties from %s : %s", filePath, err.Error())
+	}
+	agentConfig.set(agentProps)
+
+	zpeProps := new(zpeProperties)
+	zpeConfig.loader = newSectionLoader(root, sectionZpe)
+	if err := zpeConfig.loader.LoadConfig(zpeProps, filePath); err != nil {
+		return common.Errorf("unable to load zpe section from %s : %s", filePath, err.Error())
+	}
+	zpeConfig.set(zpeProps)
+
+	athenzProps := new(athenzProperties)
+	athenzConfig.loader = newSectionLoader(root, sectionAthenz)
+	if err := athenzConfig.loader.LoadConfig(athenzProps, filePath); err != nil {
+		return common.Errorf("unable to load athenz section from %s : %s", filePath, err.Error())
+	}
+	athenzConfig.set(athenzProps)
+
+	// ZPU properties are built from zpu section, ZPE policy directory and
+	// athenz urls and keys
+	zpuConfig.loader = newSectionLoader(root, sectionZpu)
+	zpuConfig.load = func() (*zpu.ZpuConfiguration, error) {
+		properties := new(zpuProperties)
+		if err := zpuConfig.loader.Reload(properties); err != nil {
+			return nil, err
+		}
+		return properties.zpuConfiguration(zpeConfig.Get().PolicyFilesDir, athenzConfig.Get())
+	}
+	zpuConfig.unknownKeys = func() ([]string, error) {
+		return zpuConfig.loader.UnusedKeys(), nil
+	}
+	properties, err := zpuConfig.load()
+	if err != nil {
+		return common.Errorf("unable to load zpu section from %s : %s", filePath, err.Error())
+	}
+	zpuConfig.set(properties)
+
+	// the order matters, ZPU reload uses the reloaded ZPE and athenz properties
+	root.WithDefaultConfig()
+	root.OnChange(agentConfig.reload)
+	root.OnChange(zpeConfig.reload)
+	root.OnChange(athenzConfig.reload)
+	root.OnChange(zpuConfig.Reload)
+
+	return nil
+}
+
+// zpuConfiguration creates Yahoo ZPU configuration the same way
+// zpu.NewZpuConfiguration does for legacy config files.
+func (p *zpuProperties) zpuConfiguration(policyDir string,
+	athenz *athenzProperties) (*zpu.ZpuConfiguration, error) {
+
+	ztsKeys, err := decodePublicKeys("zts", athenz.ZtsPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	zmsKeys, err := decodePublicKeys("zms", athenz.ZmsPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zpu.ZpuConfiguration{
+		Zts:               athenz.ZtsUrl,
+		Zms:               athenz.ZmsUrl,
+		DomainList:        p.Domains,
+		ZpuOwner:          orDefault(p.User, "root"),
+		PolicyFileDir:     orDefault(policyDir, "./var/zpe"),
+		TempPolicyFileDir: orDefault(p.TempPolicyDir, "./tmp/zpe"),
+		MetricsDir:        orDefault(p.MetricsDir, "./var/zpe_stat"),
+		ZtsKeysmap:        ztsKeys,
+		ZmsKeysmap:        zmsKeys,
+		StartUpDelay:      zpu.DEFAULT_STARTUP_DELAY,
+		ExpiryCheck:       zpu.DEFAULT_EXPIRY_CHECK * 60,
+		LogAge:            p.LogMaxAge,
+		LogSize:           p.LogMaxSize,
+		LogBackups:        p.LogMaxBackups,
+		LogCompression:    p.LogCompress,
+		PrivateKeyFile:    p.PrivateKeyFile,
+		CertFile:          p.CertFile,
+		CaCertFile:        p.CaCertFile,
+		Proxy:             p.Proxy,
+	}, nil
+}
+
+// decodePublicKeys decodes ybase64 public keys into a map of key id to PEM.
+func decodePublicKeys(service string, publicKeys []PublicKeys) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, publicKey := range publicKeys {
+		key, err := new(zmssvctoken.YBase64).DecodeString(publicKey.Key)
+		if err != nil {
+			return nil, common.Errorf("unable to decode %s public key with id: %s, error: %s",
+				service, publicKey.Id, err.Error())
+		}
+		keys[publicKey.Id] = string(key)
+	}
+	return keys, nil
+}
+
+// orDefault returns value if it's not empty, otherwise defaultValue.
+func orDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// ConvertLegacyConfig reads legacy agent, ZPE, athenz and ZPU config files
+// and writes them into one unified config file. The output is JSON if
+// outPath has .json extension, otherwise it's TOML. ConvertLegacyConfig
+// never overwrites an existing file.
+func ConvertLegacyConfig(agentConfPath, zpeConfPath, athenzConfPath, zpuConfPath, outPath string) error {
+	if _, err := os.Stat(outPath); err == nil {
+		return common.Errorf("%s already exists", outPath)
+	}
+
+	agent, err := readViperFile(agentConfPath)
+	if err != nil {
+		return err
+	}
+	zpe, err := readViperFile(zpeConfPath)
+	if err != nil {
+		return err
+	}
+	// zpe-updater reads athenz and ZPU configs as JSON regardless of the
+	// file extension, encoding/json also keeps the camel case keys
+	athenz, err := readJSONFile(athenzConfPath)
+	if err != nil {
+		return err
+	}
+	zpuConf, err := readJSONFile(zpuConfPath)
+	if err != nil {
+		return err
+	}
+
+	// policy directory is set once by zpe.policy_files_dir
+	if policyDir, ok := zpuConf[zpuPolicyDirKey].(string); ok && policyDir != "" &&
+		filepath.Clean(policyDir) != filepath.Clean(fmt.Sprint(zpe["policy_files_dir"])) {
+		return common.Errorf("%s of zpu config '%s' differs from policy_files_dir of zpe config '%v'",
+			zpuPolicyDirKey, policyDir, zpe["policy_files_dir"])
+	}
+	delete(zpuConf, zpuPolicyDirKey)
+
+	unified := make(map[string]interface{})
+	for key, value := range agent {
+		// config section points to the legacy config files
+		if key != "config" {
+			unified[key] = value
+		}
+	}
+	unified[sectionZpe] = zpe
+	unified[sectionZpu] = zpuConf
+	unified[sectionAthenz] = athenz
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(outPath), ".json") {
+		if data, err = json.MarshalIndent(unified, "", "  "); err != nil {
+			return common.Errorf("unable to encode unified config, error: %s", err.Error())
+		}
+	} else {
+		tree, err := toml.TreeFromMap(normalizeNumbers(unified).(map[string]interface{}))
+		if err != nil {
+			return common.Errorf("unable to encode unified config, error: %s", err.Error())
+		}
+		data = []byte(tree.String())
+	}
+
+	if err := ioutil.WriteFile(outPath, data, 0644); err != nil {
+		return common.Errorf("unable to write %s, error: %s", outPath, err.Error())
+	}
+	return nil
+}
+
+// readViperFile reads a config file that viper supports into a map.
+func readViperFile(filePath string) (map[string]interface{}, error) {
+	v := viper.New()
+	v.SetConfigFile(filePath)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, common.Errorf("unable to read %s, error: %s", filePath, err.Error())
+	}
+	return v.AllSettings(), nil
+}
+
+// readJSONFile reads a JSON object file into a map.
+func readJSONFile(filePath string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, common.Errorf("unable to read %s, error: %s", filePath, err.Error())
+	}
+	content := make(map[string]interface{})
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, common.Errorf("unable to parse %s, error: %s", filePath, err.Error())
+	}
+	return content, nil
+}
+
+// normalizeNumbers converts whole float64 numbers of decoded JSON to int64,
+// so they are written as TOML integers.
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v)
+		}
+	}
+	return value
+}
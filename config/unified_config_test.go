@@ -0,0 +1,149 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 3:55 PM
+ *
+ * Description:
+ *
+ */
+
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"testing"
+)
+
+const (
+	legacyConfigDir = "../build/config/"
+)
+
+func TestLoadUnifiedConfig(t *testing.T) {
+	a := assert.New(t)
+
+	agentConfig := newAgentConfiguration()
+	zpeConfig := newZpeConfiguration()
+	athenzConfig := newAthenzConfiguration()
+	zpuConfig := new(ZpuConfiguration)
+	a.NoError(LoadUnifiedConfig(agentConfig, zpeConfig, athenzConfig, zpuConfig, "testdata/athenz-agent.toml"))
+
+	a.Equal("sidecar-agent", agentConfig.Get().Server.Name)
+	a.Equal("9091", agentConfig.Get().Server.Port)
+	a.Equal("debug", agentConfig.Get().Log.Level)
+	a.Equal("var/policy", zpeConfig.Get().PolicyFilesDir)
+	a.Equal(int64(600), zpeConfig.Get().ZpuDownloadInterval)
+	a.Equal([]TokenAllowRule{{Caller: "sports.frontend", Domain: "sports", Roles: []string{"reader"}}},
+		zpeConfig.Get().TokenAllowList)
+	a.Equal("https://zts.athenz.io:4443/", athenzConfig.Get().ZtsUrl)
+	a.Equal(testPublicKey, athenzConfig.GetZtsPublicKey("0"))
+
+	zpuProperties := zpuConfig.Get()
+	a.Equal("mydm", zpuProperties.DomainList)
+	a.Equal("var/policy", zpuProperties.PolicyFileDir)
+	a.Equal("var/metric", zpuProperties.MetricsDir)
+	a.Equal("./tmp/zpe", zpuProperties.TempPolicyFileDir)
+	a.Equal("https://zts.athenz.io:4443/", zpuProperties.Zts)
+	a.Equal(100, zpuProperties.LogSize)
+	a.True(zpuProperties.LogCompression)
+	a.Len(zpuProperties.ZtsKeysmap, 1)
+	a.Len(zpuProperties.ZmsKeysmap, 1)
+
+	a.NoError(agentConfig.Validate())
+	a.NoError(zpeConfig.Validate())
+	a.NoError(athenzConfig.Validate())
+	a.NoError(zpuConfig.Validate())
+}
+
+func TestLoadUnifiedConfig_UnknownKeys(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/athenz-agent.toml"
+	a.NoError(CreateFile(configPath, `
+[server]
+port = "9091"
+[logs]
+level = "info"
+[zpe]
+policy_file_dir = "var/policy"
+[zpu]
+domain = "mydm"
+`))
+
+	agentConfig := newAgentConfiguration()
+	zpeConfig := newZpeConfiguration()
+	athenzConfig := newAthenzConfiguration()
+	zpuConfig := new(ZpuConfiguration)
+	a.NoError(LoadUnifiedConfig(agentConfig, zpeConfig, athenzConfig, zpuConfig, configPath))
+
+	a.Equal([]string{"logs"}, agentConfig.loader.UnusedKeys())
+	a.Equal([]string{"zpe.policy_file_dir"}, zpeConfig.loader.UnusedKeys())
+	a.Empty(athenzConfig.loader.UnusedKeys())
+	a.Equal([]string{"zpu.domain"}, zpuConfig.loader.UnusedKeys())
+}
+
+func TestConvertLegacyConfig(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	legacyZpe := newZpeConfiguration()
+	a.NoError(LoadZpeConfig(legacyZpe, legacyConfigDir+"zpe.toml"))
+	legacyAthenz := newAthenzConfiguration()
+	a.NoError(LoadAthenzConfig(legacyAthenz, legacyConfigDir+"athenz.json"))
+	legacyZpu := new(ZpuConfiguration)
+	a.NoError(LoadZpuConfig(legacyZpu, legacyConfigDir+"athenz.json", legacyConfigDir+"zpu.json"))
+
+	for _, outPath := range []string{dir + "/athenz-agent.toml", dir + "/athenz-agent.json"} {
+		a.NoError(ConvertLegacyConfig(legacyConfigDir+"agent.toml", legacyConfigDir+"zpe.toml",
+			legacyConfigDir+"athenz.json", legacyConfigDir+"zpu.json", outPath))
+
+		agentConfig := newAgentConfiguration()
+		zpeConfig := newZpeConfiguration()
+		athenzConfig := newAthenzConfiguration()
+		zpuConfig := new(ZpuConfiguration)
+		a.NoError(LoadUnifiedConfig(agentConfig, zpeConfig, athenzConfig, zpuConfig, outPath))
+
+		a.Equal("sidecar-agent", agentConfig.Get().Server.Name)
+		a.Equal("20MB", agentConfig.Get().Log.MaxSize)
+		a.Empty(agentConfig.loader.UnusedKeys())
+		a.Equal(*legacyZpe.Get(), *zpeConfig.Get())
+		a.Empty(zpeConfig.loader.UnusedKeys())
+		a.Equal(legacyAthenz.Get(), athenzConfig.Get())
+		a.Empty(athenzConfig.loader.UnusedKeys())
+		a.Equal(legacyZpu.Get(), zpuConfig.Get())
+		a.Empty(zpuConfig.loader.UnusedKeys())
+
+		// existing files are never overwritten
+		a.Error(ConvertLegacyConfig(legacyConfigDir+"agent.toml", legacyConfigDir+"zpe.toml",
+			legacyConfigDir+"athenz.json", legacyConfigDir+"zpu.json", outPath))
+	}
+}
+
+func TestConvertLegacyConfig_PolicyDirMismatch(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	zpuPath := dir + "/zpu.json"
+	a.NoError(CreateFile(zpuPath, `{"domains": "mydm", "policyDir": "/var/zpe"}`))
+
+	err = ConvertLegacyConfig(legacyConfigDir+"agent.toml", legacyConfigDir+"zpe.toml",
+		legacyConfigDir+"athenz.json", zpuPath, dir+"/athenz-agent.toml")
+	a.Error(err)
+	a.Contains(err.Error(), "differs from policy_files_dir")
+}
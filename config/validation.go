@@ -0,0 +1,125 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 1:05 PM
+ *
+ * Description:
+ * ValidationError collects all problems of a configuration, so
+ * operators can fix a config file in one go instead of discovering
+ * problems one by one at runtime.
+ *
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	// ValidationError is the error type that Validate methods return. It
+	// contains all problems of a configuration.
+	ValidationError struct {
+		// Source is the name of the validated configuration.
+		Source string
+		// Problems is the list of human readable configuration problems.
+		Problems []string
+	}
+)
+
+// newValidationError creates new instance of ValidationError for a source.
+func newValidationError(source string) *ValidationError {
+	return &ValidationError{Source: source}
+}
+
+// Error returns all problems, one per line.
+func (e *ValidationError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("invalid %s config, %d problem(s):", e.Source, len(e.Problems)))
+	for _, problem := range e.Problems {
+		builder.WriteString("\n  - ")
+		builder.WriteString(problem)
+	}
+	return builder.String()
+}
+
+// addf adds a new problem to the list.
+func (e *ValidationError) addf(format string, params ...interface{}) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, params...))
+}
+
+// unknownKeys adds a problem for each unknown key.
+func (e *ValidationError) unknownKeys(keys []string) {
+	for _, key := range keys {
+		e.addf("unknown key '%s'", key)
+	}
+}
+
+// fileExists adds a problem if the path is set but the file doesn't exist.
+func (e *ValidationError) fileExists(key, path string) {
+	if path == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		e.addf("%s: file '%s' is not accessible, %s", key, path, err.Error())
+		return
+	}
+	if info.IsDir() {
+		e.addf("%s: '%s' is a directory, expected a file", key, path)
+	}
+}
+
+// orNil returns nil if there's no problem, so callers can return it as error.
+func (e *ValidationError) orNil() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// unknownJSONKeys returns the top level keys of a JSON file that don't
+// match any json tag of the input struct.
+func unknownJSONKeys(filePath string, v interface{}) ([]string, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string]interface{})
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, common.Errorf("unable to parse %s, error: %s", filePath, err.Error())
+	}
+
+	known := make(map[string]bool)
+	t := reflect.TypeOf(v)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = t.Field(i).Name
+		}
+		known[strings.ToLower(name)] = true
+	}
+
+	unknown := make([]string, 0)
+	for key := range content {
+		if !known[strings.ToLower(key)] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown, nil
+}
@@ -24,6 +24,36 @@ package config
 import (
 	"fmt"
 	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/mapping"
+	"github.com/yahoo/athenz/libs/go/zmssvctoken"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultTokenHeader is the role token header of ext_authz requests,
+	// Envoy sends lower case header names
+	defaultTokenHeader = "athenz-role-auth"
+	// defaultDynamicDomainRetry is the time that a failed dynamic domain
+	// isn't downloaded again
+	defaultDynamicDomainRetry = time.Minute
+	// defaultMaxDynamicDomains limits the domains that role tokens can
+	// subscribe to
+	defaultMaxDynamicDomains = 100
+
+	// StalePolicyStrict denies the access when the policies of the domain
+	// are expired
+	StalePolicyStrict = "strict"
+	// StalePolicyGrace evaluates the expired policies of the domain until
+	// the grace period ends
+	StalePolicyGrace = "grace"
+	// StalePolicyFailOpen allows the access when the policies of the domain
+	// are expired
+	StalePolicyFailOpen = "fail_open"
 )
 
 var (
@@ -42,6 +72,20 @@ type (
 	AthenzConfiguration struct {
 		loader     Loader
 		Properties *athenzProperties
+		// fetched holds the public keys that were fetched from ZTS at runtime
+		fetched *fetchedKeys
+		// keys caches parsed public keys
+		keys keyRegistry
+
+		lock sync.RWMutex
+		publisher
+	}
+
+	// fetchedKeys is the set of ZTS and ZMS public keys that a key provider
+	// fetched from ZTS.
+	fetchedKeys struct {
+		ZtsPublicKeys []PublicKeys `json:"ztsPublicKeys"`
+		ZmsPublicKeys []PublicKeys `json:"zmsPublicKeys"`
 	}
 
 	// ZpeConfiguration holds ZPE's properties. It uses a Loader to load
@@ -49,6 +93,9 @@ type (
 	ZpeConfiguration struct {
 		loader     Loader
 		Properties *zpeProperties
+
+		lock sync.RWMutex
+		publisher
 	}
 
 	zpeProperties struct {
@@ -81,11 +128,106 @@ type (
 		NTokenExpiration int64 `mapstructure:"ntoken_expiration"`
 		// in seconds format
 		ZpuDownloadInterval int64 `mapstructure:"zpu_download_interval"`
+		// in seconds format, 0 disables fetching public keys from ZTS
+		PublicKeysRefreshInterval int64 `mapstructure:"public_keys_refresh_interval"`
+		// fetched public keys are cached in this file to be available when ZTS is down
+		PublicKeysCacheFile string `mapstructure:"public_keys_cache_file"`
+		// verified policies and fetched public keys are saved in this file and
+		// loaded at startup before policy files, empty disables snapshots
+		PolicySnapshotFile string `mapstructure:"policy_snapshot_file"`
+		// the fraction of role token lifetime that triggers the refresh of a
+		// cached token, 0 means the default value
+		TokenRefreshFraction float64 `mapstructure:"token_refresh_fraction"`
+		// callers that may request role tokens of other domains, roles or
+		// proxy principals than the configured ones
+		TokenAllowList []TokenAllowRule `mapstructure:"token_allow_list"`
+		// provider service that attests this instance, the agent registers the
+		// instance and refreshes service identity certificate of domain_name
+		// and service_name into cert_file_path and key_file_path if it's set
+		SiaProvider string `mapstructure:"sia_provider"`
+		// instance id that is used in attestation data and certificate SAN
+		SiaInstanceID string `mapstructure:"sia_instance_id"`
+		// attestation data of the provider, it's read on each registration
+		SiaAttestationDataFile string `mapstructure:"sia_attestation_data_file"`
+		// DNS suffix of the provider, it's used in certificate SAN
+		SiaDNSSuffix string `mapstructure:"sia_dns_suffix"`
+		// the certificate signer is written in this file, if it's set
+		SiaCACertFile string `mapstructure:"sia_ca_cert_file"`
+		// in minutes format, 0 uses ZTS default
+		SiaCertExpiryTime int32 `mapstructure:"sia_cert_expiry_time"`
+		// ordered rules that map HTTP requests to action and resource, they
+		// are used by CheckHTTPAccessWithToken and Envoy ext_authz Check API
+		HTTPMappings []mapping.Rule `mapstructure:"http_mappings"`
+		// Envoy ext_authz Check API properties
+		ExtAuthz ExtAuthzProperties `mapstructure:"ext_authz"`
+		// on demand policy download of the domains that are not in the domain
+		// list of ZPU config
+		DynamicDomains DynamicDomainsProperties `mapstructure:"dynamic_domains"`
+		// ordered rules of the behavior of access checks when the policies of
+		// a domain are expired, domains without a rule are strict
+		StalePolicies []StalePolicyRule `mapstructure:"stale_policies"`
+	}
+
+	// ExtAuthzProperties holds the properties of Envoy ext_authz Check API.
+	ExtAuthzProperties struct {
+		// header of the role token, default is athenz-role-auth. Authorization
+		// header is used if the request doesn't have it
+		TokenHeader string `mapstructure:"token_header"`
+	}
+
+	// DynamicDomainsProperties holds the properties of on demand domain
+	// subscription. The first access check of an unknown domain that matches
+	// an allow pattern downloads its policy file, and the domain is added to
+	// the domains that ZPU downloads.
+	DynamicDomainsProperties struct {
+		// domain name patterns, * matches any characters, e.g. "sports.*".
+		// Empty disables on demand subscription
+		Allow []string `mapstructure:"allow"`
+		// in milliseconds format, the max time that the first access check
+		// of a domain waits for its policies, 0 returns DENY_DOMAIN_LOADING
+		// without waiting
+		Wait int64 `mapstructure:"wait"`
+		// in seconds format, the time that a failed domain isn't downloaded
+		// again, 0 means the default value
+		RetryInterval int64 `mapstructure:"retry_interval"`
+		// max number of subscribed domains, 0 means the default value
+		MaxDomains int `mapstructure:"max_domains"`
+		// file of the subscribed domains, they are subscribed again on
+		// restart. Empty doesn't keep them
+		SubscriptionsFile string `mapstructure:"subscriptions_file"`
+	}
+
+	// StalePolicyRule is the stale policy mode of the domains that match it.
+	StalePolicyRule struct {
+		// domain name pattern, * matches any characters, e.g. "sports.*"
+		Domain string `mapstructure:"domain"`
+		// strict, grace or fail_open
+		Mode string `mapstructure:"mode"`
+		// in hours format, the time that expired policies are still
+		// evaluated in grace mode
+		GracePeriod int64 `mapstructure:"grace_period"`
+	}
+
+	// TokenAllowRule allows a caller to request role tokens of a domain.
+	TokenAllowRule struct {
+		// common name of the caller client certificate, "*" matches any caller
+		// with a certificate
+		Caller string `mapstructure:"caller"`
+		Domain string `mapstructure:"domain"`
+		// allowed role names, "*" allows any role and requests without roles
+		Roles []string `mapstructure:"roles"`
+		// allowed proxy for principals, "*" allows any principal
+		ProxyForPrincipals []string `mapstructure:"proxy_for_principals"`
+		// allowed services of access token id tokens, "*" allows any service
+		IDTokenServices []string `mapstructure:"id_token_services"`
+		// in minutes format, it limits min and max expiry times of the tokens,
+		// 0 means no limit
+		MaxExpiryTime int32 `mapstructure:"max_expiry_time"`
 	}
 
 	PublicKeys struct {
-		Id  string
-		Key string
+		Id  string `json:"id"`
+		Key string `json:"key"`
 	}
 
 	athenzProperties struct {
@@ -99,7 +241,7 @@ type (
 // newAthenzConfiguration creates a new instance of AthenzConfiguration with
 // an empty properties to prevent nil pointer exception.
 func newAthenzConfiguration() *AthenzConfiguration {
-	return &AthenzConfiguration{Properties: new(athenzProperties)}
+	return &AthenzConfiguration{Properties: new(athenzProperties), fetched: new(fetchedKeys)}
 }
 
 // LoadGlobalAthenzConfig loads config file from input path into the global
@@ -113,36 +255,181 @@ func LoadGlobalAthenzConfig(filePath string) error {
 func LoadAthenzConfig(athenzConfig *AthenzConfiguration, filePath string) error {
 
 	// load config properties into athenzProperties
-	athenzConfig.Properties = new(athenzProperties)
+	properties := new(athenzProperties)
 	athenzConfig.loader = NewConfigLoader()
-	if err := athenzConfig.loader.LoadConfig(athenzConfig.Properties, filePath); err != nil {
+	if err := athenzConfig.loader.LoadConfig(properties, filePath); err != nil {
 		return common.Errorf("unable to load config from %s : %s", filePath, err.Error())
 	}
+	athenzConfig.set(properties)
 
 	// use default configuration for config loader
 	athenzConfig.loader.WithDefaultConfig()
+	athenzConfig.loader.OnChange(athenzConfig.reload)
 
 	return nil
 }
 
-// GetZtsPublicKey return ZTS public key for a specific input id.
-func (config AthenzConfiguration) GetZtsPublicKey(id string) string {
-	for _, ztsPublicKeys := range config.Properties.ZtsPublicKeys {
-		if ztsPublicKeys.Id == id {
-			return ztsPublicKeys.Key
+// Get returns the current athenz properties. Use Get instead of Properties
+// field when the config file may be reloaded at runtime.
+func (config *AthenzConfiguration) Get() *athenzProperties {
+	config.lock.RLock()
+	defer config.lock.RUnlock()
+	return config.Properties
+}
+
+// set swaps the current athenz properties.
+func (config *AthenzConfiguration) set(properties *athenzProperties) {
+	config.lock.Lock()
+	defer config.lock.Unlock()
+	config.Properties = properties
+}
+
+// GetZtsPublicKey return ZTS public key for a specific input id. The keys of
+// config file take precedence over the keys that were fetched from ZTS.
+func (config *AthenzConfiguration) GetZtsPublicKey(id string) string {
+	if key := findPublicKey(config.Get().ZtsPublicKeys, id); key != "" {
+		return key
+	}
+	return findPublicKey(config.getFetchedKeys().ZtsPublicKeys, id)
+}
+
+// GetZmsPublicKey return ZMS public key for a specific input id. The keys of
+// config file take precedence over the keys that were fetched from ZTS.
+func (config *AthenzConfiguration) GetZmsPublicKey(id string) string {
+	if key := findPublicKey(config.Get().ZmsPublicKeys, id); key != "" {
+		return key
+	}
+	return findPublicKey(config.getFetchedKeys().ZmsPublicKeys, id)
+}
+
+// findPublicKey returns the key with the input id, or empty string if there
+// is no such key.
+func findPublicKey(publicKeys []PublicKeys, id string) string {
+	for _, publicKey := range publicKeys {
+		if publicKey.Id == id {
+			return publicKey.Key
 		}
 	}
 	return ""
 }
 
-// GetZmsPublicKey return ZMS public key for a specific input id.
-func (config AthenzConfiguration) GetZmsPublicKey(id string) string {
-	for _, zmsPublicKey := range config.Properties.ZmsPublicKeys {
-		if zmsPublicKey.Id == id {
-			return zmsPublicKey.Key
+// SetFetchedKeys replaces the public keys that were fetched from ZTS. It
+// notifies the subscribers if the fetched keys changed.
+func (config *AthenzConfiguration) SetFetchedKeys(ztsPublicKeys, zmsPublicKeys []PublicKeys) {
+	keys := &fetchedKeys{ZtsPublicKeys: ztsPublicKeys, ZmsPublicKeys: zmsPublicKeys}
+
+	config.lock.Lock()
+	if reflect.DeepEqual(keys, config.fetched) {
+		config.lock.Unlock()
+		return
+	}
+	config.fetched = keys
+	config.lock.Unlock()
+	config.keys.clear()
+
+	logger.Info(fmt.Sprintf("fetched public keys changed, zts keys: %d, zms keys: %d",
+		len(ztsPublicKeys), len(zmsPublicKeys)))
+	config.publish()
+}
+
+// getFetchedKeys returns the public keys that were fetched from ZTS.
+func (config *AthenzConfiguration) getFetchedKeys() *fetchedKeys {
+	config.lock.RLock()
+	defer config.lock.RUnlock()
+	if config.fetched == nil {
+		return new(fetchedKeys)
+	}
+	return config.fetched
+}
+
+// reload reads the changed config file, validates it and swaps the current
+// properties with the new one. Invalid changes are rejected.
+func (config *AthenzConfiguration) reload() {
+	properties := new(athenzProperties)
+	if err := config.loader.Reload(properties); err != nil {
+		logger.Error("unable to reload athenz config, error: " + err.Error())
+		return
+	}
+
+	if err := properties.validate(config.loader.UnusedKeys()); err != nil {
+		logger.Error("athenz config change rejected, error: " + err.Error())
+		return
+	}
+
+	config.lock.Lock()
+	if reflect.DeepEqual(properties, config.Properties) {
+		config.lock.Unlock()
+		return
+	}
+	config.Properties = properties
+	config.lock.Unlock()
+	config.keys.clear()
+
+	logger.Info("athenz config reloaded")
+	config.publish()
+}
+
+// Validate checks ZMS/ZTS urls and public keys and returns a ValidationError
+// that contains all problems, or nil if the config is valid.
+func (config *AthenzConfiguration) Validate() error {
+	return config.Get().validate(config.loader.UnusedKeys())
+}
+
+// validate checks the properties that are required to validate tokens
+// and policies. Unknown keys are reported as problems too.
+func (p *athenzProperties) validate(unknownKeys []string) error {
+	v := newValidationError("athenz")
+	v.unknownKeys(unknownKeys)
+
+	if p.ZtsUrl == "" {
+		v.addf("ztsUrl is required")
+	} else {
+		validateURL(v, "ztsUrl", p.ZtsUrl)
+	}
+	if p.ZmsUrl != "" {
+		validateURL(v, "zmsUrl", p.ZmsUrl)
+	}
+
+	validatePublicKeys(v, "ztsPublicKeys", p.ZtsPublicKeys)
+	validatePublicKeys(v, "zmsPublicKeys", p.ZmsPublicKeys)
+
+	return v.orNil()
+}
+
+// validateURL checks that the input is an absolute http(s) url.
+func validateURL(v *ValidationError, key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		v.addf("%s: invalid url '%s', expected an absolute http(s) url", key, value)
+	}
+}
+
+// validatePublicKeys checks that key ids are unique and keys are ybase64
+// encoded PEM public keys.
+func validatePublicKeys(v *ValidationError, key string, publicKeys []PublicKeys) {
+	if len(publicKeys) == 0 {
+		v.addf("%s is required", key)
+		return
+	}
+
+	ids := make(map[string]bool)
+	for i, publicKey := range publicKeys {
+		if publicKey.Id == "" {
+			v.addf("%s[%d]: id is required", key, i)
+		} else if ids[publicKey.Id] {
+			v.addf("%s[%d]: duplicate id '%s'", key, i, publicKey.Id)
+		}
+		ids[publicKey.Id] = true
+
+		pem, err := new(zmssvctoken.YBase64).DecodeString(publicKey.Key)
+		if err != nil {
+			v.addf("%s[%d]: key with id '%s' is not ybase64 encoded", key, i, publicKey.Id)
+			continue
+		}
+		if _, err := zmssvctoken.NewVerifier(pem); err != nil {
+			v.addf("%s[%d]: key with id '%s' is not a valid public key, %s", key, i, publicKey.Id, err.Error())
 		}
 	}
-	return ""
 }
 
 // newZpeConfiguration creates a new instance of ZpeConfiguration with
@@ -162,14 +449,265 @@ func LoadGlobalZpeConfig(filePath string) error {
 func LoadZpeConfig(zpeConfig *ZpeConfiguration, filePath string) error {
 
 	// load config properties into zpeProperties
-	zpeConfig.Properties = new(zpeProperties)
+	properties := new(zpeProperties)
 	zpeConfig.loader = NewConfigLoader()
-	if err := zpeConfig.loader.LoadConfig(zpeConfig.Properties, filePath); err != nil {
+	if err := zpeConfig.loader.LoadConfig(properties, filePath); err != nil {
 		return common.Errorf(fmt.Sprintf("unable to load config from %s: %s", filePath, err.Error()))
 	}
+	zpeConfig.set(properties)
 
 	// use default configuration for config loader
 	zpeConfig.loader.WithDefaultConfig()
+	zpeConfig.loader.OnChange(zpeConfig.reload)
 
 	return nil
 }
+
+// Get returns the current ZPE properties. Use Get instead of Properties
+// field when the config file may be reloaded at runtime.
+func (config *ZpeConfiguration) Get() *zpeProperties {
+	config.lock.RLock()
+	defer config.lock.RUnlock()
+	return config.Properties
+}
+
+// set swaps the current ZPE properties.
+func (config *ZpeConfiguration) set(properties *zpeProperties) {
+	config.lock.Lock()
+	defer config.lock.Unlock()
+	config.Properties = properties
+}
+
+// reload reads the changed config file, validates it and swaps the current
+// properties with the new one. Invalid changes are rejected.
+func (config *ZpeConfiguration) reload() {
+	properties := new(zpeProperties)
+	if err := config.loader.Reload(properties); err != nil {
+		logger.Error("unable to reload zpe config, error: " + err.Error())
+		return
+	}
+
+	if err := properties.validate(config.loader.UnusedKeys()); err != nil {
+		logger.Error("zpe config change rejected, error: " + err.Error())
+		return
+	}
+
+	config.lock.Lock()
+	if reflect.DeepEqual(properties, config.Properties) {
+		config.lock.Unlock()
+		return
+	}
+	config.Properties = properties
+	config.lock.Unlock()
+
+	logger.Info("zpe config reloaded")
+	config.publish()
+}
+
+// Validate checks ZPE properties and returns a ValidationError that contains
+// all problems, or nil if the config is valid.
+func (config *ZpeConfiguration) Validate() error {
+	return config.Get().validate(config.loader.UnusedKeys())
+}
+
+// validate checks required fields, intervals, token expiry ranges and TLS
+// files. Unknown keys are reported as problems too, they are usually typos.
+func (p *zpeProperties) validate(unknownKeys []string) error {
+	v := newValidationError("zpe")
+	v.unknownKeys(unknownKeys)
+
+	if p.PolicyFilesDir == "" {
+		v.addf("policy_files_dir is required")
+	}
+	if p.CleanupTokenInterval <= 0 {
+		v.addf("cleanup_token_interval must be positive, value: %d", p.CleanupTokenInterval)
+	}
+	if p.ZpuDownloadInterval <= 0 {
+		v.addf("zpu_download_interval must be positive, value: %d", p.ZpuDownloadInterval)
+	}
+	if p.PublicKeysRefreshInterval < 0 {
+		v.addf("public_keys_refresh_interval must not be negative, value: %d", p.PublicKeysRefreshInterval)
+	}
+	if p.TokenRefreshFraction < 0 || p.TokenRefreshFraction >= 1 {
+		v.addf("token_refresh_fraction must be in [0, 1) range, value: %v", p.TokenRefreshFraction)
+	}
+	if p.AllowedOffset < 0 {
+		v.addf("allowed_offset must not be negative, value: %d", p.AllowedOffset)
+	}
+	if !p.AthenzTokenNoExpiry && p.AthenzTokenMaxExpiry <= 0 {
+		v.addf("athenz_token_max_expiry must be positive when athenz_token_no_expiry is false, value: %d",
+			p.AthenzTokenMaxExpiry)
+	}
+	if p.TokenExpirationMin < 0 || p.TokenExpirationMax < 0 {
+		v.addf("token_expiration_min and token_expiration_max must not be negative, values: %d, %d",
+			p.TokenExpirationMin, p.TokenExpirationMax)
+	} else if p.TokenExpirationMax > 0 && p.TokenExpirationMin > p.TokenExpirationMax {
+		v.addf("token_expiration_min must not be greater than token_expiration_max, values: %d, %d",
+			p.TokenExpirationMin, p.TokenExpirationMax)
+	}
+	if p.NTokenExpiration < 0 {
+		v.addf("ntoken_expiration must not be negative, value: %d", p.NTokenExpiration)
+	}
+	if (p.CertFilePath == "") != (p.KeyFilePath == "") {
+		v.addf("cert_file_path and key_file_path must be set together")
+	}
+	// service identity certificate is created by the agent, if sia is enabled
+	if p.SiaProvider == "" {
+		v.fileExists("cert_file_path", p.CertFilePath)
+		v.fileExists("key_file_path", p.KeyFilePath)
+	} else {
+		p.validateSia(v)
+	}
+	if p.RoleNames != "" && p.DomainName == "" {
+		v.addf("domain_name is required when role_names is set")
+	}
+	for i, rule := range p.TokenAllowList {
+		if rule.Caller == "" || rule.Domain == "" || len(rule.Roles) == 0 {
+			v.addf("token_allow_list[%d] must have caller, domain and roles", i)
+		}
+		if rule.MaxExpiryTime < 0 {
+			v.addf("token_allow_list[%d] max_expiry_time must not be negative, value: %d", i, rule.MaxExpiryTime)
+		}
+	}
+	for i, rule := range p.HTTPMappings {
+		if err := rule.Validate(); err != nil {
+			v.addf("http_mappings[%d]: %s", i, err.Error())
+		}
+	}
+	for i, pattern := range p.DynamicDomains.Allow {
+		if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+			v.addf("dynamic_domains.allow[%d] is not a valid pattern: '%s'", i, pattern)
+		}
+	}
+	if p.DynamicDomains.Wait < 0 || p.DynamicDomains.RetryInterval < 0 || p.DynamicDomains.MaxDomains < 0 {
+		v.addf("dynamic_domains.wait, retry_interval and max_domains must not be negative, values: %d, %d, %d",
+			p.DynamicDomains.Wait, p.DynamicDomains.RetryInterval, p.DynamicDomains.MaxDomains)
+	}
+	for i, rule := range p.StalePolicies {
+		if _, err := path.Match(rule.Domain, ""); err != nil || rule.Domain == "" {
+			v.addf("stale_policies[%d].domain is not a valid pattern: '%s'", i, rule.Domain)
+		}
+		switch rule.Mode {
+		case StalePolicyStrict, StalePolicyFailOpen:
+		case StalePolicyGrace:
+			if rule.GracePeriod <= 0 {
+				v.addf("stale_policies[%d].grace_period must be positive in grace mode, value: %d", i,
+					rule.GracePeriod)
+			}
+		default:
+			v.addf("stale_policies[%d].mode must be strict, grace or fail_open, value: '%s'", i, rule.Mode)
+		}
+	}
+
+	return v.orNil()
+}
+
+// validateSia checks the properties that service identity certificate
+// management needs.
+func (p *zpeProperties) validateSia(v *ValidationError) {
+	required := []struct{ key, value string }{
+		{"cert_file_path", p.CertFilePath},
+		{"key_file_path", p.KeyFilePath},
+		{"domain_name", p.DomainName},
+		{"service_name", p.ServiceName},
+		{"sia_instance_id", p.SiaInstanceID},
+		{"sia_attestation_data_file", p.SiaAttestationDataFile},
+		{"sia_dns_suffix", p.SiaDNSSuffix},
+	}
+	for _, field := range required {
+		if field.value == "" {
+			v.addf("%s is required when sia_provider is set", field.key)
+		}
+	}
+	v.fileExists("sia_attestation_data_file", p.SiaAttestationDataFile)
+	if p.SiaCertExpiryTime < 0 {
+		v.addf("sia_cert_expiry_time must not be negative, value: %d", p.SiaCertExpiryTime)
+	}
+}
+
+// Allows checks the rule allows the caller to request role tokens of the
+// domain with the input roles, proxy for principal and id token service.
+// Empty roles means all roles of the domain, so it's only allowed by "*"
+// role. Callers without a certificate common name are never allowed.
+func (r TokenAllowRule) Allows(caller, domain string, roles []string, proxyForPrincipal,
+	idTokenService string) bool {
+	if caller == "" || (r.Caller != "*" && r.Caller != caller) || r.Domain != domain {
+		return false
+	}
+	if proxyForPrincipal != "" && !containsOrWildcard(r.ProxyForPrincipals, proxyForPrincipal) {
+		return false
+	}
+	if idTokenService != "" && !containsOrWildcard(r.IDTokenServices, idTokenService) {
+		return false
+	}
+	if len(roles) == 0 {
+		return containsOrWildcard(r.Roles, "*")
+	}
+	for _, role := range roles {
+		if !containsOrWildcard(r.Roles, role) {
+			return false
+		}
+	}
+	return true
+}
+
+// Header returns the lower case name of role token header.
+func (p ExtAuthzProperties) Header() string {
+	if p.TokenHeader == "" {
+		return defaultTokenHeader
+	}
+	return strings.ToLower(p.TokenHeader)
+}
+
+// Allows checks the domain matches an allow pattern.
+func (p DynamicDomainsProperties) Allows(domain string) bool {
+	for _, pattern := range p.Allow {
+		if matched, _ := path.Match(pattern, domain); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitTime returns the max wait time of the first access check of a
+// domain.
+func (p DynamicDomainsProperties) WaitTime() time.Duration {
+	return time.Duration(p.Wait) * time.Millisecond
+}
+
+// Retry returns the time that a failed domain isn't downloaded again.
+func (p DynamicDomainsProperties) Retry() time.Duration {
+	if p.RetryInterval == 0 {
+		return defaultDynamicDomainRetry
+	}
+	return time.Duration(p.RetryInterval) * time.Second
+}
+
+// Max returns the max number of subscribed domains.
+func (p DynamicDomainsProperties) Max() int {
+	if p.MaxDomains == 0 {
+		return defaultMaxDynamicDomains
+	}
+	return p.MaxDomains
+}
+
+// StalePolicy returns the stale policy mode of the first rule that matches
+// the domain and its grace period, strict if no rule matches.
+func (p *zpeProperties) StalePolicy(domain string) (string, time.Duration) {
+	for _, rule := range p.StalePolicies {
+		if matched, _ := path.Match(rule.Domain, domain); matched {
+			return rule.Mode, time.Duration(rule.GracePeriod) * time.Hour
+		}
+	}
+	return StalePolicyStrict, 0
+}
+
+// containsOrWildcard checks the values contain the input value or "*".
+func containsOrWildcard(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -17,9 +17,11 @@ package config
 
 import (
 	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/mapping"
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,6 +30,7 @@ const (
 	testConfigDirPrefix  = "config"
 	testAthenzConfigFile = "athenz.json"
 	testZpeConfigFile    = "zpe.json"
+	testPublicKey        = "LS0tLS1CRUdJTiBQVUJMSUMgS0VZLS0tLS0KTUlHZk1BMEdDU3FHU0liM0RRRUJBUVVBQTRHTkFEQ0JpUUtCZ1FERmQzSjJWOFdDUy9nTkUyR1BCY3R1T1J5awpqZ1FtTGZXclRRRkVGYld4TU1mVUdtUm8vSnFHQ0h1SjE0TWU5aXJjSU5CcTdvZkxFdXhrQ3dZc3dsNE8zd3BPCnRyQTFmekdTRFo4RGpmWUxDbThjWUovWml4WG1FbW1yVk01UGxVYVlRNkJ6U0FRdnFuZzdXSjJZYkIySEZmU2EKZEJqNUN6YXJvVGRVMXdPNEV3SURBUUFCCi0tLS0tRU5EIFBVQkxJQyBLRVktLS0tLQo-"
 )
 
 func CreateFile(fileName, content string) error {
@@ -188,3 +191,363 @@ func TestAthenzConfiguration_GetZtsPublicKeyWithValue(t *testing.T) {
 
 	a.Equal("ztsKey", athenzConfig.GetZtsPublicKey("0"))
 }
+
+func TestZpeConfiguration_Reload(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/" + testZpeConfigFile
+	err = CreateFile(configPath, `{"policy_files_dir": "./resource/policy","cleanup_token_interval":600,"zpu_download_interval":600,"athenz_token_max_expiry":30}`)
+	a.NoError(err)
+
+	zpeConfig := new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+
+	changed := make(chan struct{}, 1)
+	zpeConfig.OnChange(func() {
+		changed <- struct{}{}
+	})
+
+	// invalid change must be rejected
+	err = ioutil.WriteFile(configPath, []byte(`{"policy_files_dir": "./resource/policy","cleanup_token_interval":0,"zpu_download_interval":600,"athenz_token_max_expiry":30}`), 0644)
+	a.NoError(err)
+	select {
+	case <-changed:
+		a.Fail("invalid config change was applied")
+	case <-time.After(time.Second):
+	}
+	a.Equal(int64(600), zpeConfig.Get().CleanupTokenInterval)
+
+	err = ioutil.WriteFile(configPath, []byte(`{"policy_files_dir": "./resource/policy","cleanup_token_interval":60,"zpu_download_interval":600,"athenz_token_max_expiry":30}`), 0644)
+	a.NoError(err)
+	select {
+	case <-changed:
+		a.Equal(int64(60), zpeConfig.Get().CleanupTokenInterval)
+	case <-time.After(5 * time.Second):
+		a.Fail("config change was not notified")
+	}
+}
+
+func TestAthenzConfiguration_Reload(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/" + testAthenzConfigFile
+	err = CreateFile(configPath, `{"ztsUrl":"https://zts.athenz.io","ztsPublicKeys":[{"id":"0","key":"`+testPublicKey+
+		`"}],"zmsPublicKeys":[{"id":"0","key":"`+testPublicKey+`"}]}`)
+	a.NoError(err)
+
+	athenzConfig := new(AthenzConfiguration)
+	a.NoError(LoadAthenzConfig(athenzConfig, configPath))
+
+	changed := make(chan struct{}, 1)
+	athenzConfig.OnChange(func() {
+		changed <- struct{}{}
+	})
+
+	err = ioutil.WriteFile(configPath, []byte(`{"ztsUrl":"https://zts.athenz.io","ztsPublicKeys":[{"id":"0","key":"`+
+		testPublicKey+`"},{"id":"1","key":"`+testPublicKey+`"}],"zmsPublicKeys":[{"id":"0","key":"`+testPublicKey+`"}]}`), 0644)
+	a.NoError(err)
+	select {
+	case <-changed:
+		a.Equal(testPublicKey, athenzConfig.GetZtsPublicKey("1"))
+	case <-time.After(5 * time.Second):
+		a.Fail("config change was not notified")
+	}
+}
+
+func TestZpeConfiguration_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/" + testZpeConfigFile
+	err = CreateFile(configPath, `{"policy_files_dir": "./resource/policy","cleanup_token_interval":600,`+
+		`"zpu_download_interval":600,"athenz_token_max_expiry":30,"role_names":"reader"}`)
+	a.NoError(err)
+
+	zpeConfig := new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+	err = zpeConfig.Validate()
+	a.Error(err)
+	a.Equal([]string{"domain_name is required when role_names is set"}, err.(*ValidationError).Problems)
+
+	// all problems must be reported at once
+	err = CreateFile(configPath, `{"cleanup_token_interval":0,"zpu_download_interval":600,"role_name":"reader",`+
+		`"token_expiration_min":10,"token_expiration_max":5,"cert_file_path":"missing.pem"}`)
+	a.NoError(err)
+	zpeConfig = new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+	err = zpeConfig.Validate()
+	a.Error(err)
+	problems := err.(*ValidationError).Problems
+	a.Len(problems, 7)
+	a.Equal("unknown key 'role_name'", problems[0])
+	a.Equal("policy_files_dir is required", problems[1])
+	a.Contains(err.Error(), "invalid zpe config, 7 problem(s)")
+}
+
+func TestZpeConfiguration_TokenAllowList(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/zpe.toml"
+	err = CreateFile(configPath, `
+policy_files_dir = "./resource/policy"
+cleanup_token_interval = 600
+zpu_download_interval = 600
+athenz_token_max_expiry = 30
+
+[[token_allow_list]]
+caller = "sports.frontend"
+domain = "sports"
+roles = ["reader", "writer"]
+proxy_for_principals = ["user.jane"]
+
+[[token_allow_list]]
+caller = "*"
+domain = "weather"
+roles = ["*"]
+id_token_services = ["*"]
+
+[[token_allow_list]]
+caller = "sports.backend"
+domain = "sports"
+max_expiry_time = -1
+`)
+	a.NoError(err)
+
+	zpeConfig := new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+	rules := zpeConfig.Get().TokenAllowList
+	a.Len(rules, 3)
+	a.Equal(TokenAllowRule{Caller: "sports.frontend", Domain: "sports", Roles: []string{"reader", "writer"},
+		ProxyForPrincipals: []string{"user.jane"}}, rules[0])
+
+	err = zpeConfig.Validate()
+	a.Error(err)
+	a.Equal([]string{"token_allow_list[2] must have caller, domain and roles",
+		"token_allow_list[2] max_expiry_time must not be negative, value: -1"}, err.(*ValidationError).Problems)
+
+	a.True(rules[0].Allows("sports.frontend", "sports", []string{"reader"}, "", ""))
+	a.True(rules[0].Allows("sports.frontend", "sports", []string{"reader", "writer"}, "user.jane", ""))
+	a.False(rules[0].Allows("sports.frontend", "sports", []string{"reader", "admin"}, "", ""))
+	a.False(rules[0].Allows("sports.frontend", "sports", nil, "", ""))
+	a.False(rules[0].Allows("sports.frontend", "sports", []string{"reader"}, "user.john", ""))
+	a.False(rules[0].Allows("sports.backend", "sports", []string{"reader"}, "", ""))
+	a.False(rules[0].Allows("sports.frontend", "weather", []string{"reader"}, "", ""))
+
+	a.True(rules[1].Allows("sports.backend", "weather", nil, "", ""))
+	a.True(rules[1].Allows("sports.frontend", "weather", []string{"admin"}, "", ""))
+	a.False(rules[1].Allows("", "weather", []string{"admin"}, "", ""))
+	a.False(rules[1].Allows("sports.backend", "weather", nil, "user.jane", ""))
+	a.True(rules[1].Allows("sports.backend", "weather", nil, "", "api"))
+	a.False(rules[0].Allows("sports.frontend", "sports", []string{"reader"}, "", "api"))
+}
+
+func TestZpeConfiguration_ValidateSia(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	// cert and key files don't exist yet, they are downloaded by the agent
+	configPath := dir + "/" + testZpeConfigFile
+	err = CreateFile(configPath, `{"policy_files_dir": "./resource/policy","cleanup_token_interval":600,`+
+		`"zpu_download_interval":600,"athenz_token_max_expiry":30,"sia_provider":"sys.auth.example",`+
+		`"cert_file_path":"missing.cert.pem","key_file_path":"missing.key.pem","sia_cert_expiry_time":-1}`)
+	a.NoError(err)
+
+	zpeConfig := new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+	err = zpeConfig.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"domain_name is required when sia_provider is set",
+		"service_name is required when sia_provider is set",
+		"sia_instance_id is required when sia_provider is set",
+		"sia_attestation_data_file is required when sia_provider is set",
+		"sia_dns_suffix is required when sia_provider is set",
+		"sia_cert_expiry_time must not be negative, value: -1",
+	}, err.(*ValidationError).Problems)
+}
+
+func TestZpeConfiguration_HTTPMappings(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/zpe.toml"
+	err = CreateFile(configPath, `
+policy_files_dir = "./resource/policy"
+cleanup_token_interval = 600
+zpu_download_interval = 600
+athenz_token_max_expiry = 30
+
+[ext_authz]
+token_header = "X-Role-Token"
+
+[[http_mappings]]
+methods = ["GET", "HEAD"]
+path = "/api/{version}/articles/{id}"
+action = "read"
+resource = "sports:articles.{id}.{tenant}"
+headers = { tenant = "X-Tenant" }
+
+[[http_mappings]]
+path = "/static/{file*}"
+resource = "static.{name}"
+`)
+	a.NoError(err)
+
+	zpeConfig := new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+	err = zpeConfig.Validate()
+	a.Error(err)
+	a.Equal([]string{"http_mappings[1]: undefined variable 'name' in 'static.{name}'"},
+		err.(*ValidationError).Problems)
+
+	properties := zpeConfig.Get()
+	a.Equal(mapping.Rule{Methods: []string{"GET", "HEAD"}, Path: "/api/{version}/articles/{id}", Action: "read",
+		Resource: "sports:articles.{id}.{tenant}", Headers: map[string]string{"tenant": "X-Tenant"}},
+		properties.HTTPMappings[0])
+	a.Equal("x-role-token", properties.ExtAuthz.Header())
+	a.Equal("athenz-role-auth", ExtAuthzProperties{}.Header())
+}
+
+func TestZpeConfiguration_DynamicDomains(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/zpe.toml"
+	err = CreateFile(configPath, `
+policy_files_dir = "./resource/policy"
+cleanup_token_interval = 600
+zpu_download_interval = 600
+athenz_token_max_expiry = 30
+
+[dynamic_domains]
+allow = ["sports.*", "weather", "[news"]
+wait = 500
+max_domains = -1
+`)
+	a.NoError(err)
+
+	zpeConfig := new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+	err = zpeConfig.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"dynamic_domains.allow[2] is not a valid pattern: '[news'",
+		"dynamic_domains.wait, retry_interval and max_domains must not be negative, values: 500, 0, -1",
+	}, err.(*ValidationError).Problems)
+
+	dynamicDomains := zpeConfig.Get().DynamicDomains
+	a.True(dynamicDomains.Allows("sports.nba"))
+	a.True(dynamicDomains.Allows("weather"))
+	a.False(dynamicDomains.Allows("weather.rain"))
+	a.Equal(500*time.Millisecond, dynamicDomains.WaitTime())
+	a.Equal(time.Minute, dynamicDomains.Retry())
+	a.Equal(100, DynamicDomainsProperties{}.Max())
+	a.False(DynamicDomainsProperties{}.Allows("sports"))
+}
+
+func TestZpeConfiguration_StalePolicies(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/zpe.toml"
+	err = CreateFile(configPath, `
+policy_files_dir = "./resource/policy"
+cleanup_token_interval = 600
+zpu_download_interval = 600
+athenz_token_max_expiry = 30
+
+[[stale_policies]]
+domain = "sports.*"
+mode = "grace"
+grace_period = 24
+
+[[stale_policies]]
+domain = "weather"
+mode = "fail_open"
+
+[[stale_policies]]
+domain = "[news"
+mode = "open"
+
+[[stale_policies]]
+domain = "music"
+mode = "grace"
+`)
+	a.NoError(err)
+
+	zpeConfig := new(ZpeConfiguration)
+	a.NoError(LoadZpeConfig(zpeConfig, configPath))
+	err = zpeConfig.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"stale_policies[2].domain is not a valid pattern: '[news'",
+		"stale_policies[2].mode must be strict, grace or fail_open, value: 'open'",
+		"stale_policies[3].grace_period must be positive in grace mode, value: 0",
+	}, err.(*ValidationError).Problems)
+
+	mode, grace := zpeConfig.Get().StalePolicy("sports.nba")
+	a.Equal(StalePolicyGrace, mode)
+	a.Equal(24*time.Hour, grace)
+	mode, _ = zpeConfig.Get().StalePolicy("weather")
+	a.Equal(StalePolicyFailOpen, mode)
+	mode, grace = zpeConfig.Get().StalePolicy("sports")
+	a.Equal(StalePolicyStrict, mode)
+	a.Zero(grace)
+}
+
+func TestAthenzConfiguration_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("./", testConfigDirPrefix)
+	a.NoError(err)
+	defer RemoveAll(dir)
+
+	configPath := dir + "/" + testAthenzConfigFile
+	err = CreateFile(configPath, `{"ztsUrl":"https://zts.athenz.io","ztsPublicKeys":[{"id":"0","key":"`+testPublicKey+
+		`"}],"zmsPublicKeys":[{"id":"0","key":"`+testPublicKey+`"}]}`)
+	a.NoError(err)
+	athenzConfig := new(AthenzConfiguration)
+	a.NoError(LoadAthenzConfig(athenzConfig, configPath))
+	a.NoError(athenzConfig.Validate())
+
+	err = CreateFile(configPath, `{"ztsUrl":"zts_url","ztsPublicKeys":[{"id":"0","key":"key0"},{"id":"0","key":"`+
+		testPublicKey+`"}]}`)
+	a.NoError(err)
+	athenzConfig = new(AthenzConfiguration)
+	a.NoError(LoadAthenzConfig(athenzConfig, configPath))
+	err = athenzConfig.Validate()
+	a.Error(err)
+	a.Equal([]string{
+		"ztsUrl: invalid url 'zts_url', expected an absolute http(s) url",
+		"ztsPublicKeys[0]: key with id '0' is not a valid public key, Unable to load public key",
+		"ztsPublicKeys[1]: duplicate id '0'",
+		"zmsPublicKeys is required",
+	}, err.(*ValidationError).Problems)
+}
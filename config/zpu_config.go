@@ -18,6 +18,8 @@ package config
 import (
 	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/yahoo/athenz/utils/zpe-updater"
+	"reflect"
+	"sync"
 )
 
 var (
@@ -31,6 +33,14 @@ type (
 	ZpuConfiguration struct {
 		// Yahoo zpu configuration
 		Properties *zpu.ZpuConfiguration
+
+		loader Loader
+		// load reads ZPU properties from config files
+		load func() (*zpu.ZpuConfiguration, error)
+		// unknownKeys returns the keys of ZPU config that ZPU doesn't use
+		unknownKeys func() ([]string, error)
+		lock        sync.RWMutex
+		publisher
 	}
 )
 
@@ -44,12 +54,105 @@ func LoadGlobalZpuConfig(athenzConfPath, zpuConfPath string) error {
 // a ZpuConfiguration object.
 func LoadZpuConfig(zpuConfig *ZpuConfiguration, athenzConfPath, zpuConfPath string) error {
 
-	var err error
 	// load ZPU configuration using Yahoo zpe-updater
-	zpuConfig.Properties, err = zpu.NewZpuConfiguration(".", athenzConfPath, zpuConfPath)
+	properties, err := zpu.NewZpuConfiguration(".", athenzConfPath, zpuConfPath)
 	if err != nil {
 		return common.Errorf("unable to get zpu configuration, error: %s", err.Error())
 	}
+	zpuConfig.set(properties)
+	zpuConfig.load = func() (*zpu.ZpuConfiguration, error) {
+		return zpu.NewZpuConfiguration(".", athenzConfPath, zpuConfPath)
+	}
+	zpuConfig.unknownKeys = func() ([]string, error) {
+		return unknownJSONKeys(zpuConfPath, zpu.ZpuConf{})
+	}
+
+	// zpe-updater reads the file itself, so viper is only used to watch it
+	zpuConfig.loader = NewConfigLoader()
+	if err := zpuConfig.loader.LoadConfig(&map[string]interface{}{}, zpuConfPath); err != nil {
+		logger.Error("zpu config hot reload is disabled, error: " + err.Error())
+		return nil
+	}
+	zpuConfig.loader.WithDefaultConfig()
+	zpuConfig.loader.OnChange(zpuConfig.Reload)
 
 	return nil
 }
+
+// Get returns the current ZPU properties. Use Get instead of Properties
+// field when the config file may be reloaded at runtime.
+func (config *ZpuConfiguration) Get() *zpu.ZpuConfiguration {
+	config.lock.RLock()
+	defer config.lock.RUnlock()
+	return config.Properties
+}
+
+// set swaps the current ZPU properties.
+func (config *ZpuConfiguration) set(properties *zpu.ZpuConfiguration) {
+	config.lock.Lock()
+	defer config.lock.Unlock()
+	config.Properties = properties
+}
+
+// Reload reads ZPU and athenz configs again and swaps the current
+// properties with the new one. ZPU configuration contains ZTS url and
+// public keys, so Reload must be called when athenz config changes too.
+func (config *ZpuConfiguration) Reload() {
+	properties, err := config.load()
+	if err != nil {
+		logger.Error("zpu config change rejected, error: " + err.Error())
+		return
+	}
+
+	if err := validateZpu(properties, config.unknownKeys); err != nil {
+		logger.Error("zpu config change rejected, error: " + err.Error())
+		return
+	}
+
+	config.lock.Lock()
+	if reflect.DeepEqual(properties, config.Properties) {
+		config.lock.Unlock()
+		return
+	}
+	config.Properties = properties
+	config.lock.Unlock()
+
+	logger.Info("zpu config reloaded")
+	config.publish()
+}
+
+// Validate checks ZPU properties and returns a ValidationError that contains
+// all problems, or nil if the config is valid.
+func (config *ZpuConfiguration) Validate() error {
+	return validateZpu(config.Get(), config.unknownKeys)
+}
+
+// validateZpu checks the properties that ZPU needs to download policies.
+// Unknown keys of the ZPU config file are reported as problems too.
+func validateZpu(properties *zpu.ZpuConfiguration, unknownKeys func() ([]string, error)) error {
+	v := newValidationError("zpu")
+
+	if keys, err := unknownKeys(); err != nil {
+		v.addf("%s", err.Error())
+	} else {
+		v.unknownKeys(keys)
+	}
+
+	if properties.DomainList == "" {
+		v.addf("domains is required")
+	}
+	if properties.Zts == "" {
+		v.addf("ztsUrl of athenz config is required")
+	}
+	if len(properties.ZtsKeysmap) == 0 {
+		v.addf("ztsPublicKeys of athenz config is required")
+	}
+	if len(properties.ZmsKeysmap) == 0 {
+		v.addf("zmsPublicKeys of athenz config is required")
+	}
+	v.fileExists("privateKeyFile", properties.PrivateKeyFile)
+	v.fileExists("certFile", properties.CertFile)
+	v.fileExists("caCertFile", properties.CaCertFile)
+
+	return v.orNil()
+}
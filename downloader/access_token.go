@@ -0,0 +1,108 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 9:45 PM
+ *
+ * Description:
+ * RequestAccessToken gets an OAuth2 access token from ZTS token
+ * endpoint with client credentials grant. The service is authenticated
+ * by the client certificate of the http client.
+ *
+ */
+
+package downloader
+
+import (
+	"encoding/json"
+	"github.com/ardielle/ardielle-go/rdl"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	accessTokenPath = "/oauth2/token"
+)
+
+type (
+	// AccessTokenRequest holds the parameters of ZTS access token request.
+	AccessTokenRequest struct {
+		Domain string
+		// Roles empty roles means all roles of the domain that the service
+		// is a member of
+		Roles []string
+		// IDTokenService is the service of the domain that ID token is
+		// issued for, empty means no ID token
+		IDTokenService string
+		// ExpiryTime in seconds format, 0 uses ZTS default
+		ExpiryTime        int32
+		ProxyForPrincipal string
+	}
+)
+
+// Scope returns the OAuth2 scope of the request, e.g.
+// "openid sports:role.reader sports:service.api".
+func (r AccessTokenRequest) Scope() string {
+	scopes := make([]string, 0, len(r.Roles)+2)
+	if r.IDTokenService != "" {
+		scopes = append(scopes, "openid")
+	}
+	if len(r.Roles) == 0 {
+		scopes = append(scopes, r.Domain+":domain")
+	}
+	for _, role := range r.Roles {
+		scopes = append(scopes, r.Domain+":role."+role)
+	}
+	if r.IDTokenService != "" {
+		scopes = append(scopes, r.Domain+":service."+r.IDTokenService)
+	}
+	return strings.Join(scopes, " ")
+}
+
+// RequestAccessToken posts the access token request to ZTS token endpoint.
+func RequestAccessToken(client *http.Client, ztsURL string, request AccessTokenRequest) (*zts.AccessTokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("scope", request.Scope())
+	if request.ExpiryTime > 0 {
+		form.Set("expires_in", strconv.Itoa(int(request.ExpiryTime)))
+	}
+	if request.ProxyForPrincipal != "" {
+		form.Set("proxy_for_principal", request.ProxyForPrincipal)
+	}
+
+	resp, err := client.PostForm(ZtsAPIURL(ztsURL)+accessTokenPath, form)
+	if err != nil {
+		return nil, common.Errorf("unable to request access token, error: %s", err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, common.Errorf("unable to read access token response, error: %s", err.Error())
+	}
+	if resp.StatusCode != http.StatusOK {
+		resourceError := rdl.ResourceError{Code: resp.StatusCode, Message: string(body)}
+		_ = json.Unmarshal(body, &resourceError)
+		return nil, common.Errorf("access token request failed, error: %s", resourceError.Error())
+	}
+
+	token := new(zts.AccessTokenResponse)
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, common.Errorf("unable to parse access token response, error: %s", err.Error())
+	}
+	if token.Access_token == "" {
+		return nil, common.Error("access token response has no access token")
+	}
+	return token, nil
+}
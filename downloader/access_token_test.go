@@ -0,0 +1,76 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 10:20 PM
+ *
+ * Description:
+ *
+ */
+
+package downloader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAccessTokenRequest_Scope(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("sports:domain", AccessTokenRequest{Domain: "sports"}.Scope())
+	a.Equal("sports:role.reader sports:role.writer",
+		AccessTokenRequest{Domain: "sports", Roles: []string{"reader", "writer"}}.Scope())
+	a.Equal("openid sports:role.reader sports:service.api",
+		AccessTokenRequest{Domain: "sports", Roles: []string{"reader"}, IDTokenService: "api"}.Scope())
+}
+
+func TestRequestAccessToken(t *testing.T) {
+	a := assert.New(t)
+
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/zts/v1/oauth2/token" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = r.ParseForm()
+		form = r.PostForm
+		if form.Get("scope") == "weather:domain" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"code":403,"message":"principal is not included in any role"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"at","id_token":"it","token_type":"Bearer","expires_in":600}`))
+	}))
+	defer server.Close()
+
+	token, err := RequestAccessToken(server.Client(), server.URL, AccessTokenRequest{Domain: "sports",
+		Roles: []string{"reader"}, IDTokenService: "api", ExpiryTime: 600, ProxyForPrincipal: "user.jane"})
+	a.NoError(err)
+	a.Equal("at", token.Access_token)
+	a.Equal("it", token.Id_token)
+	a.Equal("Bearer", token.Token_type)
+	a.Equal(int32(600), *token.Expires_in)
+	a.Equal("client_credentials", form.Get("grant_type"))
+	a.Equal("openid sports:role.reader sports:service.api", form.Get("scope"))
+	a.Equal("600", form.Get("expires_in"))
+	a.Equal("user.jane", form.Get("proxy_for_principal"))
+
+	_, err = RequestAccessToken(server.Client(), server.URL, AccessTokenRequest{Domain: "sports"})
+	a.NoError(err)
+	a.Equal("", form.Get("expires_in"))
+	a.Equal("", form.Get("proxy_for_principal"))
+
+	_, err = RequestAccessToken(server.Client(), server.URL, AccessTokenRequest{Domain: "weather"})
+	a.Error(err)
+	a.Contains(err.Error(), "403 principal is not included in any role")
+}
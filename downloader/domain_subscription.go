@@ -0,0 +1,147 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 2:10 PM
+ *
+ * Description:
+ * DomainSubscriptions is the set of domains that are downloaded on
+ * demand, in addition to the static domain list of ZPU config. A domain
+ * is subscribed after its first policy file is downloaded and loaded, and
+ * ZPU downloads it periodically from then on. Subscriptions are saved in
+ * the subscriptions file of dynamic_domains to restore them on restart.
+ *
+ */
+
+package downloader
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// Subscriptions is the global set of subscribed domains.
+	Subscriptions = NewDomainSubscriptions()
+)
+
+type (
+	// DomainSubscriptions is a set of domain names, it's safe for
+	// concurrent use.
+	DomainSubscriptions struct {
+		mu      sync.RWMutex
+		domains map[string]bool
+	}
+)
+
+// NewDomainSubscriptions creates an empty set of subscribed domains.
+func NewDomainSubscriptions() *DomainSubscriptions {
+	return &DomainSubscriptions{domains: make(map[string]bool)}
+}
+
+// Add subscribes the domain.
+func (s *DomainSubscriptions) Add(domain string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.domains[domain] = true
+}
+
+// Len returns the number of subscribed domains.
+func (s *DomainSubscriptions) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.domains)
+}
+
+// Domains returns the sorted subscribed domains.
+func (s *DomainSubscriptions) Domains() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	domains := make([]string, 0, len(s.domains))
+	for domain := range s.domains {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// DomainList appends the subscribed domains to the comma separated domain
+// list of ZPU config, domains of the list are not repeated.
+func (s *DomainSubscriptions) DomainList(domainList string) string {
+	domains := make([]string, 0)
+	listed := make(map[string]bool)
+	for _, domain := range strings.Split(domainList, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" && !listed[domain] {
+			domains = append(domains, domain)
+			listed[domain] = true
+		}
+	}
+	for _, domain := range s.Domains() {
+		if !listed[domain] {
+			domains = append(domains, domain)
+		}
+	}
+	return strings.Join(domains, ",")
+}
+
+// Prune unsubscribes the domains that are not allowed anymore and returns
+// them.
+func (s *DomainSubscriptions) Prune(allows func(domain string) bool) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	removed := make([]string, 0)
+	for domain := range s.domains {
+		if !allows(domain) {
+			delete(s.domains, domain)
+			removed = append(removed, domain)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// Save writes the subscribed domains into the file, one domain per line.
+// The file is replaced atomically, empty path doesn't write it.
+func (s *DomainSubscriptions) Save(path string) error {
+	if path == "" {
+		return nil
+	}
+	content := strings.Join(s.Domains(), "\n")
+	tempPath := path + ".tmp"
+	if err := ioutil.WriteFile(tempPath, []byte(content), 0644); err != nil {
+		return common.Errorf("unable to write subscriptions file: %s, error: %s", tempPath, err.Error())
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return common.Errorf("unable to replace subscriptions file: %s, error: %s", path, err.Error())
+	}
+	return nil
+}
+
+// Restore subscribes the allowed domains of the file that Save wrote, so
+// domains that were subscribed before a restart are still downloaded. A
+// missing file has no domains.
+func (s *DomainSubscriptions) Restore(path string, allows func(domain string) bool) error {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return common.Errorf("unable to read subscriptions file: %s, error: %s", path, err.Error())
+	}
+	for _, domain := range strings.Split(string(data), "\n") {
+		if domain = strings.TrimSpace(domain); domain != "" && allows(domain) {
+			s.Add(domain)
+		}
+	}
+	return nil
+}
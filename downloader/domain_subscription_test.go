@@ -0,0 +1,89 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 2:35 PM
+ *
+ * Description:
+ *
+ */
+
+package downloader
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDomainSubscriptions(t *testing.T) {
+	a := assert.New(t)
+	subscriptions := NewDomainSubscriptions()
+	a.Equal("sports,weather", subscriptions.DomainList(" sports, weather,sports"))
+
+	subscriptions.Add("news")
+	subscriptions.Add("weather")
+	subscriptions.Add("news")
+	a.Equal(2, subscriptions.Len())
+	a.Equal([]string{"news", "weather"}, subscriptions.Domains())
+	a.Equal("sports,weather,news", subscriptions.DomainList("sports,weather"))
+	a.Equal("news,weather", subscriptions.DomainList(""))
+}
+
+func TestDomainSubscriptions_Prune(t *testing.T) {
+	a := assert.New(t)
+	subscriptions := NewDomainSubscriptions()
+	for _, domain := range []string{"sports.nba", "weather", "sports.nfl"} {
+		subscriptions.Add(domain)
+	}
+
+	a.Equal([]string{"weather"}, subscriptions.Prune(func(domain string) bool {
+		return strings.HasPrefix(domain, "sports.")
+	}))
+	a.Equal([]string{"sports.nba", "sports.nfl"}, subscriptions.Domains())
+	a.Empty(subscriptions.Prune(func(string) bool { return true }))
+	a.Equal([]string{"sports.nba", "sports.nfl"}, subscriptions.Prune(func(string) bool { return false }))
+	a.Equal(0, subscriptions.Len())
+}
+
+func TestDomainSubscriptions_Restore(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "subscriptions")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "subscriptions")
+
+	// a missing file has no domains
+	subscriptions := NewDomainSubscriptions()
+	a.NoError(subscriptions.Restore(path, func(string) bool { return true }))
+	a.Equal(0, subscriptions.Len())
+
+	for _, domain := range []string{"sports.nba", "weather", "sports.nfl"} {
+		subscriptions.Add(domain)
+	}
+	a.NoError(subscriptions.Save(path))
+	a.NoError(subscriptions.Save(""))
+	data, err := ioutil.ReadFile(path)
+	a.NoError(err)
+	a.Equal("sports.nba\nsports.nfl\nweather", string(data))
+	a.False(common.Exists(path + ".tmp"))
+
+	// only the allowed domains are subscribed again
+	restored := NewDomainSubscriptions()
+	a.NoError(restored.Restore(path, func(domain string) bool {
+		return strings.HasPrefix(domain, "sports.")
+	}))
+	a.Equal([]string{"sports.nba", "sports.nfl"}, restored.Domains())
+
+	a.Error(subscriptions.Save(filepath.Join(dir, "missing", "subscriptions")))
+	a.Error(restored.Restore(dir, func(string) bool { return true }))
+}
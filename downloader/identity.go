@@ -0,0 +1,279 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 10:40 PM
+ *
+ * Description:
+ * IdentityDownloader is a lightweight SIA, it gets the X.509 service
+ * identity certificate of the agent from ZTS. The instance is registered
+ * with the attestation data of the provider when there is no valid
+ * certificate, otherwise the certificate is refreshed with mutual TLS.
+ * The private key is generated once and reused, key and certificate
+ * files are written atomically.
+ *
+ */
+
+package downloader
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	identityKeySize = 2048
+)
+
+type (
+	// IdentityDownloader the interface that wraps service identity
+	// certificate provider
+	IdentityDownloader interface {
+		// Certificate returns the current service identity certificate, or
+		// nil if there is no valid certificate
+		Certificate() *x509.Certificate
+		// DownloadIdentity registers the instance, or refreshes its
+		// certificate if the current one is valid, and writes the new
+		// certificate into cert file
+		DownloadIdentity() (*x509.Certificate, error)
+	}
+
+	// IdentityConfig holds the properties of service identity certificate.
+	IdentityConfig struct {
+		ZtsURL              string
+		Provider            string
+		Domain              string
+		Service             string
+		InstanceID          string
+		DNSSuffix           string
+		AttestationDataFile string
+		CertFile            string
+		KeyFile             string
+		// CACertFile is optional, the certificate signer is written in it
+		CACertFile string
+		// ExpiryTime in minutes format, 0 uses ZTS default
+		ExpiryTime int32
+		Timeout    time.Duration
+	}
+
+	ztsIdentityDownloader struct {
+		config IdentityConfig
+	}
+)
+
+// NewIdentityDownloader creates new instance of IdentityDownloader type.
+func NewIdentityDownloader(config IdentityConfig) IdentityDownloader {
+	return ztsIdentityDownloader{config: config}
+}
+
+// Certificate returns the current service identity certificate. It returns
+// nil if cert and key files don't exist, don't match or the certificate is
+// expired.
+func (d ztsIdentityDownloader) Certificate() *x509.Certificate {
+	keyPair, err := tls.LoadX509KeyPair(d.config.CertFile, d.config.KeyFile)
+	if err != nil {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil || !time.Now().Before(cert.NotAfter) {
+		return nil
+	}
+	return cert
+}
+
+// DownloadIdentity gets a new service identity certificate from ZTS and
+// writes it into cert file. The current certificate is kept if any step
+// fails.
+func (d ztsIdentityDownloader) DownloadIdentity() (*x509.Certificate, error) {
+	key, keyPem, err := d.privateKey()
+	if err != nil {
+		return nil, err
+	}
+	csr, err := d.certificateRequest(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var identity *zts.InstanceIdentity
+	if d.Certificate() != nil {
+		identity, err = d.refresh(csr)
+	} else {
+		identity, err = d.register(csr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keyPair, err := tls.X509KeyPair([]byte(identity.X509Certificate), keyPem)
+	if err != nil {
+		return nil, common.Errorf("DownloadIdentity: invalid certificate, error: %s", err.Error())
+	}
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return nil, common.Errorf("DownloadIdentity: invalid certificate, error: %s", err.Error())
+	}
+
+	if d.config.CACertFile != "" && identity.X509CertificateSigner != "" {
+		if err := common.WriteFileAtomically(d.config.CACertFile, []byte(identity.X509CertificateSigner),
+			0644); err != nil {
+			return nil, err
+		}
+	}
+	if err := common.WriteFileAtomically(d.config.CertFile, []byte(identity.X509Certificate), 0644); err != nil {
+		return nil, err
+	}
+	logger.Info("DownloadIdentity: service identity certificate is valid until " + cert.NotAfter.String())
+	return cert, nil
+}
+
+// register registers the instance with the attestation data of the provider.
+func (d ztsIdentityDownloader) register(csr string) (*zts.InstanceIdentity, error) {
+	attestationData, err := ioutil.ReadFile(d.config.AttestationDataFile)
+	if err != nil {
+		return nil, common.Errorf("DownloadIdentity: unable to read attestation data, error: %s", err.Error())
+	}
+	client, err := d.ztsClient("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	identity, _, err := client.PostInstanceRegisterInformation(&zts.InstanceRegisterInformation{
+		Provider:        zts.ServiceName(d.config.Provider),
+		Domain:          zts.DomainName(d.config.Domain),
+		Service:         zts.SimpleName(d.config.Service),
+		AttestationData: strings.TrimSpace(string(attestationData)),
+		Csr:             csr,
+		ExpiryTime:      d.expiryTime(),
+	})
+	if err != nil {
+		return nil, common.Errorf("DownloadIdentity: unable to register instance, error: %s", err.Error())
+	}
+	return identity, nil
+}
+
+// refresh refreshes the certificate, ZTS authenticates the instance by the
+// current certificate.
+func (d ztsIdentityDownloader) refresh(csr string) (*zts.InstanceIdentity, error) {
+	client, err := d.ztsClient(d.config.CertFile, d.config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := client.PostInstanceRefreshInformation(zts.ServiceName(d.config.Provider),
+		zts.DomainName(d.config.Domain), zts.SimpleName(d.config.Service), zts.PathElement(d.config.InstanceID),
+		&zts.InstanceRefreshInformation{Csr: csr, ExpiryTime: d.expiryTime()})
+	if err != nil {
+		return nil, common.Errorf("DownloadIdentity: unable to refresh certificate, error: %s", err.Error())
+	}
+	return identity, nil
+}
+
+// ztsClient creates a ZTS client that uses the input cert and key files.
+func (d ztsIdentityDownloader) ztsClient(certFile, keyFile string) (*zts.ZTSClient, error) {
+	httpClient, err := NewZtsHTTPClient(certFile, keyFile, d.config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	client := zts.NewClient(ZtsAPIURL(d.config.ZtsURL), httpClient.Transport)
+	client.Timeout = d.config.Timeout
+	return &client, nil
+}
+
+// expiryTime returns the requested certificate expiry time, nil means ZTS
+// default.
+func (d ztsIdentityDownloader) expiryTime() *int32 {
+	if d.config.ExpiryTime <= 0 {
+		return nil
+	}
+	expiryTime := d.config.ExpiryTime
+	return &expiryTime
+}
+
+// privateKey loads the private key from key file. If key file doesn't exist
+// or it's invalid, a new RSA key is generated and written into key file.
+func (d ztsIdentityDownloader) privateKey() (crypto.Signer, []byte, error) {
+	if data, err := ioutil.ReadFile(d.config.KeyFile); err == nil {
+		if key, err := parsePrivateKey(data); err == nil {
+			return key, data, nil
+		}
+		logger.Error("DownloadIdentity: invalid private key, a new key is generated: " + d.config.KeyFile)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, identityKeySize)
+	if err != nil {
+		return nil, nil, common.Errorf("DownloadIdentity: unable to generate private key, error: %s", err.Error())
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := common.WriteFileAtomically(d.config.KeyFile, data, 0600); err != nil {
+		return nil, nil, err
+	}
+	return key, data, nil
+}
+
+// certificateRequest creates a PEM encoded CSR with the SAN entries that ZTS
+// expects for the instance.
+func (d ztsIdentityDownloader) certificateRequest(key crypto.Signer) (string, error) {
+	instanceURI, err := url.Parse("athenz://instanceid/" + d.config.Provider + "/" + d.config.InstanceID)
+	if err != nil {
+		return "", common.Errorf("DownloadIdentity: invalid instance id, error: %s", err.Error())
+	}
+
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:         d.config.Domain + "." + d.config.Service,
+			OrganizationalUnit: []string{d.config.Provider},
+		},
+		DNSNames: []string{
+			d.config.Service + "." + strings.Replace(d.config.Domain, ".", "-", -1) + "." + d.config.DNSSuffix,
+			d.config.InstanceID + ".instanceid.athenz." + d.config.DNSSuffix,
+		},
+		URIs: []*url.URL{instanceURI},
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return "", common.Errorf("DownloadIdentity: unable to create CSR, error: %s", err.Error())
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})), nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS1, PKCS8 or EC private key.
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, common.Error("no PEM data found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	}
+	return nil, common.Error("unsupported private key type")
+}
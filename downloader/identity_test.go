@@ -0,0 +1,191 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 11:00 PM
+ *
+ * Description:
+ *
+ */
+
+package downloader
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeInstanceProvider is a local stand-in for ZTS instance API, it signs
+// the CSRs by a test CA.
+type fakeInstanceProvider struct {
+	t      *testing.T
+	caKey  *ecdsa.PrivateKey
+	ca     *x509.Certificate
+	caPem  string
+	mu     sync.Mutex
+	serial int64
+	// paths of the received requests
+	requests []string
+	csrs     []*x509.CertificateRequest
+}
+
+func newFakeInstanceProvider(t *testing.T) *fakeInstanceProvider {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Athenz CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(der)
+	return &fakeInstanceProvider{t: t, caKey: caKey, ca: ca, serial: 1,
+		caPem: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}
+}
+
+func (f *fakeInstanceProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.URL.Path)
+
+	var info struct {
+		Csr             string `json:"csr"`
+		AttestationData string `json:"attestationData"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&info); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	block, _ := pem.Decode([]byte(info.Csr))
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil || csr.CheckSignature() != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if r.URL.Path == "/zts/v1/instance" && info.AttestationData != "attestation-document" {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"code":403,"message":"invalid attestation data"}`))
+		return
+	}
+	f.csrs = append(f.csrs, csr)
+
+	f.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(f.serial),
+		Subject:      csr.Subject,
+		DNSNames:     csr.DNSNames,
+		URIs:         csr.URIs,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, f.ca, csr.PublicKey, f.caKey)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+
+	identity := &zts.InstanceIdentity{
+		Provider:              "sys.auth.test",
+		Name:                  zts.ServiceName(csr.Subject.CommonName),
+		InstanceId:            "i-001",
+		X509Certificate:       string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		X509CertificateSigner: f.caPem,
+	}
+	if r.URL.Path == "/zts/v1/instance" {
+		w.WriteHeader(http.StatusCreated)
+	}
+	_ = json.NewEncoder(w).Encode(identity)
+}
+
+func TestIdentityDownloader_DownloadIdentity(t *testing.T) {
+	a := assert.New(t)
+
+	provider := newFakeInstanceProvider(t)
+	server := httptest.NewServer(provider)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "sia")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	attestationFile := dir + "/attestation"
+	a.NoError(ioutil.WriteFile(attestationFile, []byte("attestation-document\n"), 0600))
+
+	identityConfig := IdentityConfig{
+		ZtsURL:              server.URL,
+		Provider:            "sys.auth.test",
+		Domain:              "sports.prod",
+		Service:             "api",
+		InstanceID:          "i-001",
+		DNSSuffix:           "athenz.cloud",
+		AttestationDataFile: attestationFile,
+		CertFile:            dir + "/certs/service.cert.pem",
+		KeyFile:             dir + "/keys/service.key.pem",
+		CACertFile:          dir + "/certs/ca.cert.pem",
+		Timeout:             time.Second,
+	}
+	d := NewIdentityDownloader(identityConfig)
+	a.Nil(d.Certificate())
+
+	// there is no certificate, so the instance is registered
+	cert, err := d.DownloadIdentity()
+	a.NoError(err)
+	a.Equal("sports.prod.api", cert.Subject.CommonName)
+	a.Equal([]string{"/zts/v1/instance"}, provider.requests)
+	a.Equal([]string{"api.sports-prod.athenz.cloud", "i-001.instanceid.athenz.athenz.cloud"},
+		provider.csrs[0].DNSNames)
+	a.Equal("athenz://instanceid/sys.auth.test/i-001", provider.csrs[0].URIs[0].String())
+	a.NotNil(d.Certificate())
+	a.Equal(cert.SerialNumber, d.Certificate().SerialNumber)
+
+	info, err := os.Stat(identityConfig.KeyFile)
+	a.NoError(err)
+	a.Equal(os.FileMode(0600), info.Mode().Perm())
+	caCert, err := ioutil.ReadFile(identityConfig.CACertFile)
+	a.NoError(err)
+	a.Equal(provider.caPem, string(caCert))
+
+	// valid certificate is refreshed with the same key
+	refreshed, err := d.DownloadIdentity()
+	a.NoError(err)
+	a.Equal("/zts/v1/instance/sys.auth.test/sports.prod/api/i-001", provider.requests[1])
+	a.NotEqual(cert.SerialNumber, refreshed.SerialNumber)
+	a.Equal(cert.PublicKey, refreshed.PublicKey)
+	a.Equal(refreshed.SerialNumber, d.Certificate().SerialNumber)
+
+	// registration fails with invalid attestation data
+	a.NoError(os.Remove(identityConfig.CertFile))
+	a.NoError(ioutil.WriteFile(attestationFile, []byte("invalid"), 0600))
+	_, err = d.DownloadIdentity()
+	a.Error(err)
+	a.Contains(err.Error(), "invalid attestation data")
+	a.Nil(d.Certificate())
+}
@@ -0,0 +1,157 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 4:40 PM
+ *
+ * Description:
+ * KeyDownloader is the public key provider of the agent. It fetches
+ * ZTS and ZMS public keys from ZTS service identities sys.auth.zts and
+ * sys.auth.zms, so tokens and policies signed by rotated keys are
+ * validated without editing athenz config and restarting the agent.
+ *
+ */
+
+package downloader
+
+import (
+	"encoding/json"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	athenzSystemDomain = "sys.auth"
+	ztsServiceName     = "zts"
+	zmsServiceName     = "zms"
+)
+
+type (
+	// KeyDownloader the interface that wraps ZTS/ZMS public key provider
+	KeyDownloader interface {
+		// LoadCachedKeys loads the keys of the last successful download
+		// from cache file into the key store
+		LoadCachedKeys() error
+		// DownloadKeys fetches ZTS and ZMS public keys from ZTS, writes them
+		// into cache file and updates the key store
+		DownloadKeys() error
+	}
+
+	ztsKeyDownloader struct {
+		ztsURL    string
+		client    *http.Client
+		cacheFile string
+		keyStore  *config.AthenzConfiguration
+	}
+
+	// cachedKeys is the content of public keys cache file, it has the same
+	// keys as athenz config file.
+	cachedKeys struct {
+		ZtsPublicKeys []config.PublicKeys `json:"ztsPublicKeys"`
+		ZmsPublicKeys []config.PublicKeys `json:"zmsPublicKeys"`
+	}
+)
+
+// NewKeyDownloader creates new instance of KeyDownloader type. If cacheFile
+// is empty, fetched keys are only kept in memory.
+func NewKeyDownloader(ztsURL string, client *http.Client, cacheFile string,
+	keyStore *config.AthenzConfiguration) KeyDownloader {
+	return ztsKeyDownloader{
+		ztsURL:    ZtsAPIURL(ztsURL),
+		client:    client,
+		cacheFile: cacheFile,
+		keyStore:  keyStore,
+	}
+}
+
+// LoadCachedKeys loads the keys of the last successful download from cache
+// file into the key store. A missing cache file is not an error.
+func (d ztsKeyDownloader) LoadCachedKeys() error {
+	if d.cacheFile == "" || !common.Exists(d.cacheFile) {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(d.cacheFile)
+	if err != nil {
+		return common.Errorf("LoadCachedKeys: unable to read %s, error: %s", d.cacheFile, err.Error())
+	}
+	keys := new(cachedKeys)
+	if err := json.Unmarshal(data, keys); err != nil {
+		return common.Errorf("LoadCachedKeys: unable to parse %s, error: %s", d.cacheFile, err.Error())
+	}
+
+	d.keyStore.SetFetchedKeys(keys.ZtsPublicKeys, keys.ZmsPublicKeys)
+	logger.Info("LoadCachedKeys: public keys loaded from " + d.cacheFile)
+	return nil
+}
+
+// DownloadKeys fetches ZTS and ZMS public keys from ZTS, writes them into
+// cache file and updates the key store. The key store is not changed if
+// any of the downloads fails.
+func (d ztsKeyDownloader) DownloadKeys() error {
+	client := zts.NewClient(d.ztsURL, d.client.Transport)
+	client.Timeout = d.client.Timeout
+
+	ztsKeys, err := d.fetchKeys(client, ztsServiceName)
+	if err != nil {
+		return err
+	}
+	zmsKeys, err := d.fetchKeys(client, zmsServiceName)
+	if err != nil {
+		return err
+	}
+
+	if d.cacheFile != "" {
+		if err := writeCacheFile(d.cacheFile, &cachedKeys{ZtsPublicKeys: ztsKeys, ZmsPublicKeys: zmsKeys}); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+
+	d.keyStore.SetFetchedKeys(ztsKeys, zmsKeys)
+	logger.Info("DownloadKeys: public keys downloaded successfully")
+	return nil
+}
+
+// fetchKeys gets the public keys of a service of athenz system domain.
+func (d ztsKeyDownloader) fetchKeys(client zts.ZTSClient, service string) ([]config.PublicKeys, error) {
+	identity, err := client.GetServiceIdentity(athenzSystemDomain, zts.ServiceName(service))
+	if err != nil {
+		return nil, common.Errorf("DownloadKeys: unable to get %s.%s service identity, error: %s",
+			athenzSystemDomain, service, err.Error())
+	}
+
+	keys := make([]config.PublicKeys, 0, len(identity.PublicKeys))
+	for _, entry := range identity.PublicKeys {
+		if entry == nil || entry.Id == "" || entry.Key == "" {
+			continue
+		}
+		keys = append(keys, config.PublicKeys{Id: entry.Id, Key: entry.Key})
+	}
+	if len(keys) == 0 {
+		return nil, common.Errorf("DownloadKeys: %s.%s service identity has no public key",
+			athenzSystemDomain, service)
+	}
+	return keys, nil
+}
+
+// writeCacheFile writes keys into cache file atomically, so the cache file
+// is never partially written.
+func writeCacheFile(cacheFile string, keys *cachedKeys) error {
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return common.Errorf("DownloadKeys: unable to encode public keys, error: %s", err.Error())
+	}
+
+	if err := common.WriteFileAtomically(cacheFile, data, 0644); err != nil {
+		return common.Errorf("DownloadKeys: unable to write cache file, error: %s", err.Error())
+	}
+	return nil
+}
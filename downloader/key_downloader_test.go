@@ -0,0 +1,139 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 5:20 PM
+ *
+ * Description:
+ *
+ */
+
+package downloader
+
+import (
+	"encoding/json"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeZts is a local stand-in for ZTS service identity API.
+type fakeZts struct {
+	mu   sync.Mutex
+	keys map[string][]*zts.PublicKeyEntry
+}
+
+func (f *fakeZts) setKeys(service string, keys ...*zts.PublicKeyEntry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys[service] = keys
+}
+
+func (f *fakeZts) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for service, keys := range f.keys {
+		if r.URL.Path == "/zts/v1/domain/sys.auth/service/"+service {
+			_ = json.NewEncoder(w).Encode(&zts.ServiceIdentity{
+				Name:       zts.ServiceName("sys.auth." + service),
+				PublicKeys: keys,
+			})
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"code":404,"message":"Service not found"}`))
+}
+
+func newFakeZts() (*fakeZts, *httptest.Server) {
+	f := &fakeZts{keys: make(map[string][]*zts.PublicKeyEntry)}
+	return f, httptest.NewServer(f)
+}
+
+func TestKeyDownloader_DownloadKeys(t *testing.T) {
+	a := assert.New(t)
+
+	fake, server := newFakeZts()
+	defer server.Close()
+	fake.setKeys("zts", &zts.PublicKeyEntry{Id: "zts.0", Key: "ztsKey0"}, &zts.PublicKeyEntry{Id: "zts.1", Key: "ztsKey1"})
+	fake.setKeys("zms", &zts.PublicKeyEntry{Id: "zms.0", Key: "zmsKey0"})
+
+	dir, err := ioutil.TempDir("", "keys")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	cacheFile := dir + "/cache/athenz_keys.json"
+
+	// static keys take precedence over the fetched keys
+	athenzConfPath := dir + "/athenz.json"
+	a.NoError(ioutil.WriteFile(athenzConfPath,
+		[]byte(`{"ztsUrl":"`+server.URL+`","ztsPublicKeys":[{"id":"zts.0","key":"staticKey0"}]}`), 0644))
+	keyStore := new(config.AthenzConfiguration)
+	a.NoError(config.LoadAthenzConfig(keyStore, athenzConfPath))
+	changes := 0
+	keyStore.OnChange(func() { changes++ })
+
+	client, err := NewZtsHTTPClient("", "", time.Second)
+	a.NoError(err)
+	kd := NewKeyDownloader(server.URL+"/", client, cacheFile, keyStore)
+	a.NoError(kd.DownloadKeys())
+	a.Equal("staticKey0", keyStore.GetZtsPublicKey("zts.0"))
+	a.Equal("ztsKey1", keyStore.GetZtsPublicKey("zts.1"))
+	a.Equal("zmsKey0", keyStore.GetZmsPublicKey("zms.0"))
+	a.Equal("", keyStore.GetZtsPublicKey("zms.0"))
+	a.Equal(1, changes)
+
+	// same keys don't notify subscribers
+	a.NoError(kd.DownloadKeys())
+	a.Equal(1, changes)
+
+	// ZTS rotates its keys
+	fake.setKeys("zts", &zts.PublicKeyEntry{Id: "zts.2", Key: "ztsKey2"})
+	a.NoError(kd.DownloadKeys())
+	a.Equal("ztsKey2", keyStore.GetZtsPublicKey("zts.2"))
+	a.Equal("", keyStore.GetZtsPublicKey("zts.1"))
+	a.Equal(2, changes)
+
+	// a failed download keeps the current keys
+	fake.setKeys("zms")
+	a.Error(kd.DownloadKeys())
+	a.Equal("zmsKey0", keyStore.GetZmsPublicKey("zms.0"))
+
+	// a new key store loads the keys from cache file while ZTS is down
+	server.Close()
+	newKeyStore := new(config.AthenzConfiguration)
+	a.NoError(config.LoadAthenzConfig(newKeyStore, athenzConfPath))
+	kd = NewKeyDownloader(server.URL, client, cacheFile, newKeyStore)
+	a.Error(kd.DownloadKeys())
+	a.NoError(kd.LoadCachedKeys())
+	a.Equal("ztsKey2", newKeyStore.GetZtsPublicKey("zts.2"))
+	a.Equal("zmsKey0", newKeyStore.GetZmsPublicKey("zms.0"))
+}
+
+func TestKeyDownloader_LoadCachedKeysWithoutFile(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "keys")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	athenzConfPath := dir + "/athenz.json"
+	a.NoError(ioutil.WriteFile(athenzConfPath, []byte(`{"ztsUrl":"http://localhost"}`), 0644))
+	keyStore := new(config.AthenzConfiguration)
+	a.NoError(config.LoadAthenzConfig(keyStore, athenzConfPath))
+	a.NoError(NewKeyDownloader("http://localhost", http.DefaultClient, "", keyStore).LoadCachedKeys())
+	a.NoError(NewKeyDownloader("http://localhost", http.DefaultClient, "missing.json", keyStore).LoadCachedKeys())
+	a.Equal("", keyStore.GetZtsPublicKey("0"))
+}
@@ -19,10 +19,23 @@ import (
 	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/hamed-yousefi/athenz-agent/common/log"
 	"github.com/yahoo/athenz/utils/zpe-updater"
+	"sync"
+	"time"
 )
 
 var (
 	logger = log.GetLogger(common.GolangFileName())
+
+	// downloadLock serializes policy downloads, policies are downloaded by
+	// ZPU monitor and on demand by admin RPCs
+	downloadLock sync.Mutex
+
+	// lastDownload is the result of the last DownloadPolicies call
+	lastDownload struct {
+		sync.RWMutex
+		time time.Time
+		err  error
+	}
 )
 
 type (
@@ -45,7 +58,13 @@ func NewPolicyDownloader(zpuConfig *zpu.ZpuConfiguration) PolicyDownloader {
 }
 
 func (d zpuDownloader) DownloadPolicies() error {
+	downloadLock.Lock()
 	err := zpu.PolicyUpdater(d.zpuConfig)
+	downloadLock.Unlock()
+
+	lastDownload.Lock()
+	lastDownload.time, lastDownload.err = time.Now(), err
+	lastDownload.Unlock()
 	if err != nil {
 		logger.Error(err.Error())
 		return common.Errorf("DownloadPolicies: policy updater failed, %s", err.Error())
@@ -53,3 +72,11 @@ func (d zpuDownloader) DownloadPolicies() error {
 	logger.Info("DownloadPolicies: policy updater finished successfully")
 	return nil
 }
+
+// LastPolicyDownload returns the time and the error of the last policy
+// download, zero time if policies were never downloaded.
+func LastPolicyDownload() (time.Time, error) {
+	lastDownload.RLock()
+	defer lastDownload.RUnlock()
+	return lastDownload.time, lastDownload.err
+}
@@ -0,0 +1,105 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 7:05 PM
+ *
+ * Description:
+ * ZTS http client helpers. The client certificate is read from disk
+ * again when the cert or key file changes, so a long-lived client
+ * keeps working after the service identity certificate is refreshed.
+ *
+ */
+
+package downloader
+
+import (
+	"crypto/tls"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ztsAPIPath = "zts/v1"
+)
+
+type (
+	// certificateReloader loads a key pair and loads it again when cert or
+	// key file modification time changes.
+	certificateReloader struct {
+		certFile string
+		keyFile  string
+
+		mu          sync.Mutex
+		cert        *tls.Certificate
+		certModTime time.Time
+		keyModTime  time.Time
+	}
+)
+
+// NewZtsHTTPClient creates a http client for ZTS. If cert and key files are
+// set, the client uses them for mutual TLS authentication.
+func NewZtsHTTPClient(certFile, keyFile string, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if certFile != "" && keyFile != "" {
+		reloader := &certificateReloader{certFile: certFile, keyFile: keyFile}
+		if _, err := reloader.certificate(); err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = &tls.Config{
+			GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return reloader.certificate()
+			},
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// ZtsAPIURL appends ZTS API path to ZTS url, if it doesn't have it.
+func ZtsAPIURL(ztsURL string) string {
+	ztsURL = strings.TrimSuffix(ztsURL, "/")
+	if strings.HasSuffix(ztsURL, ztsAPIPath) {
+		return ztsURL
+	}
+	return ztsURL + "/" + ztsAPIPath
+}
+
+// certificate returns the loaded key pair. It loads the files again if any
+// of them changed. If the changed files can't be loaded, e.g. the cert is
+// written but the key is not yet, the previous key pair is returned.
+func (r *certificateReloader) certificate() (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certInfo, certErr := os.Stat(r.certFile)
+	keyInfo, keyErr := os.Stat(r.keyFile)
+	if r.cert != nil && (certErr != nil || keyErr != nil ||
+		(certInfo.ModTime().Equal(r.certModTime) && keyInfo.ModTime().Equal(r.keyModTime))) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			logger.Error("unable to reload ZTS client certificate, error: " + err.Error())
+			return r.cert, nil
+		}
+		return nil, common.Errorf("unable to load ZTS client certificate, error: %s", err.Error())
+	}
+
+	r.cert = &cert
+	if certErr == nil && keyErr == nil {
+		r.certModTime = certInfo.ModTime()
+		r.keyModTime = keyInfo.ModTime()
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,127 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 8:50 PM
+ *
+ * Description:
+ *
+ */
+
+package downloader
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+)
+
+// writeTestKeyPair writes a self-signed certificate with the input common name
+// and its key into certFile and keyFile.
+func writeTestKeyPair(t *testing.T, commonName, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDer, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer}),
+		0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}),
+		0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// commonName returns the subject common name of the certificate.
+func commonName(t *testing.T, cert []byte) string {
+	parsed, err := x509.ParseCertificate(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Subject.CommonName
+}
+
+// touch changes the modification time of the files, so the change is
+// detected on file systems with coarse timestamps.
+func touch(t *testing.T, modTime time.Time, files ...string) {
+	for _, file := range files {
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNewZtsHTTPClient(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "zts-client")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := dir+"/service.cert.pem", dir+"/service.key.pem"
+
+	_, err = NewZtsHTTPClient(certFile, keyFile, time.Second)
+	a.Error(err)
+
+	client, err := NewZtsHTTPClient("", "", time.Second)
+	a.NoError(err)
+	a.Equal(time.Second, client.Timeout)
+
+	writeTestKeyPair(t, "first", certFile, keyFile)
+	reloader := &certificateReloader{certFile: certFile, keyFile: keyFile}
+	cert, err := reloader.certificate()
+	a.NoError(err)
+	a.Equal("first", commonName(t, cert.Certificate[0]))
+
+	// refreshed certificate is loaded
+	writeTestKeyPair(t, "second", certFile, keyFile)
+	touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+	cert, err = reloader.certificate()
+	a.NoError(err)
+	a.Equal("second", commonName(t, cert.Certificate[0]))
+
+	// previous certificate is kept if the new files can't be loaded
+	a.NoError(ioutil.WriteFile(keyFile, []byte("invalid"), 0600))
+	touch(t, time.Now().Add(2*time.Minute), keyFile)
+	cert, err = reloader.certificate()
+	a.NoError(err)
+	a.Equal("second", commonName(t, cert.Certificate[0]))
+}
+
+func TestZtsAPIURL(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("https://zts.athenz.io:4443/zts/v1", ZtsAPIURL("https://zts.athenz.io:4443/"))
+	a.Equal("https://zts.athenz.io:4443/zts/v1", ZtsAPIURL("https://zts.athenz.io:4443"))
+	a.Equal("https://zts.athenz.io:4443/zts/v1", ZtsAPIURL("https://zts.athenz.io:4443/zts/v1"))
+	a.Equal("https://zts.athenz.io:4443/zts/v1", ZtsAPIURL("https://zts.athenz.io:4443/zts/v1/"))
+}
@@ -0,0 +1,109 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 10:05 PM
+ *
+ * Description:
+ * Access tokens of GetAccessToken are fetched from ZTS token endpoint
+ * with the service identity certificate and cached like role tokens.
+ * Requests are authorized by token_allow_list of zpe config the same
+ * way as GetServiceToken requests.
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+var (
+	accessTokens = cache.NewServiceTokenCache(fetchAccessToken, func() float64 {
+		return config.ZpeConfig.Get().TokenRefreshFraction
+	})
+)
+
+// fetchAccessToken gets an access token from ZTS with the service identity
+// certificate of the agent.
+func fetchAccessToken(key cache.ServiceTokenKey) (*cache.ServiceToken, error) {
+	zpeProperties := config.ZpeConfig.Get()
+	httpClient, err := ztsClients.get(zpeProperties.CertFilePath, zpeProperties.KeyFilePath)
+	if err != nil {
+		return nil, ztsClientError{err: err}
+	}
+
+	var roles []string
+	if key.Roles != "" {
+		roles = strings.Split(key.Roles, ",")
+	}
+	requestTime := time.Now()
+	accessToken, err := downloader.RequestAccessToken(httpClient, config.KeyStore.Get().ZtsUrl,
+		downloader.AccessTokenRequest{
+			Domain:            key.Domain,
+			Roles:             roles,
+			IDTokenService:    key.IDTokenService,
+			ExpiryTime:        key.MaxExpiry,
+			ProxyForPrincipal: key.ProxyForPrincipal,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens without expires_in are not cached
+	var expiryTime int64
+	if accessToken.Expires_in != nil {
+		expiryTime = requestTime.Add(time.Duration(*accessToken.Expires_in) * time.Second).Unix()
+	}
+	return &cache.ServiceToken{
+		Token:      accessToken.Access_token,
+		IDToken:    accessToken.Id_token,
+		TokenType:  accessToken.Token_type,
+		ExpiryTime: expiryTime,
+	}, nil
+}
+
+// accessTokenKey creates the cache key of the request. Empty fields are
+// replaced by zpe config values. If the request differs from zpe config, it
+// must be allowed for the caller by token_allow_list.
+func accessTokenKey(ctx context.Context, req *v1.AccessTokenRequest) (cache.ServiceTokenKey, error) {
+	idTokenService := strings.TrimSpace(req.IdTokenService)
+	domain, roles, maxExpiry, err := authorizeTokenRequest(ctx, "access token", req.Domain, req.Roles, req.ProxyForPrincipal,
+		idTokenService)
+	if err != nil {
+		return cache.ServiceTokenKey{}, err
+	}
+
+	key := cache.ServiceTokenKey{
+		Domain:            domain,
+		Roles:             roles,
+		MaxExpiry:         req.ExpiryTime,
+		ProxyForPrincipal: req.ProxyForPrincipal,
+		IDTokenService:    idTokenService,
+	}
+	if key.MaxExpiry == 0 {
+		key.MaxExpiry = limitExpiry(config.ZpeConfig.Get().TokenExpirationMax*60, maxExpiry)
+	}
+	if key.MaxExpiry < 0 {
+		return key, status.Errorf(codes.InvalidArgument, "expiry time must not be negative, value: %d", key.MaxExpiry)
+	}
+	if maxExpiry > 0 && key.MaxExpiry > maxExpiry {
+		return key, status.Errorf(codes.PermissionDenied,
+			"expiry time must not be greater than %d seconds of token_allow_list, value: %d", maxExpiry,
+			key.MaxExpiry)
+	}
+	return key, nil
+}
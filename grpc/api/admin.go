@@ -0,0 +1,229 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 10:12 AM
+ *
+ * Description:
+ * This file contains AdminService struct that implements gRPC
+ * AthenzAgentAdminServer interface. Admin APIs change the agent
+ * behavior at runtime without restarting it, e.g. turning on debug
+ * logs for a misbehaving sidecar, or forcing a policy refresh during
+ * an incident instead of waiting for the monitors.
+ *
+ */
+
+package api
+
+import (
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"github.com/yahoo/athenz/utils/zpe-updater"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+var (
+	adminLogger = log.GetLogger(common.GolangFileName())
+)
+
+// AdminService implements gRPC AthenzAgentAdminServer interface.
+type AdminService struct {
+	// LogInitializer is used to read and change the log level at runtime.
+	LogInitializer log.Initializer
+	// StartTime is the start time of the agent.
+	StartTime time.Time
+	// PolicyDownloader creates the downloader of RefreshPolicies, nil uses
+	// downloader.NewPolicyDownloader.
+	PolicyDownloader func(zpuConfig *zpu.ZpuConfiguration) downloader.PolicyDownloader
+}
+
+// GetLogLevel returns the active log level.
+func (adminService AdminService) GetLogLevel(ctx context.Context,
+	req *v1.GetLogLevelRequest) (*v1.LogLevelResponse, error) {
+	return &v1.LogLevelResponse{Level: adminService.LogInitializer.CurrentLevel().String()}, nil
+}
+
+// SetLogLevel changes the log level at runtime. It returns the new and the
+// previous log level.
+func (adminService AdminService) SetLogLevel(ctx context.Context,
+	req *v1.SetLogLevelRequest) (*v1.LogLevelResponse, error) {
+
+	level, err := log.ParseLevel(req.Level)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	previous := adminService.LogInitializer.CurrentLevel()
+	adminService.LogInitializer.SetLevel(level)
+	adminLogger.Info("log level changed from " + previous.String() + " to " + level.String())
+
+	return &v1.LogLevelResponse{Level: level.String(), PreviousLevel: previous.String()}, nil
+}
+
+// ListDomains returns the load status of each policy file and its domain,
+// e.g. to find out why a domain is not found.
+func (adminService AdminService) ListDomains(ctx context.Context,
+	req *v1.ListDomainsRequest) (*v1.ListDomainsResponse, error) {
+
+	return &v1.ListDomainsResponse{Domains: domainStatuses(nil)}, nil
+}
+
+// RefreshPolicies downloads the policy files of the domains and loads them
+// into the cache immediately, instead of waiting for ZpuDownloadInterval.
+// The domains must be in the domain list of ZPU config or subscribed on
+// demand, all of them are refreshed if the request has no domain.
+func (adminService AdminService) RefreshPolicies(ctx context.Context,
+	req *v1.RefreshPoliciesRequest) (*v1.RefreshPoliciesResponse, error) {
+
+	zpuConfig := config.ZpuConfig.Get()
+	if zpuConfig == nil {
+		return nil, status.Error(codes.FailedPrecondition, "zpu config is not loaded")
+	}
+	zpuProperties := *zpuConfig
+	configured := splitDomainList(downloader.Subscriptions.DomainList(zpuProperties.DomainList))
+	domains := req.Domains
+	if len(domains) == 0 {
+		domains = configured
+	}
+	if len(domains) == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "zpu config has no domain")
+	}
+	configuredSet := make(map[string]bool, len(configured))
+	for _, domain := range configured {
+		configuredSet[domain] = true
+	}
+	for _, domain := range domains {
+		if !configuredSet[domain] {
+			return nil, status.Errorf(codes.InvalidArgument, "domain '%s' is not in the domain list of zpu config "+
+				"or subscribed", domain)
+		}
+	}
+
+	zpuProperties.DomainList = strings.Join(domains, ",")
+	adminLogger.Info("refreshing policies of domains: " + zpuProperties.DomainList)
+	newPolicyDownloader := adminService.PolicyDownloader
+	if newPolicyDownloader == nil {
+		newPolicyDownloader = downloader.NewPolicyDownloader
+	}
+	downloadErr := newPolicyDownloader(&zpuProperties).DownloadPolicies()
+
+	// load the downloaded files even if some domains failed
+	if err := cache.LoadDirectory(config.ZpeConfig.Get().PolicyFilesDir); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if downloadErr != nil {
+		return nil, status.Error(codes.Unavailable, downloadErr.Error())
+	}
+	return &v1.RefreshPoliciesResponse{Domains: domainStatuses(func(domainStatus cache.DomainStatus) bool {
+		for _, domain := range domains {
+			if domainStatus.Domain == domain || domainStatus.FileName == domain+".pol" {
+				return true
+			}
+		}
+		return false
+	})}, nil
+}
+
+// ReloadCache verifies and loads all policy files again, e.g. after public
+// keys are fixed, instead of waiting for CleanupTokenInterval.
+func (adminService AdminService) ReloadCache(ctx context.Context,
+	req *v1.ReloadCacheRequest) (*v1.ReloadCacheResponse, error) {
+
+	adminLogger.Info("reloading all policy files")
+	cache.ReloadAll()
+	if err := cache.LoadDirectory(config.ZpeConfig.Get().PolicyFilesDir); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &v1.ReloadCacheResponse{Domains: domainStatuses(nil)}, nil
+}
+
+// FlushTokenCache removes all cached role tokens, they are validated again
+// by the next access checks.
+func (adminService AdminService) FlushTokenCache(ctx context.Context,
+	req *v1.FlushTokenCacheRequest) (*v1.FlushTokenCacheResponse, error) {
+
+	flushed := cache.FlushRoleTokenCache()
+	adminLogger.Info(fmt.Sprintf("role token cache flushed, %d tokens removed", flushed))
+	return &v1.FlushTokenCacheResponse{FlushedTokens: int32(flushed)}, nil
+}
+
+// GetStatus returns the status of the agent caches and the last policy
+// download.
+func (adminService AdminService) GetStatus(ctx context.Context,
+	req *v1.GetStatusRequest) (*v1.StatusResponse, error) {
+
+	response := &v1.StatusResponse{StartTime: unixSeconds(adminService.StartTime),
+		InvalidPolicyFiles: cache.InvalidPolicyFiles(), CachedRoleTokens: int32(cache.RoleTokenCacheSize()),
+		LastCacheLoadTime: unixSeconds(cache.LastLoadTime())}
+	if adminService.LogInitializer != nil {
+		response.LogLevel = adminService.LogInitializer.CurrentLevel().String()
+	}
+	if zpuProperties := config.ZpuConfig.Get(); zpuProperties != nil {
+		response.Domains = splitDomainList(zpuProperties.DomainList)
+	}
+	response.SubscribedDomains = downloader.Subscriptions.Domains()
+	for _, domainStatus := range cache.DomainStatuses() {
+		if domainStatus.Valid {
+			response.LoadedDomains++
+		}
+	}
+
+	downloadTime, downloadErr := downloader.LastPolicyDownload()
+	response.LastPolicyDownloadTime = unixSeconds(downloadTime)
+	if downloadErr != nil {
+		response.LastPolicyDownloadError = downloadErr.Error()
+	}
+	return response, nil
+}
+
+// domainStatuses converts the statuses of cache that match the filter, nil
+// filter matches all statuses.
+func domainStatuses(filter func(domainStatus cache.DomainStatus) bool) []*v1.DomainStatus {
+	statuses := cache.DomainStatuses()
+	domains := make([]*v1.DomainStatus, 0, len(statuses))
+	for _, domainStatus := range statuses {
+		if filter != nil && !filter(domainStatus) {
+			continue
+		}
+		domains = append(domains, &v1.DomainStatus{Domain: domainStatus.Domain, FileName: domainStatus.FileName,
+			LastLoadTime: unixSeconds(domainStatus.LastLoadTime), Valid: domainStatus.Valid,
+			ExpiryTime: unixSeconds(domainStatus.Expires), Assertions: &v1.AssertionCounts{
+				Allow: int32(domainStatus.Assertions.Allow), Deny: int32(domainStatus.Assertions.Deny),
+				Standard: int32(domainStatus.Assertions.Standard), Wildcard: int32(domainStatus.Assertions.Wildcard)},
+			LastError: domainStatus.LastError})
+	}
+	return domains
+}
+
+// splitDomainList splits the comma separated domain list of ZPU config.
+func splitDomainList(domainList string) []string {
+	domains := make([]string, 0)
+	for _, domain := range strings.Split(domainList, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// unixSeconds returns 0 for zero time.
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
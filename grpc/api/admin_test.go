@@ -0,0 +1,190 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 10:48 AM
+ *
+ * Description:
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"github.com/hamed-yousefi/athenz-agent/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/yahoo/athenz/utils/zpe-updater"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// fakePolicyDownloader downloads policies by calling itself.
+type fakePolicyDownloader func() error
+
+func (f fakePolicyDownloader) DownloadPolicies() error {
+	return f()
+}
+
+func TestAdminService_SetLogLevel(t *testing.T) {
+	a := assert.New(t)
+	logInit := log.NewLogrusInitializer()
+	logInit.InitialLog(log.Info)
+	logInit.SetLevel(log.Info)
+
+	adminService := AdminService{LogInitializer: logInit}
+	ctx := context.Background()
+
+	response, err := adminService.SetLogLevel(ctx, &v1.SetLogLevelRequest{Level: "debug"})
+	a.NoError(err)
+	a.Equal("debug", response.Level)
+	a.Equal("info", response.PreviousLevel)
+
+	response, err = adminService.GetLogLevel(ctx, &v1.GetLogLevelRequest{})
+	a.NoError(err)
+	a.Equal("debug", response.Level)
+
+	_, err = adminService.SetLogLevel(ctx, &v1.SetLogLevelRequest{Level: "verbose"})
+	a.Error(err)
+
+	logInit.SetLevel(log.Info)
+}
+
+func TestAdminService_ListDomains(t *testing.T) {
+	a := assert.New(t)
+	expiry := time.Now()
+	err := preparePolicyFiles(expiry)
+	a.NoError(err)
+	defer os.RemoveAll(testTempFolder)
+
+	files, _ := ioutil.ReadDir(testTempFolder)
+	cache.LoadDB(testTempFolder, files)
+
+	response, err := AdminService{}.ListDomains(context.Background(), &v1.ListDomainsRequest{})
+	a.NoError(err)
+
+	var angler *v1.DomainStatus
+	for _, domain := range response.Domains {
+		if domain.Domain == "angler" {
+			angler = domain
+		}
+	}
+	if a.NotNil(angler) {
+		a.Equal("angler.pol", angler.FileName)
+		a.True(angler.Valid)
+		a.Empty(angler.LastError)
+		a.Equal(expiry.Add(48*time.Hour).Unix(), angler.ExpiryTime)
+		a.InDelta(time.Now().Unix(), angler.LastLoadTime, 60)
+		a.Equal(&v1.AssertionCounts{Allow: 18, Deny: 4, Standard: 18, Wildcard: 4}, angler.Assertions)
+	}
+}
+
+func TestAdminService_RefreshPolicies(t *testing.T) {
+	a := assert.New(t)
+	err := preparePolicyFiles(time.Now())
+	a.NoError(err)
+	defer os.RemoveAll(testTempFolder)
+
+	policyData, err := ioutil.ReadFile(testTempFolder + "/angler.pol")
+	a.NoError(err)
+	a.NoError(os.Remove(testTempFolder + "/angler.pol"))
+
+	policyFilesDir := config.ZpeConfig.Properties.PolicyFilesDir
+	config.ZpeConfig.Properties.PolicyFilesDir = testTempFolder
+	config.ZpuConfig.Properties = &zpu.ZpuConfiguration{DomainList: "angler, sports"}
+	defer func() {
+		config.ZpeConfig.Properties.PolicyFilesDir = policyFilesDir
+		config.ZpuConfig.Properties = nil
+	}()
+
+	// the downloader writes the policy file of angler domain
+	var domainList string
+	var downloadErr error
+	adminService := AdminService{PolicyDownloader: func(zpuConfig *zpu.ZpuConfiguration) downloader.PolicyDownloader {
+		domainList = zpuConfig.DomainList
+		return fakePolicyDownloader(func() error {
+			if err := common.CreateFile(testTempFolder+"/angler.pol", string(policyData)); err != nil {
+				return err
+			}
+			return downloadErr
+		})
+	}}
+	ctx := context.Background()
+
+	_, err = adminService.RefreshPolicies(ctx, &v1.RefreshPoliciesRequest{Domains: []string{"weather"}})
+	a.Equal(codes.InvalidArgument, status.Code(err))
+
+	// subscribed domains are refreshed too
+	subscriptions := downloader.Subscriptions
+	downloader.Subscriptions = downloader.NewDomainSubscriptions()
+	defer func() { downloader.Subscriptions = subscriptions }()
+	downloader.Subscriptions.Add("weather")
+	_, err = adminService.RefreshPolicies(ctx, &v1.RefreshPoliciesRequest{Domains: []string{"weather"}})
+	a.NoError(err)
+	a.Equal("weather", domainList)
+
+	response, err := adminService.RefreshPolicies(ctx, &v1.RefreshPoliciesRequest{Domains: []string{"angler"}})
+	a.NoError(err)
+	a.Equal("angler", domainList)
+	if a.Len(response.Domains, 1) {
+		a.Equal("angler", response.Domains[0].Domain)
+		a.True(response.Domains[0].Valid)
+	}
+
+	downloadErr = common.Error("sports failed")
+	_, err = adminService.RefreshPolicies(ctx, &v1.RefreshPoliciesRequest{})
+	a.Equal(codes.Unavailable, status.Code(err))
+	a.Equal("angler,sports,weather", domainList)
+
+	reloadResponse, err := adminService.ReloadCache(ctx, &v1.ReloadCacheRequest{})
+	a.NoError(err)
+	a.NotEmpty(reloadResponse.Domains)
+	for _, domain := range reloadResponse.Domains {
+		if domain.Domain == "angler" {
+			a.True(domain.Valid)
+		}
+	}
+}
+
+func TestAdminService_GetStatus(t *testing.T) {
+	a := assert.New(t)
+	logInit := log.NewLogrusInitializer()
+	logInit.InitialLog(log.Info)
+	startTime := time.Now()
+	config.ZpuConfig.Properties = &zpu.ZpuConfiguration{DomainList: "angler"}
+	defer func() { config.ZpuConfig.Properties = nil }()
+
+	adminService := AdminService{LogInitializer: logInit, StartTime: startTime}
+	ctx := context.Background()
+
+	cache.PutRoleToken("token", &token.RoleToken{})
+	response, err := adminService.GetStatus(ctx, &v1.GetStatusRequest{})
+	a.NoError(err)
+	a.Equal(startTime.Unix(), response.StartTime)
+	a.Equal("info", response.LogLevel)
+	a.Equal([]string{"angler"}, response.Domains)
+	a.True(response.CachedRoleTokens > 0)
+
+	flushResponse, err := adminService.FlushTokenCache(ctx, &v1.FlushTokenCacheRequest{})
+	a.NoError(err)
+	a.Equal(response.CachedRoleTokens, flushResponse.FlushedTokens)
+
+	response, err = adminService.GetStatus(ctx, &v1.GetStatusRequest{})
+	a.NoError(err)
+	a.Equal(int32(0), response.CachedRoleTokens)
+}
@@ -0,0 +1,192 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 3:05 PM
+ *
+ * Description:
+ * Access checks of a domain that has no policies and matches an allow
+ * pattern of dynamic_domains start downloading its policy file from ZTS.
+ * The check waits for the download at most dynamic_domains.wait, and
+ * returns DENY_DOMAIN_LOADING if it's not finished yet. A loaded domain
+ * is subscribed, so ZPU downloads it periodically from then on.
+ *
+ */
+
+package api
+
+import (
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"golang.org/x/net/context"
+	"sync"
+	"time"
+)
+
+var (
+	domainLogger = log.GetLogger(common.GolangFileName())
+
+	domainLoads = newDomainLoader(fetchDomainPolicies)
+)
+
+type (
+	// domainLoad is a download of the policies of a domain, done is closed
+	// when the download is finished.
+	domainLoad struct {
+		done     chan struct{}
+		err      error
+		finished time.Time
+	}
+
+	// domainLoader starts one download per domain at a time, failed
+	// domains are not downloaded again before the retry interval.
+	domainLoader struct {
+		mu      sync.Mutex
+		loads   map[string]*domainLoad
+		pending int
+		fetch   func(domain string) error
+	}
+)
+
+func newDomainLoader(fetch func(domain string) error) *domainLoader {
+	return &domainLoader{loads: make(map[string]*domainLoad), fetch: fetch}
+}
+
+// load returns the pending or the last failed download of the domain, or
+// starts a new one. It returns nil if the max subscribed domains is
+// reached.
+func (l *domainLoader) load(domain string, properties config.DynamicDomainsProperties) *domainLoad {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if load, ok := l.loads[domain]; ok {
+		select {
+		case <-load.done:
+			if time.Since(load.finished) < properties.Retry() {
+				return load
+			}
+		default:
+			return load
+		}
+	}
+
+	l.evictFailed(properties.Retry())
+	if downloader.Subscriptions.Len()+l.pending >= properties.Max() {
+		domainLogger.Error(fmt.Sprintf("unable to subscribe domain %s, max dynamic domains %d is reached",
+			domain, properties.Max()))
+		return nil
+	}
+
+	load := &domainLoad{done: make(chan struct{})}
+	l.loads[domain] = load
+	l.pending++
+	domainLogger.Info("downloading policies of domain " + domain)
+
+	go func() {
+		err := l.fetch(domain)
+		if err != nil {
+			domainLogger.Error(fmt.Sprintf("unable to download policies of domain %s, error: %s", domain, err.Error()))
+		}
+
+		l.mu.Lock()
+		load.err, load.finished = err, time.Now()
+		l.pending--
+		// a loaded domain is found by the next checks, it's downloaded
+		// again only if its policies are removed
+		if err == nil {
+			delete(l.loads, domain)
+		}
+		l.mu.Unlock()
+		close(load.done)
+	}()
+	return load
+}
+
+// evictFailed removes the failed downloads that are older than the retry
+// interval, they are downloaded again by the next check. It must be called
+// with mu held.
+func (l *domainLoader) evictFailed(retry time.Duration) {
+	for domain, load := range l.loads {
+		select {
+		case <-load.done:
+			if time.Since(load.finished) >= retry {
+				delete(l.loads, domain)
+			}
+		default:
+		}
+	}
+}
+
+// allowActionOnDemand checks the access like allowAction, the policies of
+// a domain that is not found are downloaded if dynamic_domains allows it.
+func allowActionOnDemand(ctx context.Context, action, resource, domain string,
+	roles []string) (*v1.AccessCheckResponse, error) {
+
+	resp, err := allowAction(action, resource, domain, roles, time.Now().UnixNano())
+	if err != nil || resp.AccessCheckStatus != DenyDomainNotFound {
+		return resp, err
+	}
+
+	properties := config.ZpeConfig.Get().DynamicDomains
+	if !properties.Allows(domain) {
+		return resp, nil
+	}
+	load := domainLoads.load(domain, properties)
+	if load == nil {
+		return resp, nil
+	}
+
+	timer := time.NewTimer(properties.WaitTime())
+	defer timer.Stop()
+	select {
+	case <-load.done:
+		if load.err != nil {
+			return resp, nil
+		}
+		return allowAction(action, resource, domain, roles, time.Now().UnixNano())
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return &v1.AccessCheckResponse{AccessCheckStatus: DenyDomainLoading}, nil
+}
+
+// fetchDomainPolicies downloads the policy file of the domain with the ZPU
+// config, loads it into the cache and subscribes the domain.
+func fetchDomainPolicies(domain string) error {
+	zpuConfig := config.ZpuConfig.Get()
+	if zpuConfig == nil {
+		return common.Error("zpu config is not loaded")
+	}
+	zpuProperties := *zpuConfig
+	zpuProperties.DomainList = domain
+	if err := downloader.NewPolicyDownloader(&zpuProperties).DownloadPolicies(); err != nil {
+		return err
+	}
+
+	if err := cache.LoadDirectory(config.ZpeConfig.Get().PolicyFilesDir); err != nil {
+		return err
+	}
+	for _, domainStatus := range cache.DomainStatuses() {
+		if domainStatus.Domain == domain && domainStatus.Valid {
+			downloader.Subscriptions.Add(domain)
+			// the domain is subscribed even if it's not saved, it's only
+			// not restored on restart
+			subscriptionsFile := config.ZpeConfig.Get().DynamicDomains.SubscriptionsFile
+			if err := downloader.Subscriptions.Save(subscriptionsFile); err != nil {
+				domainLogger.Error(err.Error())
+			}
+			return nil
+		}
+	}
+	return common.Errorf("policy file of domain %s is not loaded", domain)
+}
@@ -0,0 +1,147 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 3:40 PM
+ *
+ * Description:
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPermissionService_CheckAccessWithTokenDomainLoading(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(preparePolicyFiles(time.Now()))
+	defer os.RemoveAll(testTempFolder)
+
+	// the download of sports domain is finished by the test
+	var fetched int32
+	release := make(chan error)
+	loader := domainLoads
+	domainLoads = newDomainLoader(func(domain string) error {
+		atomic.AddInt32(&fetched, 1)
+		return <-release
+	})
+	defer func() {
+		domainLoads = loader
+		config.ZpeConfig.Properties.DynamicDomains = config.DynamicDomainsProperties{}
+	}()
+
+	tst := PermissionService{}
+	ctx := context.Background()
+	request := &v1.AccessCheckRequest{Access: "read", Resource: "sports:stuff",
+		Token: createRoleToken("public", "sports")}
+
+	// dynamic domains are disabled
+	status, err := tst.CheckAccessWithToken(ctx, request)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_NOT_FOUND, status.AccessCheckStatus)
+
+	config.ZpeConfig.Properties.DynamicDomains = config.DynamicDomainsProperties{Allow: []string{"sport*"},
+		Wait: 10, RetryInterval: 60}
+	status, err = tst.CheckAccessWithToken(ctx, request)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_LOADING, status.AccessCheckStatus)
+
+	// the pending download is not started again
+	status, err = tst.CheckAccessWithToken(ctx, request)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_LOADING, status.AccessCheckStatus)
+	a.Equal(int32(1), atomic.LoadInt32(&fetched))
+
+	// the failed download is not retried before the retry interval
+	release <- common.Error("sports not found")
+	status, err = tst.CheckAccessWithToken(ctx, request)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_NOT_FOUND, status.AccessCheckStatus)
+	a.Equal(int32(1), atomic.LoadInt32(&fetched))
+}
+
+func TestDomainLoader(t *testing.T) {
+	a := assert.New(t)
+	release := make(chan error)
+	var fetched int32
+	loader := newDomainLoader(func(domain string) error {
+		atomic.AddInt32(&fetched, 1)
+		return <-release
+	})
+	properties := config.DynamicDomainsProperties{MaxDomains: 1, RetryInterval: 60}
+
+	load := loader.load("sports", properties)
+	if a.NotNil(load) {
+		a.Same(load, loader.load("sports", properties))
+	}
+	// max domains is reached by the pending download
+	a.Nil(loader.load("weather", properties))
+
+	release <- nil
+	<-load.done
+	a.NoError(load.err)
+
+	// a loaded domain is downloaded again if it's not found
+	load = loader.load("sports", properties)
+	if a.NotNil(load) {
+		release <- common.Error("sports failed")
+		<-load.done
+		a.Error(load.err)
+	}
+	a.Same(load, loader.load("sports", properties))
+
+	properties.RetryInterval = 1
+	load.finished = time.Now().Add(-time.Second)
+	retry := loader.load("sports", properties)
+	if a.NotNil(retry) && a.NotSame(load, retry) {
+		release <- nil
+		<-retry.done
+	}
+	a.Equal(int32(3), atomic.LoadInt32(&fetched))
+}
+
+func TestDomainLoader_EvictFailed(t *testing.T) {
+	a := assert.New(t)
+	loader := newDomainLoader(func(domain string) error {
+		return common.Errorf("%s not found", domain)
+	})
+	properties := config.DynamicDomainsProperties{RetryInterval: 60}
+
+	for _, domain := range []string{"sports", "weather"} {
+		if load := loader.load(domain, properties); a.NotNil(load) {
+			<-load.done
+			a.Error(load.err)
+		}
+	}
+	a.Len(loader.loads, 2)
+
+	// the failed downloads are removed when a new one is started after the
+	// retry interval
+	loader.mu.Lock()
+	loader.loads["sports"].finished = time.Now().Add(-time.Minute)
+	loader.mu.Unlock()
+	if load := loader.load("news", properties); a.NotNil(load) {
+		<-load.done
+	}
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	a.Len(loader.loads, 2)
+	a.NotContains(loader.loads, "sports")
+	a.Contains(loader.loads, "weather")
+	a.Contains(loader.loads, "news")
+}
@@ -0,0 +1,125 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 3:30 AM
+ *
+ * Description:
+ * This file contains ExtAuthzService struct that implements Envoy
+ * ext_authz gRPC AuthorizationServer interface, so Envoy can consult
+ * the agent before it routes a request. The role token is read from
+ * the request headers, the request is mapped to action and resource by
+ * http_mappings rules of zpe config, then the access is checked like
+ * CheckAccessWithToken. Denied requests get 403 with the access
+ * status in x-athenz-access-status header, or 503 while the policies of
+ * the domain are loading.
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	corev3 "github.com/hamed-yousefi/athenz-agent/.gen/proto/envoy/config/core/v3"
+	authv3 "github.com/hamed-yousefi/athenz-agent/.gen/proto/envoy/service/auth/v3"
+	typev3 "github.com/hamed-yousefi/athenz-agent/.gen/proto/envoy/type/v3"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/mapping"
+	"golang.org/x/net/context"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// AccessStatusHeader is the header of denied responses that holds the
+	// AccessStatus name, e.g. DENY_NO_MATCH
+	AccessStatusHeader = "x-athenz-access-status"
+	// StalePolicyModeHeader is the header of allowed requests that holds
+	// the StalePolicyMode name if the policies of the domain are expired
+	StalePolicyModeHeader = "x-athenz-stale-policy-mode"
+)
+
+// ExtAuthzService implements Envoy ext_authz gRPC AuthorizationServer
+// interface.
+type ExtAuthzService struct{}
+
+// Check maps the HTTP request of Envoy to action and resource and checks
+// the access of its role token. Allowed requests get OK status, the others
+// get PERMISSION_DENIED status with 403 denied response.
+func (extAuthzService ExtAuthzService) Check(ctx context.Context,
+	req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+
+	httpRequest := req.GetAttributes().GetRequest().GetHttp()
+	if httpRequest == nil {
+		return deniedResponse(v1.AccessStatus_DENY_INVALID_PARAMETERS), nil
+	}
+
+	roleToken := headerToken(httpRequest.Headers, config.ZpeConfig.Get().ExtAuthz.Header())
+	if roleToken == "" {
+		return deniedResponse(v1.AccessStatus_DENY_ROLE_TOKEN_INVALID), nil
+	}
+
+	m, err := mapRequest(mapping.Request{Method: httpRequest.Method, Path: httpRequest.Path,
+		Headers: httpRequest.Headers})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := PermissionService{}.CheckAccessWithToken(ctx, &v1.AccessCheckRequest{
+		Token:    roleToken,
+		Access:   m.Action,
+		Resource: m.Resource,
+	})
+	if err != nil {
+		// the token can't be parsed or verified
+		if status.Code(err) == codes.InvalidArgument {
+			return deniedResponse(v1.AccessStatus_DENY_ROLE_TOKEN_INVALID), nil
+		}
+		return nil, err
+	}
+
+	if resp.AccessCheckStatus != v1.AccessStatus_ALLOW {
+		return deniedResponse(resp.AccessCheckStatus), nil
+	}
+	okResponse := &authv3.OkHttpResponse{}
+	if resp.StalePolicyMode != v1.StalePolicyMode_FRESH {
+		okResponse.Headers = []*corev3.HeaderValueOption{{
+			Header: &corev3.HeaderValue{Key: StalePolicyModeHeader, Value: resp.StalePolicyMode.String()},
+		}}
+	}
+	return &authv3.CheckResponse{
+		Status:       &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{OkResponse: okResponse},
+	}, nil
+}
+
+// deniedResponse creates the 403 response of the access status, or 503 if
+// the policies of the domain are loading.
+func deniedResponse(accessStatus v1.AccessStatus) *authv3.CheckResponse {
+	code, httpCode := codes.PermissionDenied, typev3.StatusCode_Forbidden
+	headers := []*corev3.HeaderValueOption{{
+		Header: &corev3.HeaderValue{Key: AccessStatusHeader, Value: accessStatus.String()},
+	}}
+	if accessStatus == v1.AccessStatus_DENY_DOMAIN_LOADING {
+		code, httpCode = codes.Unavailable, typev3.StatusCode_ServiceUnavailable
+		headers = append(headers, &corev3.HeaderValueOption{
+			Header: &corev3.HeaderValue{Key: "retry-after", Value: "1"},
+		})
+	}
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{
+			Code:    int32(code),
+			Message: "access denied, status: " + accessStatus.String(),
+		},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{DeniedResponse: &authv3.DeniedHttpResponse{
+			Status:  &typev3.HttpStatus{Code: httpCode},
+			Headers: headers,
+			Body:    accessStatus.String(),
+		}},
+	}
+}
@@ -0,0 +1,106 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 3:55 AM
+ *
+ * Description:
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	authv3 "github.com/hamed-yousefi/athenz-agent/.gen/proto/envoy/service/auth/v3"
+	typev3 "github.com/hamed-yousefi/athenz-agent/.gen/proto/envoy/type/v3"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/mapping"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestExtAuthzService_Check(t *testing.T) {
+	a := assert.New(t)
+	err := preparePolicyFiles(time.Now())
+	a.NoError(err)
+	defer os.RemoveAll(testTempFolder)
+
+	files, _ := ioutil.ReadDir(testTempFolder)
+	cache.LoadDB(testTempFolder, files)
+
+	defer setHTTPMappings([]mapping.Rule{
+		{Methods: []string{"GET"}, Path: "/api/{name}", Action: "read", Resource: "angler:{name}"}})()
+
+	check := func(method, path string, headers map[string]string) *authv3.CheckResponse {
+		resp, err := ExtAuthzService{}.Check(context.Background(), &authv3.CheckRequest{
+			Attributes: &authv3.AttributeContext{Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{Method: method, Path: path, Headers: headers},
+			}},
+		})
+		a.NoError(err)
+		return resp
+	}
+	signedToken := createRoleToken("public", "angler")
+
+	resp := check("GET", "/api/stuff?page=1", map[string]string{"athenz-role-auth": signedToken})
+	a.Equal(int32(codes.OK), resp.Status.Code)
+	a.NotNil(resp.GetOkResponse())
+
+	resp = check("GET", "/api/stuff", map[string]string{"authorization": "Bearer " + signedToken})
+	a.Equal(int32(codes.OK), resp.Status.Code)
+
+	// "post" action is not allowed
+	resp = check("POST", "/api/stuff", map[string]string{"athenz-role-auth": signedToken})
+	a.Equal(int32(codes.PermissionDenied), resp.Status.Code)
+	denied := resp.GetDeniedResponse()
+	a.Equal(typev3.StatusCode_Forbidden, denied.Status.Code)
+	a.Equal(AccessStatusHeader, denied.Headers[0].Header.Key)
+	a.Equal(v1.AccessStatus_DENY_NO_MATCH.String(), denied.Headers[0].Header.Value)
+
+	// missing and invalid tokens
+	resp = check("GET", "/api/stuff", nil)
+	a.Equal(v1.AccessStatus_DENY_ROLE_TOKEN_INVALID.String(), resp.GetDeniedResponse().Body)
+	resp = check("GET", "/api/stuff", map[string]string{"athenz-role-auth": "invalid"})
+	a.Equal(v1.AccessStatus_DENY_ROLE_TOKEN_INVALID.String(), resp.GetDeniedResponse().Body)
+
+	// the resource of the other domain
+	defer setHTTPMappings([]mapping.Rule{
+		{Methods: []string{"GET"}, Path: "/api/{name}", Action: "read", Resource: "sports:{name}"}})()
+	resp = check("GET", "/api/stuff", map[string]string{"athenz-role-auth": signedToken})
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_MISMATCH.String(), resp.GetDeniedResponse().Body)
+
+	resp, err = ExtAuthzService{}.Check(context.Background(), &authv3.CheckRequest{})
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_INVALID_PARAMETERS.String(), resp.GetDeniedResponse().Body)
+}
+
+func TestAttributeContext_FieldNumbers(t *testing.T) {
+	a := assert.New(t)
+
+	// the field numbers must be the same as Envoy API to decode its requests
+	peerFields := (&authv3.AttributeContext_Peer{}).ProtoReflect().Descriptor().Fields()
+	for name, number := range map[string]int{"service": 2, "labels": 3, "principal": 4, "certificate": 5} {
+		a.EqualValues(number, peerFields.ByName(protoreflect.Name(name)).Number(), name)
+	}
+	httpFields := (&authv3.AttributeContext_HttpRequest{}).ProtoReflect().Descriptor().Fields()
+	for name, number := range map[string]int{"id": 1, "method": 2, "headers": 3, "path": 4, "host": 5, "scheme": 6,
+		"query": 7, "fragment": 8, "size": 9, "protocol": 10, "body": 11} {
+		a.EqualValues(number, httpFields.ByName(protoreflect.Name(name)).Number(), name)
+	}
+	contextFields := (&authv3.AttributeContext{}).ProtoReflect().Descriptor().Fields()
+	for name, number := range map[string]int{"source": 1, "destination": 2, "request": 4, "context_extensions": 10} {
+		a.EqualValues(number, contextFields.ByName(protoreflect.Name(name)).Number(), name)
+	}
+}
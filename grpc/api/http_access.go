@@ -0,0 +1,134 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 5:10 AM
+ *
+ * Description:
+ * CheckHTTPAccessWithToken checks the access of an HTTP request, the
+ * method, path and headers of the request are mapped to access and
+ * resource by http_mappings rules of zpe config, so callers behind a
+ * proxy don't need to build them. The rules are compiled once, and again
+ * when zpe config changes.
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/mapping"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// httpMapper holds the *compiledMappings of http_mappings rules
+	httpMapper atomic.Value
+	// httpMapperLock orders the compilations, so an older config doesn't
+	// replace the mapper of a newer one
+	httpMapperLock sync.Mutex
+)
+
+// compiledMappings is the mapper of http_mappings rules, or the error of
+// the invalid rule, and the zpe config that the rules are read from.
+type compiledMappings struct {
+	mapper     mapping.Mapper
+	err        error
+	properties interface{}
+}
+
+// CheckHTTPAccessWithToken maps the HTTP request to access and resource and
+// checks the access like CheckAccessWithToken. The token is read from the
+// request headers if it's empty. The response has the mapped access and
+// resource too.
+func (permService PermissionService) CheckHTTPAccessWithToken(ctx context.Context,
+	req *v1.HTTPAccessCheckRequest) (*v1.HTTPAccessCheckResponse, error) {
+
+	if req.Method == "" || !strings.HasPrefix(req.Path, "/") {
+		return nil, status.Error(codes.InvalidArgument, "method and absolute path are required")
+	}
+
+	headers := make(map[string]string, len(req.Headers))
+	for name, value := range req.Headers {
+		headers[strings.ToLower(name)] = value
+	}
+	roleToken := req.Token
+	if roleToken == "" {
+		roleToken = headerToken(headers, config.ZpeConfig.Get().ExtAuthz.Header())
+	}
+
+	m, err := mapRequest(mapping.Request{Method: req.Method, Path: req.Path, Headers: headers})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := permService.CheckAccessWithToken(ctx, &v1.AccessCheckRequest{Token: roleToken,
+		Access: m.Action, Resource: m.Resource})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.HTTPAccessCheckResponse{AccessCheckStatus: resp.AccessCheckStatus, Access: m.Action,
+		Resource: m.Resource, StalePolicyMode: resp.StalePolicyMode}, nil
+}
+
+// mapRequest maps the request by http_mappings rules of zpe config, the lower
+// case method and the path are used if no rule matches. The rules are
+// compiled again if zpe config was replaced since they were compiled.
+func mapRequest(request mapping.Request) (mapping.Mapping, error) {
+	compiled, ok := httpMapper.Load().(*compiledMappings)
+	if !ok || compiled.properties != interface{}(config.ZpeConfig.Get()) {
+		compiled = compileHTTPMappings()
+	}
+	if compiled.err != nil {
+		return mapping.Mapping{}, status.Error(codes.Internal, "invalid http_mappings, error: "+compiled.err.Error())
+	}
+	if m, ok := compiled.mapper.Map(request); ok {
+		return m, nil
+	}
+	return mapping.Default(request), nil
+}
+
+// CompileHTTPMappings compiles http_mappings rules of the current zpe config,
+// so the first request after a zpe config change doesn't compile them. The
+// server registers it on zpe config changes.
+func CompileHTTPMappings() {
+	compileHTTPMappings()
+}
+
+// compileHTTPMappings compiles http_mappings rules of the current zpe config
+// and replaces the mapper of mapRequest.
+func compileHTTPMappings() *compiledMappings {
+	httpMapperLock.Lock()
+	defer httpMapperLock.Unlock()
+
+	properties := config.ZpeConfig.Get()
+	mapper, err := mapping.NewMapper(properties.HTTPMappings)
+	compiled := &compiledMappings{mapper: mapper, err: err, properties: properties}
+	httpMapper.Store(compiled)
+	return compiled
+}
+
+// headerToken returns the role token of the header, or the token of
+// authorization header with or without Bearer scheme. Header names must be
+// lower case.
+func headerToken(headers map[string]string, header string) string {
+	if token := headers[header]; token != "" {
+		return token
+	}
+	authorization := headers["authorization"]
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+		return authorization[7:]
+	}
+	return authorization
+}
@@ -0,0 +1,107 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 5:30 AM
+ *
+ * Description:
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/mapping"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestPermissionService_CheckHTTPAccessWithToken(t *testing.T) {
+	a := assert.New(t)
+	err := preparePolicyFiles(time.Now())
+	a.NoError(err)
+	defer os.RemoveAll(testTempFolder)
+
+	files, _ := ioutil.ReadDir(testTempFolder)
+	cache.LoadDB(testTempFolder, files)
+
+	defer setHTTPMappings([]mapping.Rule{
+		{Methods: []string{"GET"}, Path: "/ponds/{county}", Action: "fish", Resource: "angler:stockedpond{county}"},
+		{Path: "/ponds", Action: "manage", Resource: "ponds{county}", Query: map[string]string{"county": "county"}},
+	})()
+
+	tst := PermissionService{}
+	ctx := context.Background()
+	signedToken := createRoleToken("public", "angler")
+
+	resp, err := tst.CheckHTTPAccessWithToken(ctx, &v1.HTTPAccessCheckRequest{Token: signedToken, Method: "GET",
+		Path: "/ponds/BigBassLake"})
+	a.NoError(err)
+	a.Equal(&v1.HTTPAccessCheckResponse{AccessCheckStatus: v1.AccessStatus_ALLOW, Access: "fish",
+		Resource: "angler:stockedpondBigBassLake"}, resp)
+
+	// the token is read from the headers, query variables are extracted
+	resp, err = tst.CheckHTTPAccessWithToken(ctx, &v1.HTTPAccessCheckRequest{Method: "POST",
+		Path: "/ponds?county=KernCounty", Headers: map[string]string{"Athenz-Role-Auth": createRoleToken(
+			"managerkernco", "angler")}})
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_ALLOW, resp.AccessCheckStatus)
+	a.Equal("pondsKernCounty", resp.Resource)
+
+	// requests that don't match any rule use the method and the path
+	resp, err = tst.CheckHTTPAccessWithToken(ctx, &v1.HTTPAccessCheckRequest{Method: "GET", Path: "/stuff?a=1",
+		Headers: map[string]string{"Authorization": "Bearer " + signedToken}})
+	a.NoError(err)
+	a.Equal(&v1.HTTPAccessCheckResponse{AccessCheckStatus: v1.AccessStatus_DENY_NO_MATCH, Access: "get",
+		Resource: "/stuff"}, resp)
+
+	_, err = tst.CheckHTTPAccessWithToken(ctx, &v1.HTTPAccessCheckRequest{Token: signedToken, Method: "GET",
+		Path: "stuff"})
+	a.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestMapRequest(t *testing.T) {
+	a := assert.New(t)
+	defer setHTTPMappings([]mapping.Rule{{Path: "/ponds", Action: "fish", Resource: "ponds"}})()
+	request := mapping.Request{Method: "GET", Path: "/ponds"}
+
+	m, err := mapRequest(request)
+	a.NoError(err)
+	a.Equal(mapping.Mapping{Action: "fish", Resource: "ponds"}, m)
+	compiled := httpMapper.Load()
+
+	// the rules are compiled once per zpe config
+	_, err = mapRequest(request)
+	a.NoError(err)
+	a.Same(compiled, httpMapper.Load())
+
+	// a new zpe config is compiled on the next request
+	defer setHTTPMappings([]mapping.Rule{{Path: "/ponds/{", Action: "fish"}})()
+	_, err = mapRequest(request)
+	a.Equal(codes.Internal, status.Code(err))
+	a.NotSame(compiled, httpMapper.Load())
+}
+
+// setHTTPMappings replaces zpe config by a copy with the rules, like a config
+// reload, and returns a function that restores the previous config.
+func setHTTPMappings(rules []mapping.Rule) func() {
+	previous := config.ZpeConfig.Properties
+	properties := *previous
+	properties.HTTPMappings = rules
+	config.ZpeConfig.Properties = &properties
+	return func() { config.ZpeConfig.Properties = previous }
+}
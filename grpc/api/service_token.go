@@ -0,0 +1,232 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 8:10 PM
+ *
+ * Description:
+ * Role tokens of GetServiceToken are fetched from ZTS by a long-lived
+ * http client and cached in a ServiceTokenCache, so ZTS is not called
+ * on every RPC. Requests for other domains, roles or proxy principals
+ * than the configured ones are checked against token_allow_list of zpe
+ * config, the caller is the common name of its client certificate.
+ *
+ */
+
+package api
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"github.com/hamed-yousefi/athenz-agent/grpc/server"
+	"github.com/yahoo/athenz/clients/go/zts"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ztsRequestTimeout is the timeout of each role token request
+	ztsRequestTimeout = 30 * time.Second
+)
+
+var (
+	serviceTokens = cache.NewServiceTokenCache(fetchServiceToken, func() float64 {
+		return config.ZpeConfig.Get().TokenRefreshFraction
+	})
+
+	ztsClients = new(ztsClientHolder)
+)
+
+type (
+	// ztsClientHolder keeps the ZTS http client and creates it again when
+	// the cert or key file path changes.
+	ztsClientHolder struct {
+		mu       sync.Mutex
+		client   *http.Client
+		certFile string
+		keyFile  string
+	}
+
+	// ztsClientError is returned when the ZTS client can't be created.
+	ztsClientError struct {
+		err error
+	}
+)
+
+// fetchServiceToken gets a role token from ZTS with the service identity
+// certificate of the agent.
+func fetchServiceToken(key cache.ServiceTokenKey) (*cache.ServiceToken, error) {
+	zpeProperties := config.ZpeConfig.Get()
+	httpClient, err := ztsClients.get(zpeProperties.CertFilePath, zpeProperties.KeyFilePath)
+	if err != nil {
+		return nil, ztsClientError{err: err}
+	}
+
+	client := zts.NewClient(downloader.ZtsAPIURL(config.KeyStore.Get().ZtsUrl), httpClient.Transport)
+	client.Timeout = httpClient.Timeout
+
+	minExpiryTime, maxExpiryTime := key.MinExpiry, key.MaxExpiry
+	roleToken, err := client.GetRoleToken(zts.DomainName(key.Domain), zts.EntityList(key.Roles),
+		&minExpiryTime, &maxExpiryTime, zts.EntityName(key.ProxyForPrincipal))
+	if err != nil {
+		return nil, err
+	}
+	return &cache.ServiceToken{Token: roleToken.Token, ExpiryTime: roleToken.ExpiryTime}, nil
+}
+
+// serviceTokenKey creates the cache key of the request. Empty fields are
+// replaced by zpe config values. If the request differs from zpe config, it
+// must be allowed for the caller by token_allow_list.
+func serviceTokenKey(ctx context.Context, req *v1.ServiceTokenRequest) (cache.ServiceTokenKey, error) {
+	domain, roles, maxExpiry, err := authorizeTokenRequest(ctx, "role token", req.Domain, req.Roles,
+		req.ProxyForPrincipal, "")
+	if err != nil {
+		return cache.ServiceTokenKey{}, err
+	}
+
+	zpeProperties := config.ZpeConfig.Get()
+	key := cache.ServiceTokenKey{
+		Domain:            domain,
+		Roles:             roles,
+		MinExpiry:         req.MinExpiryTime,
+		MaxExpiry:         req.MaxExpiryTime,
+		ProxyForPrincipal: req.ProxyForPrincipal,
+	}
+	if key.MinExpiry == 0 {
+		key.MinExpiry = zpeProperties.TokenExpirationMin * 60
+		if maxExpiry > 0 && key.MinExpiry > maxExpiry {
+			key.MinExpiry = maxExpiry
+		}
+	}
+	if key.MaxExpiry == 0 {
+		key.MaxExpiry = limitExpiry(zpeProperties.TokenExpirationMax*60, maxExpiry)
+	}
+
+	if key.MinExpiry < 0 || key.MaxExpiry < 0 {
+		return key, status.Errorf(codes.InvalidArgument, "expiry times must not be negative, values: %d, %d",
+			key.MinExpiry, key.MaxExpiry)
+	}
+	if key.MaxExpiry > 0 && key.MinExpiry > key.MaxExpiry {
+		return key, status.Errorf(codes.InvalidArgument,
+			"min expiry time must not be greater than max expiry time, values: %d, %d", key.MinExpiry, key.MaxExpiry)
+	}
+	if maxExpiry > 0 && (key.MinExpiry > maxExpiry || key.MaxExpiry > maxExpiry) {
+		return key, status.Errorf(codes.PermissionDenied,
+			"expiry times must not be greater than %d seconds of token_allow_list, values: %d, %d", maxExpiry,
+			key.MinExpiry, key.MaxExpiry)
+	}
+	return key, nil
+}
+
+// authorizeTokenRequest replaces empty domain and roles by zpe config values
+// and returns the domain, comma separated roles and the max expiry time of
+// the request in seconds, 0 means no limit. The configured domain and roles
+// are available to all callers, other requests, including id tokens of
+// access tokens, must be allowed for the caller by token_allow_list and are
+// limited by the largest max_expiry_time of the matching rules.
+func authorizeTokenRequest(ctx context.Context, tokenType, domain string, roles []string,
+	proxyForPrincipal, idTokenService string) (string, string, int32, error) {
+	zpeProperties := config.ZpeConfig.Get()
+	configuredRoles := strings.Join(normalizeRoles(strings.Split(zpeProperties.RoleNames, ",")), ",")
+
+	requestedRoles := strings.Join(normalizeRoles(roles), ",")
+	if domain == "" {
+		domain = zpeProperties.DomainName
+	}
+	if requestedRoles == "" && domain == zpeProperties.DomainName {
+		requestedRoles = configuredRoles
+	}
+	if domain == "" {
+		return "", "", 0, status.Error(codes.InvalidArgument, "domain is required")
+	}
+
+	if domain == zpeProperties.DomainName && requestedRoles == configuredRoles && proxyForPrincipal == "" &&
+		idTokenService == "" {
+		return domain, requestedRoles, 0, nil
+	}
+
+	caller := server.CallerName(ctx)
+	var roleList []string
+	if requestedRoles != "" {
+		roleList = strings.Split(requestedRoles, ",")
+	}
+	allowed := false
+	var maxExpiry int32
+	for _, rule := range zpeProperties.TokenAllowList {
+		if !rule.Allows(caller, domain, roleList, proxyForPrincipal, idTokenService) {
+			continue
+		}
+		if !allowed || (maxExpiry > 0 && (rule.MaxExpiryTime == 0 || rule.MaxExpiryTime*60 > maxExpiry)) {
+			maxExpiry = rule.MaxExpiryTime * 60
+		}
+		allowed = true
+	}
+	if allowed {
+		return domain, requestedRoles, maxExpiry, nil
+	}
+	return "", "", 0, status.Errorf(codes.PermissionDenied,
+		"caller '%s' is not allowed to get %s of domain: %s, roles: %s, proxy for principal: %s, "+
+			"id token service: %s", caller, tokenType, domain, requestedRoles, proxyForPrincipal, idTokenService)
+}
+
+// limitExpiry returns the max expiry time if the default expiry time is
+// unlimited or greater than it, 0 max expiry time means no limit.
+func limitExpiry(expiry, maxExpiry int32) int32 {
+	if maxExpiry > 0 && (expiry == 0 || expiry > maxExpiry) {
+		return maxExpiry
+	}
+	return expiry
+}
+
+// normalizeRoles trims, sorts and dedupes role names, so the same roles in
+// different order share a cached token.
+func normalizeRoles(roles []string) []string {
+	normalized := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if role = strings.TrimSpace(role); role != "" {
+			normalized = append(normalized, role)
+		}
+	}
+	sort.Strings(normalized)
+
+	unique := normalized[:0]
+	for i, role := range normalized {
+		if i == 0 || role != normalized[i-1] {
+			unique = append(unique, role)
+		}
+	}
+	return unique
+}
+
+// get returns the ZTS http client of the input cert and key files.
+func (h *ztsClientHolder) get(certFile, keyFile string) (*http.Client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.client != nil && h.certFile == certFile && h.keyFile == keyFile {
+		return h.client, nil
+	}
+	client, err := downloader.NewZtsHTTPClient(certFile, keyFile, ztsRequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	h.client, h.certFile, h.keyFile = client, certFile, keyFile
+	return client, nil
+}
+
+func (e ztsClientError) Error() string {
+	return e.err.Error()
+}
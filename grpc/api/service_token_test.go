@@ -0,0 +1,167 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 9:20 PM
+ *
+ * Description:
+ *
+ */
+
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	tokenZpeConfigPath = "testdata/zpe_token.toml"
+)
+
+// callerContext returns a context of a gRPC call from a client with the
+// input certificate common name.
+func callerContext(commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains: [][]*x509.Certificate{{cert}}}},
+	})
+}
+
+func TestServiceTokenKey(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(config.LoadGlobalZpeConfig(tokenZpeConfigPath))
+	defer func() { a.NoError(config.LoadGlobalZpeConfig(zpeConfigPath)) }()
+
+	// empty request uses zpe config and is allowed for all callers
+	key, err := serviceTokenKey(context.Background(), &v1.ServiceTokenRequest{})
+	a.NoError(err)
+	a.Equal(cache.ServiceTokenKey{Domain: "sports", Roles: "reader,writer", MinExpiry: 600, MaxExpiry: 3600}, key)
+
+	key, err = serviceTokenKey(context.Background(), &v1.ServiceTokenRequest{Domain: "sports",
+		Roles: []string{"writer", " reader", "reader"}, MinExpiryTime: 300, MaxExpiryTime: 900})
+	a.NoError(err)
+	a.Equal(cache.ServiceTokenKey{Domain: "sports", Roles: "reader,writer", MinExpiry: 300, MaxExpiry: 900}, key)
+
+	// other domains must be allowed for the caller
+	key, err = serviceTokenKey(callerContext("sports.frontend"), &v1.ServiceTokenRequest{Domain: "weather",
+		Roles: []string{"reader"}, ProxyForPrincipal: "user.jane"})
+	a.NoError(err)
+	a.Equal(cache.ServiceTokenKey{Domain: "weather", Roles: "reader", MinExpiry: 600, MaxExpiry: 3600,
+		ProxyForPrincipal: "user.jane"}, key)
+
+	_, err = serviceTokenKey(callerContext("sports.backend"), &v1.ServiceTokenRequest{Domain: "weather",
+		Roles: []string{"reader"}})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+	a.Contains(err.Error(), "caller 'sports.backend' is not allowed")
+
+	_, err = serviceTokenKey(callerContext("sports.frontend"), &v1.ServiceTokenRequest{Domain: "weather"})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = serviceTokenKey(context.Background(), &v1.ServiceTokenRequest{Roles: []string{"admin"}})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = serviceTokenKey(context.Background(), &v1.ServiceTokenRequest{ProxyForPrincipal: "user.jane"})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+
+	// "*" caller requires a client certificate
+	_, err = serviceTokenKey(context.Background(), &v1.ServiceTokenRequest{Domain: "news"})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+
+	// expiry times are limited by max_expiry_time of the rule
+	key, err = serviceTokenKey(callerContext("sports.backend"), &v1.ServiceTokenRequest{Domain: "news"})
+	a.NoError(err)
+	a.Equal(cache.ServiceTokenKey{Domain: "news", MinExpiry: 600, MaxExpiry: 1800}, key)
+
+	_, err = serviceTokenKey(callerContext("sports.backend"), &v1.ServiceTokenRequest{Domain: "news",
+		MaxExpiryTime: 3600})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+	a.Contains(err.Error(), "must not be greater than 1800 seconds")
+
+	_, err = serviceTokenKey(context.Background(), &v1.ServiceTokenRequest{MinExpiryTime: 900, MaxExpiryTime: 300})
+	a.Equal(codes.InvalidArgument, status.Code(err))
+
+	_, err = serviceTokenKey(context.Background(), &v1.ServiceTokenRequest{MinExpiryTime: -1})
+	a.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestPermissionService_GetAccessToken(t *testing.T) {
+	a := assert.New(t)
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = r.ParseForm()
+		a.Equal("openid sports:role.reader sports:role.writer sports:service.api", r.PostForm.Get("scope"))
+		a.Equal("3600", r.PostForm.Get("expires_in"))
+		_, _ = w.Write([]byte(`{"access_token":"at","id_token":"it","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	athenzConfig, err := ioutil.ReadFile(athenzConfigPath)
+	a.NoError(err)
+	dir, err := ioutil.TempDir("", "athenz")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	a.NoError(ioutil.WriteFile(dir+"/athenz.json", []byte(strings.Replace(string(athenzConfig),
+		"https://dev.zts.athenzcompany.com:4443/", server.URL, 1)), 0644))
+
+	a.NoError(config.LoadGlobalZpeConfig(tokenZpeConfigPath))
+	a.NoError(config.LoadGlobalAthenzConfig(dir + "/athenz.json"))
+	defer func() {
+		a.NoError(config.LoadGlobalZpeConfig(zpeConfigPath))
+		a.NoError(config.LoadGlobalAthenzConfig(athenzConfigPath))
+	}()
+	defer accessTokens.Flush()
+
+	request := &v1.AccessTokenRequest{IdTokenService: "api"}
+	for i := 0; i < 2; i++ {
+		response, err := PermissionService{}.GetAccessToken(callerContext("sports.backend"), request)
+		a.NoError(err)
+		a.Equal("at", response.AccessToken)
+		a.Equal("it", response.IdToken)
+		a.Equal("Bearer", response.TokenType)
+		a.InDelta(time.Now().Unix()+3600, response.ExpiryTime, 5)
+	}
+	// the second response is cached
+	a.Equal(1, requests)
+
+	_, err = PermissionService{}.GetAccessToken(context.Background(), &v1.AccessTokenRequest{Domain: "weather"})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+	a.Contains(err.Error(), "not allowed to get access token")
+
+	// id tokens of other services must be allowed by token_allow_list
+	_, err = PermissionService{}.GetAccessToken(callerContext("sports.backend"),
+		&v1.AccessTokenRequest{IdTokenService: "db"})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = PermissionService{}.GetAccessToken(callerContext("sports.backend"),
+		&v1.AccessTokenRequest{Domain: "news", ExpiryTime: 3600})
+	a.Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = PermissionService{}.GetAccessToken(context.Background(), &v1.AccessTokenRequest{ExpiryTime: -1})
+	a.Equal(codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,88 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 7:15 PM
+ *
+ * Description:
+ * Expired policies of a domain deny all access checks by default, which
+ * is an outage if ZTS is unreachable for a while. stale_policies rules of
+ * zpe config change it per domain: grace mode evaluates the expired
+ * policies until the grace period ends, and fail_open mode allows the
+ * access of non-critical domains unless a deny assertion matches. Access
+ * checks with expired policies are counted in "stale_policies" expvar by
+ * mode and domain, it's served on /debug/vars of the metrics listener.
+ *
+ */
+
+package api
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	staleLogger = log.GetLogger(common.GolangFileName())
+
+	stalePolicyMetrics = expvar.NewMap("stale_policies")
+	// staleDomains counts the access checks with expired policies by domain
+	staleDomains = new(expvar.Map).Init()
+	// staleWarnings keeps the last logged staleWarning of each domain, so a
+	// warning is logged once per policy file and mode
+	staleWarnings sync.Map
+)
+
+// staleWarning is the expiry and the stale policy mode of a domain.
+type staleWarning struct {
+	expiry int64
+	mode   v1.StalePolicyMode
+}
+
+func init() {
+	stalePolicyMetrics.Set("domains", staleDomains)
+}
+
+// stalePolicyMode returns how an access check uses the policies of the
+// domain that expire at expiry, both in unix nanoseconds.
+func stalePolicyMode(domain string, expiry, now int64) v1.StalePolicyMode {
+	if expiry >= now {
+		return v1.StalePolicyMode_FRESH
+	}
+
+	staleMode := v1.StalePolicyMode_STRICT
+	mode, gracePeriod := config.ZpeConfig.Get().StalePolicy(domain)
+	// policies of a removed policy file have no expiry, they are never used
+	if expiry > 0 {
+		switch mode {
+		case config.StalePolicyGrace:
+			if now-expiry <= gracePeriod.Nanoseconds() {
+				staleMode = v1.StalePolicyMode_GRACE
+			}
+		case config.StalePolicyFailOpen:
+			staleMode = v1.StalePolicyMode_FAIL_OPEN
+		}
+	}
+
+	stalePolicyMetrics.Add(strings.ToLower(staleMode.String()), 1)
+	staleDomains.Add(domain, 1)
+	warning := staleWarning{expiry: expiry, mode: staleMode}
+	if logged, ok := staleWarnings.Load(domain); !ok || logged.(staleWarning) != warning {
+		staleWarnings.Store(domain, warning)
+		staleLogger.Error(fmt.Sprintf("policies of domain %s expired at %s, stale policy mode: %s", domain,
+			time.Unix(0, expiry).UTC().Format(time.RFC3339), staleMode.String()))
+	}
+	return staleMode
+}
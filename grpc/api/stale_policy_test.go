@@ -0,0 +1,139 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 7:50 PM
+ *
+ * Description:
+ *
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"expvar"
+	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/grpc/server"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStalePolicyMode(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(preparePolicyFiles(time.Time{}))
+	defer os.RemoveAll(testTempFolder)
+
+	config.ZpeConfig.Properties.StalePolicies = []config.StalePolicyRule{
+		{Domain: "sports.*", Mode: config.StalePolicyGrace, GracePeriod: 2},
+		{Domain: "weather", Mode: config.StalePolicyFailOpen},
+	}
+	defer func() { config.ZpeConfig.Properties.StalePolicies = nil }()
+
+	grace, strict, failOpen := counter(stalePolicyMetrics, "grace"), counter(stalePolicyMetrics, "strict"),
+		counter(stalePolicyMetrics, "fail_open")
+	sportsNba, weather := counter(staleDomains, "sports.nba"), counter(staleDomains, "weather")
+
+	now := time.Now()
+	expired := now.Add(-time.Hour).UnixNano()
+	a.Equal(v1.StalePolicyMode_FRESH, stalePolicyMode("sports.nba", now.Add(time.Hour).UnixNano(), now.UnixNano()))
+	a.Equal(v1.StalePolicyMode_GRACE, stalePolicyMode("sports.nba", expired, now.UnixNano()))
+	a.Equal(v1.StalePolicyMode_STRICT, stalePolicyMode("sports.nba", now.Add(-3*time.Hour).UnixNano(),
+		now.UnixNano()))
+	a.Equal(v1.StalePolicyMode_FAIL_OPEN, stalePolicyMode("weather", expired, now.UnixNano()))
+	a.Equal(v1.StalePolicyMode_STRICT, stalePolicyMode("news", expired, now.UnixNano()))
+	// policies of removed policy files are never used
+	a.Equal(v1.StalePolicyMode_STRICT, stalePolicyMode("weather", 0, now.UnixNano()))
+
+	// fresh policies are not counted
+	a.Equal(grace+1, counter(stalePolicyMetrics, "grace"))
+	a.Equal(strict+3, counter(stalePolicyMetrics, "strict"))
+	a.Equal(failOpen+1, counter(stalePolicyMetrics, "fail_open"))
+	a.Equal(sportsNba+2, counter(staleDomains, "sports.nba"))
+	a.Equal(weather+2, counter(staleDomains, "weather"))
+
+	// the counters are served by the metrics listener
+	metricsServer := httptest.NewServer(server.NewMetricsHandler())
+	defer metricsServer.Close()
+	resp, err := http.Get(metricsServer.URL + "/debug/vars")
+	if a.NoError(err) {
+		defer resp.Body.Close()
+		var metrics struct {
+			StalePolicies struct {
+				Grace   int64            `json:"grace"`
+				Domains map[string]int64 `json:"domains"`
+			} `json:"stale_policies"`
+		}
+		a.NoError(json.NewDecoder(resp.Body).Decode(&metrics))
+		a.Equal(grace+1, metrics.StalePolicies.Grace)
+		a.Equal(weather+2, metrics.StalePolicies.Domains["weather"])
+	}
+}
+
+// counter returns the value of a counter of the map, 0 if it's not set.
+func counter(m *expvar.Map, key string) int64 {
+	if value, ok := m.Get(key).(*expvar.Int); ok {
+		return value.Value()
+	}
+	return 0
+}
+
+func TestPermissionService_CheckAccessWithTokenStalePolicy(t *testing.T) {
+	a := assert.New(t)
+	// the policies of angler domain expired an hour ago
+	a.NoError(preparePolicyFiles(time.Now().Add(-49 * time.Hour)))
+	defer os.RemoveAll(testTempFolder)
+	a.NoError(cache.LoadDirectory(testTempFolder))
+	defer func() { config.ZpeConfig.Properties.StalePolicies = nil }()
+
+	tst := PermissionService{}
+	ctx := context.Background()
+	signedToken := createRoleToken("public", "angler")
+	allowed := &v1.AccessCheckRequest{Access: "read", Resource: "angler:stuff", Token: signedToken}
+	denied := &v1.AccessCheckRequest{Access: "throw", Resource: "angler:stuff", Token: signedToken}
+	noMatch := &v1.AccessCheckRequest{Access: "fly", Resource: "angler:stuff", Token: signedToken}
+
+	status, err := tst.CheckAccessWithToken(ctx, allowed)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_EXPIRED, status.AccessCheckStatus)
+	a.Equal(v1.StalePolicyMode_STRICT, status.StalePolicyMode)
+
+	config.ZpeConfig.Properties.StalePolicies = []config.StalePolicyRule{
+		{Domain: "angler", Mode: config.StalePolicyGrace, GracePeriod: 2}}
+	status, err = tst.CheckAccessWithToken(ctx, allowed)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_ALLOW, status.AccessCheckStatus)
+	a.Equal(v1.StalePolicyMode_GRACE, status.StalePolicyMode)
+	status, err = tst.CheckAccessWithToken(ctx, denied)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY, status.AccessCheckStatus)
+	a.Equal(v1.StalePolicyMode_GRACE, status.StalePolicyMode)
+
+	status, err = tst.CheckAccessWithToken(ctx, noMatch)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_NO_MATCH, status.AccessCheckStatus)
+
+	// fail open allows the checks that no deny assertion matches
+	config.ZpeConfig.Properties.StalePolicies = []config.StalePolicyRule{
+		{Domain: "angler", Mode: config.StalePolicyFailOpen}}
+	status, err = tst.CheckAccessWithToken(ctx, noMatch)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_ALLOW, status.AccessCheckStatus)
+	a.Equal(v1.StalePolicyMode_FAIL_OPEN, status.StalePolicyMode)
+	status, err = tst.CheckAccessWithToken(ctx, denied)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY, status.AccessCheckStatus)
+	a.Equal(v1.StalePolicyMode_FAIL_OPEN, status.StalePolicyMode)
+}
@@ -23,20 +23,15 @@
 package api
 
 import (
-	"crypto/tls"
 	"github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
 	"github.com/hamed-yousefi/athenz-agent/cache"
 	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/hamed-yousefi/athenz-agent/config"
 	"github.com/hamed-yousefi/athenz-agent/matcher"
 	"github.com/hamed-yousefi/athenz-agent/token"
-	"github.com/yahoo/athenz/clients/go/zts"
-	"github.com/yahoo/athenz/libs/go/zmssvctoken"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"io/ioutil"
-	"net/http"
 	"reflect"
 	"strings"
 	"time"
@@ -55,14 +50,16 @@ const (
 	DenyNoMatch           = 7
 	DenyDomainEmpty       = 8
 	DenyDomainExpired     = 9
+	DenyDomainLoading     = 10
 )
 
 // We will implement gRPC PermissionServer
 // interface for this struct to use it in
 // gRPC server.
-// This interface has two method:
+// This interface has three method:
 // 		* CheckAccessWithToken
 //      * GetServiceToken
+//      * GetAccessToken
 type PermissionService struct{}
 
 // This method implements one of PermissionServer
@@ -71,13 +68,13 @@ type PermissionService struct{}
 // access and resource that roleToken wants to use.
 // This method will return a AccessCheckResponse
 // type that contains an access number between 0
-// and 9.
+// and 10.
 func (permService PermissionService) CheckAccessWithToken(ctx context.Context,
 	req *v1.AccessCheckRequest) (*v1.AccessCheckResponse, error) {
 
 	// first try to get RoleToken from
 	// cached RoleTokens
-	roleToken, ok := cache.RoleTokenCacheMap[req.Token]
+	roleToken, ok := cache.GetRoleToken(req.Token)
 	if !ok {
 		// this is first time that we trying to create
 		// this rToken, so we will cache it after
@@ -88,9 +85,11 @@ func (permService PermissionService) CheckAccessWithToken(ctx context.Context,
 		}
 
 		// validate the rToken
-		pubKey := config.KeyStore.GetZtsPublicKey(rToken.KeyId)
-		ztsKey, err := new(zmssvctoken.YBase64).DecodeString(pubKey)
-		isValid, err := rToken.Validate(string(ztsKey), config.ZpeConfig.Properties.AllowedOffset, false)
+		verifier, err := config.KeyStore.GetZtsVerifier(rToken.KeyId)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "token validation failed, error: "+err.Error())
+		}
+		isValid, err := rToken.ValidateWithVerifier(verifier, config.ZpeConfig.Get().AllowedOffset, false)
 		if err != nil {
 			return nil, status.Error(codes.InvalidArgument, "token validation failed, error: "+err.Error())
 		}
@@ -107,26 +106,27 @@ func (permService PermissionService) CheckAccessWithToken(ctx context.Context,
 			return &v1.AccessCheckResponse{AccessCheckStatus: DenyRoleTokenInvalid}, nil
 		}
 
-		cache.RoleTokenCacheMap[req.Token] = rToken
+		cache.PutRoleToken(req.Token, rToken)
 	} else {
 		// check the cached token expiration
 		// if it was expired remove it from
 		// cached tokens
 		now := common.CurrentTimeMillis()
 		if roleToken.ExpiryTime != 0 && (roleToken.ExpiryTime/int64(time.Millisecond)) < now {
-			delete(cache.RoleTokenCacheMap, req.Token)
+			cache.DeleteRoleToken(req.Token)
 			return &v1.AccessCheckResponse{AccessCheckStatus: DenyRoleTokenExpired}, nil
 		}
 	}
 
-	return allowAction(req.Access, req.Resource, roleToken.Domain, roleToken.RoleNames)
+	return allowActionOnDemand(ctx, req.Access, req.Resource, roleToken.Domain, roleToken.RoleNames)
 }
 
 // This method implements one of PermissionServer.
 // GetServiceToken accept a struct
-// named ServiceTokenRequest that is a empty struct
-// used in gRPC request message. This method will
-// return ServiceTokenResponse type that contains
+// named ServiceTokenRequest that contains optional
+// domain, roles, expiry times and proxy principal,
+// empty fields are read from zpe config. This method
+// will return ServiceTokenResponse type that contains
 // a token string.
 // There are three ways to getting roleToken from
 // ZTS server:
@@ -144,28 +144,58 @@ func (permService PermissionService) CheckAccessWithToken(ctx context.Context,
 func (permService PermissionService) GetServiceToken(ctx context.Context,
 	req *v1.ServiceTokenRequest) (*v1.ServiceTokenResponse, error) {
 
-	// load ZTS server TLS config
-	tlsConfig, err := getTLSConfigFromFiles(config.ZpeConfig.Properties.KeyFilePath, config.ZpeConfig.Properties.CertFilePath)
+	key, err := serviceTokenKey(ctx, req)
 	if err != nil {
-		return nil, status.Error(codes.Internal, "unable to load TLS Config, error: "+err.Error())
+		return nil, err
 	}
 
-	minExpiryTime := config.ZpeConfig.Properties.TokenExpirationMin * 60
-	maxExpiryTime := config.ZpeConfig.Properties.TokenExpirationMax * 60
-
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
-	client := zts.NewClient(config.KeyStore.Properties.ZtsUrl, transport)
-
-	roleToken, err := client.GetRoleToken(zts.DomainName(config.ZpeConfig.Properties.DomainName),
-		zts.EntityList(config.ZpeConfig.Properties.RoleNames), &minExpiryTime, &maxExpiryTime, "")
+	// the token is fetched from ZTS only if there is no
+	// cached token or it's going to be expired
+	roleToken, err := serviceTokens.Get(key)
 	if err != nil {
+		if _, ok := err.(ztsClientError); ok {
+			return nil, status.Error(codes.Internal, "unable to load TLS Config, error: "+err.Error())
+		}
 		return nil, status.Error(codes.InvalidArgument, "unable to get roleToken, error: "+err.Error())
 	}
 
 	return &v1.ServiceTokenResponse{Token: roleToken.Token}, nil
 }
 
-func allowAction(action, resource, domain string, roles []string) (*v1.AccessCheckResponse, error) {
+// This method implements one of PermissionServer.
+// GetAccessToken accept a struct named AccessTokenRequest
+// that contains optional domain, roles, expiry time, ID
+// token service and proxy principal. It gets an OAuth2
+// access token from ZTS token endpoint using athenz
+// service identity certificate, the same way as
+// GetServiceToken, and returns AccessTokenResponse type.
+func (permService PermissionService) GetAccessToken(ctx context.Context,
+	req *v1.AccessTokenRequest) (*v1.AccessTokenResponse, error) {
+
+	key, err := accessTokenKey(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := accessTokens.Get(key)
+	if err != nil {
+		if _, ok := err.(ztsClientError); ok {
+			return nil, status.Error(codes.Internal, "unable to load TLS Config, error: "+err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, "unable to get accessToken, error: "+err.Error())
+	}
+
+	return &v1.AccessTokenResponse{
+		AccessToken: accessToken.Token,
+		IdToken:     accessToken.IDToken,
+		TokenType:   accessToken.TokenType,
+		ExpiryTime:  accessToken.ExpiryTime,
+	}, nil
+}
+
+// allowAction checks the access of the roles by the cached policies at now,
+// in unix nanoseconds. Policies that expire before now are stale.
+func allowAction(action, resource, domain string, roles []string, now int64) (*v1.AccessCheckResponse, error) {
 
 	// check parameters to not be empty
 	if roles == nil || len(roles) == 0 {
@@ -196,7 +226,29 @@ func allowAction(action, resource, domain string, roles []string) (*v1.AccessChe
 		return &v1.AccessCheckResponse{AccessCheckStatus: DenyDomainMismatch}, nil
 	}
 
-	now := time.Now().UnixNano()
+	// all policy maps of a domain have the expiry of its policy file
+	staleMode := v1.StalePolicyMode_FRESH
+	if roleMap, ok := cache.DomainStandardRoleAllowMap[domain]; ok {
+		staleMode = stalePolicyMode(domain, roleMap.Expiry, now)
+	}
+	switch staleMode {
+	case v1.StalePolicyMode_STRICT:
+		return &v1.AccessCheckResponse{AccessCheckStatus: DenyDomainExpired, StalePolicyMode: staleMode}, nil
+	case v1.StalePolicyMode_FAIL_OPEN:
+		// deny assertions of the expired policies still deny the access
+		if accessStatus := matchPolicies(action, resource, domain, roles); accessStatus == v1.AccessStatus_DENY {
+			return &v1.AccessCheckResponse{AccessCheckStatus: accessStatus, StalePolicyMode: staleMode}, nil
+		}
+		return &v1.AccessCheckResponse{AccessCheckStatus: Allow, StalePolicyMode: staleMode}, nil
+	}
+
+	return &v1.AccessCheckResponse{AccessCheckStatus: matchPolicies(action, resource, domain, roles),
+		StalePolicyMode: staleMode}, nil
+}
+
+// matchPolicies checks the deny and then the allow assertions of the
+// domain policies for the roles, action and resource.
+func matchPolicies(action, resource, domain string, roles []string) v1.AccessStatus {
 
 	var accessStatus int32
 	accessStatus = DenyDomainNotFound
@@ -204,12 +256,9 @@ func allowAction(action, resource, domain string, roles []string) (*v1.AccessChe
 	// first hunt by role for deny assertions since
 	// deny takes precedence over allow assertions
 	roleMap, ok := cache.DomainStandardRoleDenyMap[domain]
-	if ok && roleMap.Expiry < now {
-		return &v1.AccessCheckResponse{AccessCheckStatus: DenyDomainExpired}, nil
-	}
 	if ok && len(roleMap.RoleDataMap) > 0 {
 		if actionByRole(action, resource, roles, roleMap.RoleDataMap) {
-			return &v1.AccessCheckResponse{AccessCheckStatus: Deny}, nil
+			return Deny
 		} else {
 			accessStatus = DenyNoMatch
 		}
@@ -221,12 +270,9 @@ func allowAction(action, resource, domain string, roles []string) (*v1.AccessChe
 	// standard role, then let's process our wildcard
 	// roles for deny assertions
 	roleMap, ok = cache.DomainWildcardRoleDenyMap[domain]
-	if ok && roleMap.Expiry < now {
-		return &v1.AccessCheckResponse{AccessCheckStatus: DenyDomainExpired}, nil
-	}
 	if ok && len(roleMap.RoleDataMap) > 0 {
 		if actionByWildCardRole(action, resource, roles, roleMap.RoleDataMap) {
-			return &v1.AccessCheckResponse{AccessCheckStatus: Deny}, nil
+			return Deny
 		} else {
 			accessStatus = DenyNoMatch
 		}
@@ -237,12 +283,9 @@ func allowAction(action, resource, domain string, roles []string) (*v1.AccessChe
 	// so far it did not match any deny assertions so now let's
 	// process our allow assertions
 	roleMap, ok = cache.DomainStandardRoleAllowMap[domain]
-	if ok && roleMap.Expiry < now {
-		return &v1.AccessCheckResponse{AccessCheckStatus: DenyDomainExpired}, nil
-	}
 	if ok && len(roleMap.RoleDataMap) > 0 {
 		if actionByRole(action, resource, roles, roleMap.RoleDataMap) {
-			return &v1.AccessCheckResponse{AccessCheckStatus: Allow}, nil
+			return Allow
 		} else {
 			accessStatus = DenyNoMatch
 		}
@@ -253,12 +296,9 @@ func allowAction(action, resource, domain string, roles []string) (*v1.AccessChe
 	// at this point we either got an allow or didn't match anything so we're
 	// going to try the wildcard roles
 	roleMap, ok = cache.DomainWildcardRoleAllowMap[domain]
-	if ok && roleMap.Expiry < now {
-		return &v1.AccessCheckResponse{AccessCheckStatus: DenyDomainExpired}, nil
-	}
 	if ok && len(roleMap.RoleDataMap) > 0 {
 		if actionByWildCardRole(action, resource, roles, roleMap.RoleDataMap) {
-			return &v1.AccessCheckResponse{AccessCheckStatus: Allow}, nil
+			return Allow
 		} else {
 			accessStatus = DenyNoMatch
 		}
@@ -266,7 +306,16 @@ func allowAction(action, resource, domain string, roles []string) (*v1.AccessChe
 		accessStatus = DenyDomainEmpty
 	}
 
-	return &v1.AccessCheckResponse{AccessCheckStatus: v1.AccessStatus(accessStatus)}, nil
+	return v1.AccessStatus(accessStatus)
+}
+
+// EvaluateAccess checks the access of the roles of the domain to the
+// resource by the cached policies, like CheckAccessWithToken does after the
+// role token is validated. now is the evaluation time in unix nanoseconds,
+// policies that expire before it are stale.
+func EvaluateAccess(action, resource, domain string, roles []string, now int64) v1.AccessStatus {
+	resp, _ := allowAction(action, resource, domain, roles, now)
+	return resp.AccessCheckStatus
 }
 
 func actionByRole(action, resource string, roles []string,
@@ -362,35 +411,3 @@ func actionByWildCardRole(action, resource string, roles []string,
 
 	return false
 }
-
-// accept keyFile and certFile address and read content
-// in byte array format and pass them getTLSConfig to
-// create tls config
-func getTLSConfigFromFiles(keyFilePath, certFilePath string) (*tls.Config, error) {
-	keyPem, err := ioutil.ReadFile(keyFilePath)
-	if err != nil {
-		return nil, common.Errorf("unable to read keyFile, error: %s", err.Error())
-	}
-
-	certPem, err := ioutil.ReadFile(certFilePath)
-	if err != nil {
-		return nil, common.Errorf("unable to read certFile, error: %s", err.Error())
-	}
-
-	return getTLSConfig(keyPem, certPem)
-}
-
-// use key and cert to create tls config
-func getTLSConfig(keyPem, certPem []byte) (*tls.Config, error) {
-	clientCert, err := tls.X509KeyPair(certPem, keyPem)
-	if err != nil {
-		return nil, common.Errorf("unable to formulate clientCert "+
-			"from keyPem and certPem, error: %s", err.Error())
-	}
-
-	tlsConfig := &tls.Config{}
-	tlsConfig.Certificates = make([]tls.Certificate, 1)
-	tlsConfig.Certificates[0] = clientCert
-
-	return tlsConfig, nil
-}
@@ -175,8 +175,7 @@ func TestPermissionService_CheckAccessWithTokenPolicyFileExpired(t *testing.T) {
 	}()
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("public", "angler")
 
@@ -196,8 +195,7 @@ func TestPermissionService_CheckAccessWithTokenAllow(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("public", "angler")
 
@@ -219,8 +217,7 @@ func TestPermissionService_CheckAccessWithTokenDeny(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("public", "angler")
 
@@ -242,8 +239,7 @@ func TestPermissionService_CheckAccessWithTokenStartWith(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("public", "angler")
 
@@ -265,8 +261,7 @@ func TestPermissionService_CheckAccessWithTokenWildcardDeny(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("managerkernco", "angler")
 
@@ -288,8 +283,7 @@ func TestPermissionService_CheckAccessWithTokenWildcardAllow(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("managerkernco", "angler")
 
@@ -311,8 +305,7 @@ func TestPermissionService_CheckAccessWithTokenMatchAllAllow(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("matchall", "angler")
 
@@ -334,8 +327,7 @@ func TestPermissionService_CheckAccessWithTokenMatchRegexAllow(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("matchregex", "angler")
 
@@ -357,8 +349,7 @@ func TestPermissionService_CheckAccessWithTokenFullRegexAllow1(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("full_regex", "angler")
 
@@ -380,8 +371,7 @@ func TestPermissionService_CheckAccessWithTokenFullRegexAllow2(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("full_regex", "angler")
 
@@ -403,8 +393,7 @@ func TestPermissionService_CheckAccessWithTokenFullRegexAllow3(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("full_regex", "angler")
 
@@ -426,8 +415,7 @@ func TestPermissionService_CheckAccessWithTokenFullRegexAllow4(t *testing.T) {
 	a.NoError(err)
 
 	files, _ := ioutil.ReadDir(testTempFolder)
-	cache.PolicyDirectory = testTempFolder
-	cache.LoadDB(files)
+	cache.LoadDB(testTempFolder, files)
 
 	signedToken := createRoleToken("full_regex", "angler")
 
@@ -0,0 +1,221 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 6:25 AM
+ *
+ * Description:
+ * decisionCache keeps the access decisions of the agent for a TTL. The
+ * keys are hashes of the requests, so role tokens are not kept in memory.
+ * Entries older than TTL are only returned as fallback while the agent is
+ * unavailable, and they are removed when they are older than both. An
+ * entry is never returned after the expiry time of its token, the "e="
+ * field of a role token or the "exp" claim of a JWT access token. Decisions
+ * of JWT access tokens without expiry are not cached.
+ *
+ */
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/clock"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	decisionCache struct {
+		mu         sync.Mutex
+		ttl        time.Duration
+		fallback   time.Duration
+		maxEntries int
+		entries    map[string]decision
+		clock      clock.Clock
+	}
+
+	decision struct {
+		response  interface{}
+		createdAt time.Time
+		// tokenExpiry is the expiry time of the token, zero if it's unknown
+		tokenExpiry time.Time
+	}
+
+	// jwtClaims are the claims of a JWT access token that the cache needs.
+	jwtClaims struct {
+		Exp *float64 `json:"exp"`
+	}
+)
+
+func newDecisionCache(ttl, fallback time.Duration, maxEntries int) *decisionCache {
+	return &decisionCache{ttl: ttl, fallback: fallback, maxEntries: maxEntries, entries: make(map[string]decision),
+		clock: clock.Real}
+}
+
+// key returns the key of an access check. A nil cache has no key.
+func (c *decisionCache) key(fields ...string) string {
+	if c == nil {
+		return ""
+	}
+	hash := sha256.New()
+	for _, field := range fields {
+		hash.Write([]byte(field))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// httpKey returns the key of an HTTP access check, headers are sorted.
+func (c *decisionCache) httpKey(req *msg.HTTPAccessCheckRequest) string {
+	if c == nil {
+		return ""
+	}
+	fields := []string{"http", req.Token, req.Method, req.Path}
+	names := make([]string, 0, len(req.Headers))
+	for name := range req.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, name, req.Headers[name])
+	}
+	return c.key(fields...)
+}
+
+// get returns the cached response of the key if it's not older than TTL, or
+// not older than the fallback age if stale is set. Responses of expired
+// role tokens are not returned.
+func (c *decisionCache) get(key string, stale bool) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	maxAge := c.ttl
+	if stale && c.fallback > maxAge {
+		maxAge = c.fallback
+	}
+	now := c.clock.Now()
+	if now.Sub(entry.createdAt) > maxAge || entry.tokenExpired(now) {
+		return nil, false
+	}
+	return entry.response, true
+}
+
+// put caches the response of the role token that expires at tokenExpiry. If
+// the cache is full, the expired entries are removed and the response is not
+// cached if it's still full.
+func (c *decisionCache) put(key string, response interface{}, tokenExpiry time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.removeExpired()
+		if len(c.entries) >= c.maxEntries {
+			return
+		}
+	}
+	c.entries[key] = decision{response: response, createdAt: c.clock.Now(), tokenExpiry: tokenExpiry}
+}
+
+// removeExpired removes the entries that are older than TTL and fallback
+// age, or whose role token is expired. It must be called with mu held.
+func (c *decisionCache) removeExpired() {
+	maxAge := c.ttl
+	if c.fallback > maxAge {
+		maxAge = c.fallback
+	}
+	now := c.clock.Now()
+	for key, entry := range c.entries {
+		if now.Sub(entry.createdAt) > maxAge || entry.tokenExpired(now) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// tokenExpired checks the token of the decision is expired at now.
+func (d decision) tokenExpired(now time.Time) bool {
+	return !d.tokenExpiry.IsZero() && !now.Before(d.tokenExpiry)
+}
+
+// tokenExpiry returns the expiry time of a token, the "e=" field of a role
+// token in unix seconds or the "exp" claim of a JWT access token. It returns
+// zero time if a role token has no expiry, and false if a JWT has no "exp"
+// claim, so its decision must not be cached.
+func tokenExpiry(token string) (time.Time, bool) {
+	if claims, ok := parseJWT(token); ok {
+		if claims.Exp == nil {
+			return time.Time{}, false
+		}
+		return time.Unix(int64(*claims.Exp), 0), true
+	}
+	for _, field := range strings.Split(token, ";") {
+		if strings.HasPrefix(field, "e=") {
+			if seconds, err := strconv.ParseInt(field[2:], 10, 64); err == nil {
+				return time.Unix(seconds, 0), true
+			}
+		}
+	}
+	return time.Time{}, true
+}
+
+// parseJWT returns the claims of token if it's a JWT, a JSON header and
+// payload. The signature is not verified, the agent does it.
+func parseJWT(token string) (jwtClaims, bool) {
+	var claims jwtClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, false
+	}
+	var header map[string]interface{}
+	if data, err := base64.RawURLEncoding.DecodeString(parts[0]); err != nil || json.Unmarshal(data, &header) != nil {
+		return claims, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(data, &claims) != nil {
+		// an unreadable payload has no known expiry
+		return jwtClaims{}, true
+	}
+	return claims, true
+}
+
+// httpTokenExpiry returns the expiry time of the token of the request, the
+// token field or the earliest expiry of the tokens in the headers. It returns
+// false if one of them is a JWT without expiry.
+func httpTokenExpiry(req *msg.HTTPAccessCheckRequest) (time.Time, bool) {
+	if req.Token != "" {
+		return tokenExpiry(req.Token)
+	}
+	var expiry time.Time
+	for _, value := range req.Headers {
+		if len(value) > 7 && strings.EqualFold(value[:7], "Bearer ") {
+			value = value[7:]
+		}
+		e, ok := tokenExpiry(value)
+		if !ok {
+			return time.Time{}, false
+		}
+		if !e.IsZero() && (expiry.IsZero() || e.Before(expiry)) {
+			expiry = e
+		}
+	}
+	return expiry, true
+}
@@ -0,0 +1,494 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 6:00 AM
+ *
+ * Description:
+ * Client is the Go SDK of athenz-agent. It's created once and shared by
+ * the goroutines of a service: it keeps a pool of gRPC connections over
+ * TCP, TLS or a unix socket, applies a timeout to each attempt, retries
+ * the calls that fail with Unavailable and caches access decisions for a
+ * TTL. Cached decisions can be returned after their TTL while the agent
+ * is unavailable. Failed calls return *Error.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	ac "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/command/v1"
+	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTimeout  = 5 * time.Second
+	defaultAttempts = 3
+	defaultBackoff  = 100 * time.Millisecond
+	// secretMetadataKey is the metadata key of the shared secret of server
+	// authorization rules
+	secretMetadataKey = "x-athenz-agent-secret"
+)
+
+type (
+	// Client calls athenz-agent RPCs. It's safe for concurrent use.
+	Client interface {
+		// CheckAccess checks the access of the role token to the resource.
+		CheckAccess(ctx context.Context, token, access, resource string) (msg.AccessStatus, error)
+		// CheckHTTPAccess checks the access of the HTTP request, it's mapped
+		// to access and resource by the agent.
+		CheckHTTPAccess(ctx context.Context, req *msg.HTTPAccessCheckRequest) (*msg.HTTPAccessCheckResponse, error)
+		// GetServiceToken returns a role token of the agent service.
+		GetServiceToken(ctx context.Context, req *msg.ServiceTokenRequest) (string, error)
+		// GetAccessToken returns an access token of the agent service.
+		GetAccessToken(ctx context.Context, req *msg.AccessTokenRequest) (*msg.AccessTokenResponse, error)
+		// ListDomains returns the load status of the policy files of the
+		// agent, it's an admin RPC.
+		ListDomains(ctx context.Context) ([]*msg.DomainStatus, error)
+		// RefreshPolicies downloads and loads the policy files of the domains,
+		// empty domains refresh all domains of the agent. Downloads may take
+		// longer than the default timeout, it's an admin RPC.
+		RefreshPolicies(ctx context.Context, domains []string) ([]*msg.DomainStatus, error)
+		// ReloadCache verifies and loads all policy files again, it's an
+		// admin RPC.
+		ReloadCache(ctx context.Context) ([]*msg.DomainStatus, error)
+		// FlushTokenCache removes the cached role tokens of the agent and
+		// returns their number, it's an admin RPC.
+		FlushTokenCache(ctx context.Context) (int, error)
+		// GetStatus returns the status of the agent, it's an admin RPC.
+		GetStatus(ctx context.Context) (*msg.StatusResponse, error)
+		// Close closes the connections, calls of a closed client fail.
+		Close() error
+	}
+
+	// Option configures the client.
+	Option func(*options) error
+
+	options struct {
+		unixSocket string
+		tlsConfig  *tls.Config
+		serverName string
+		timeout    time.Duration
+		attempts   int
+		backoff    time.Duration
+		poolSize   int
+		secret     string
+		cacheTTL   time.Duration
+		// fallback is the max age of cached decisions that are returned
+		// while the agent is unavailable
+		fallback   time.Duration
+		maxEntries int
+	}
+
+	client struct {
+		options
+		conns  []*grpc.ClientConn
+		next   uint32
+		closed int32
+		cache  *decisionCache
+	}
+)
+
+// WithUnixSocket connects to the unix socket of the agent instead of the
+// address.
+func WithUnixSocket(path string) Option {
+	return func(o *options) error {
+		o.unixSocket = path
+		return nil
+	}
+}
+
+// WithTLSConfig uses TLS with the config.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *options) error {
+		o.tlsConfig = config
+		return nil
+	}
+}
+
+// WithTLSFiles uses mutual TLS with the client certificate and key files. The
+// server certificate is verified by the CA file, or by the system roots if
+// caFile is empty.
+func WithTLSFiles(certFile, keyFile, caFile string) Option {
+	return func(o *options) error {
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return common.Errorf("unable to load client certificate, error: %s", err.Error())
+		}
+		o.tlsConfig = &tls.Config{Certificates: []tls.Certificate{certificate}}
+		if caFile == "" {
+			return nil
+		}
+		caPem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return common.Errorf("unable to read ca file, error: %s", err.Error())
+		}
+		o.tlsConfig.RootCAs = x509.NewCertPool()
+		if !o.tlsConfig.RootCAs.AppendCertsFromPEM(caPem) {
+			return common.Errorf("unable to append ca cert: %s", caFile)
+		}
+		return nil
+	}
+}
+
+// WithServerName sets the name that the agent certificate is verified
+// against, the host of the address is used by default. It needs TLS.
+func WithServerName(name string) Option {
+	return func(o *options) error {
+		o.serverName = name
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of each call attempt, default is 5s.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) error {
+		if timeout <= 0 {
+			return common.Errorf("timeout must be positive, value: %s", timeout)
+		}
+		o.timeout = timeout
+		return nil
+	}
+}
+
+// WithRetry sets the number of attempts of the calls that fail with
+// Unavailable and the backoff before the first retry, it's doubled after
+// each retry. Default is 3 attempts and 100ms.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(o *options) error {
+		if attempts < 1 {
+			return common.Errorf("attempts must be positive, value: %d", attempts)
+		}
+		o.attempts, o.backoff = attempts, backoff
+		return nil
+	}
+}
+
+// WithPoolSize sets the number of connections, calls are spread over them
+// round robin. Default is 1, a connection multiplexes concurrent calls.
+func WithPoolSize(size int) Option {
+	return func(o *options) error {
+		if size < 1 {
+			return common.Errorf("pool size must be positive, value: %d", size)
+		}
+		o.poolSize = size
+		return nil
+	}
+}
+
+// WithSecret sends the shared secret of server authorization rules.
+func WithSecret(secret string) Option {
+	return func(o *options) error {
+		o.secret = secret
+		return nil
+	}
+}
+
+// WithCache caches up to maxEntries access decisions for ttl, 0 maxEntries
+// means no limit. Decisions are only cached if this option is set, and
+// never used after their role token expires. Decisions of JWT access tokens
+// without an "exp" claim are not cached.
+func WithCache(ttl time.Duration, maxEntries int) Option {
+	return func(o *options) error {
+		if ttl <= 0 {
+			return common.Errorf("cache ttl must be positive, value: %s", ttl)
+		}
+		if maxEntries < 0 {
+			return common.Errorf("cache max entries must not be negative, value: %d", maxEntries)
+		}
+		o.cacheTTL, o.maxEntries = ttl, maxEntries
+		return nil
+	}
+}
+
+// WithFallback returns cached decisions up to maxAge old when the agent is
+// unavailable, it needs WithCache. Decisions of expired role tokens are not
+// returned.
+func WithFallback(maxAge time.Duration) Option {
+	return func(o *options) error {
+		if maxAge <= 0 {
+			return common.Errorf("fallback max age must be positive, value: %s", maxAge)
+		}
+		o.fallback = maxAge
+		return nil
+	}
+}
+
+// New creates a Client of the agent at address, e.g. "127.0.0.1:9090".
+// Connections are established in background, so New doesn't fail if the
+// agent is down.
+func New(address string, opts ...Option) (Client, error) {
+	c := &client{options: options{timeout: defaultTimeout, attempts: defaultAttempts, backoff: defaultBackoff,
+		poolSize: 1}}
+	for _, opt := range opts {
+		if err := opt(&c.options); err != nil {
+			return nil, err
+		}
+	}
+	if c.fallback > 0 && c.cacheTTL == 0 {
+		return nil, common.Error("fallback needs cache")
+	}
+	if c.cacheTTL > 0 {
+		c.cache = newDecisionCache(c.cacheTTL, c.fallback, c.maxEntries)
+	}
+
+	dialOptions := []grpc.DialOption{grpc.WithInsecure()}
+	if c.tlsConfig != nil {
+		tlsConfig := c.tlsConfig
+		if c.serverName != "" {
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = c.serverName
+		}
+		dialOptions[0] = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	} else if c.serverName != "" {
+		return nil, common.Error("server name needs TLS")
+	}
+	target := address
+	if c.unixSocket != "" {
+		target = "passthrough:///" + c.unixSocket
+		dialOptions = append(dialOptions, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return new(net.Dialer).DialContext(ctx, "unix", c.unixSocket)
+		}))
+	} else if _, _, err := net.SplitHostPort(address); err != nil {
+		return nil, common.Errorf("invalid address '%s', error: %s", address, err.Error())
+	}
+
+	for i := 0; i < c.poolSize; i++ {
+		conn, err := grpc.Dial(target, dialOptions...)
+		if err != nil {
+			_ = c.Close()
+			return nil, common.Errorf("unable to connect to %s, error: %s", target, err.Error())
+		}
+		c.conns = append(c.conns, conn)
+	}
+	return c, nil
+}
+
+func (c *client) CheckAccess(ctx context.Context, token, access, resource string) (msg.AccessStatus, error) {
+	req := &msg.AccessCheckRequest{Token: token, Access: access, Resource: resource}
+	key := c.cache.key(token, access, resource)
+	if resp, ok := c.cache.get(key, false); ok {
+		return resp.(*msg.AccessCheckResponse).AccessCheckStatus, nil
+	}
+
+	var resp *msg.AccessCheckResponse
+	err := c.invoke(ctx, "CheckAccessWithToken", func(ctx context.Context, agent ac.AthenzAgentClient) (err error) {
+		resp, err = agent.CheckAccessWithToken(ctx, req)
+		return err
+	})
+	if err != nil {
+		if cached, ok := c.fallbackOf(key, err); ok {
+			return cached.(*msg.AccessCheckResponse).AccessCheckStatus, nil
+		}
+		return msg.AccessStatus_DENY, err
+	}
+	// the status changes when the policies of the domain are loaded
+	if resp.AccessCheckStatus != msg.AccessStatus_DENY_DOMAIN_LOADING {
+		if expiry, ok := tokenExpiry(token); ok {
+			c.cache.put(key, resp, expiry)
+		}
+	}
+	return resp.AccessCheckStatus, nil
+}
+
+func (c *client) CheckHTTPAccess(ctx context.Context,
+	req *msg.HTTPAccessCheckRequest) (*msg.HTTPAccessCheckResponse, error) {
+
+	key := c.cache.httpKey(req)
+	if resp, ok := c.cache.get(key, false); ok {
+		return resp.(*msg.HTTPAccessCheckResponse), nil
+	}
+
+	var resp *msg.HTTPAccessCheckResponse
+	err := c.invoke(ctx, "CheckHTTPAccessWithToken", func(ctx context.Context, agent ac.AthenzAgentClient) (err error) {
+		resp, err = agent.CheckHTTPAccessWithToken(ctx, req)
+		return err
+	})
+	if err != nil {
+		if cached, ok := c.fallbackOf(key, err); ok {
+			return cached.(*msg.HTTPAccessCheckResponse), nil
+		}
+		return nil, err
+	}
+	if resp.AccessCheckStatus != msg.AccessStatus_DENY_DOMAIN_LOADING {
+		if expiry, ok := httpTokenExpiry(req); ok {
+			c.cache.put(key, resp, expiry)
+		}
+	}
+	return resp, nil
+}
+
+func (c *client) GetServiceToken(ctx context.Context, req *msg.ServiceTokenRequest) (string, error) {
+	var resp *msg.ServiceTokenResponse
+	err := c.invoke(ctx, "GetServiceToken", func(ctx context.Context, agent ac.AthenzAgentClient) (err error) {
+		resp, err = agent.GetServiceToken(ctx, req)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Token, nil
+}
+
+func (c *client) GetAccessToken(ctx context.Context, req *msg.AccessTokenRequest) (*msg.AccessTokenResponse, error) {
+	var resp *msg.AccessTokenResponse
+	err := c.invoke(ctx, "GetAccessToken", func(ctx context.Context, agent ac.AthenzAgentClient) (err error) {
+		resp, err = agent.GetAccessToken(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *client) ListDomains(ctx context.Context) ([]*msg.DomainStatus, error) {
+	var resp *msg.ListDomainsResponse
+	err := c.invokeAdmin(ctx, "ListDomains", func(ctx context.Context, admin ac.AthenzAgentAdminClient) (err error) {
+		resp, err = admin.ListDomains(ctx, &msg.ListDomainsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Domains, nil
+}
+
+func (c *client) RefreshPolicies(ctx context.Context, domains []string) ([]*msg.DomainStatus, error) {
+	var resp *msg.RefreshPoliciesResponse
+	err := c.invokeAdmin(ctx, "RefreshPolicies", func(ctx context.Context, admin ac.AthenzAgentAdminClient) (err error) {
+		resp, err = admin.RefreshPolicies(ctx, &msg.RefreshPoliciesRequest{Domains: domains})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Domains, nil
+}
+
+func (c *client) ReloadCache(ctx context.Context) ([]*msg.DomainStatus, error) {
+	var resp *msg.ReloadCacheResponse
+	err := c.invokeAdmin(ctx, "ReloadCache", func(ctx context.Context, admin ac.AthenzAgentAdminClient) (err error) {
+		resp, err = admin.ReloadCache(ctx, &msg.ReloadCacheRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Domains, nil
+}
+
+func (c *client) FlushTokenCache(ctx context.Context) (int, error) {
+	var resp *msg.FlushTokenCacheResponse
+	err := c.invokeAdmin(ctx, "FlushTokenCache", func(ctx context.Context, admin ac.AthenzAgentAdminClient) (err error) {
+		resp, err = admin.FlushTokenCache(ctx, &msg.FlushTokenCacheRequest{})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(resp.FlushedTokens), nil
+}
+
+func (c *client) GetStatus(ctx context.Context) (*msg.StatusResponse, error) {
+	var resp *msg.StatusResponse
+	err := c.invokeAdmin(ctx, "GetStatus", func(ctx context.Context, admin ac.AthenzAgentAdminClient) (err error) {
+		resp, err = admin.GetStatus(ctx, &msg.GetStatusRequest{})
+		return err
+	})
+	return resp, err
+}
+
+func (c *client) Close() error {
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return nil
+	}
+	var firstErr error
+	for _, conn := range c.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// invoke calls the RPC of AthenzAgent service like invokeConn.
+func (c *client) invoke(ctx context.Context, method string,
+	call func(ctx context.Context, agent ac.AthenzAgentClient) error) error {
+	return c.invokeConn(ctx, method, func(ctx context.Context, conn *grpc.ClientConn) error {
+		return call(ctx, ac.NewAthenzAgentClient(conn))
+	})
+}
+
+// invokeAdmin calls the RPC of AthenzAgentAdmin service like invokeConn.
+func (c *client) invokeAdmin(ctx context.Context, method string,
+	call func(ctx context.Context, admin ac.AthenzAgentAdminClient) error) error {
+	return c.invokeConn(ctx, method, func(ctx context.Context, conn *grpc.ClientConn) error {
+		return call(ctx, ac.NewAthenzAgentAdminClient(conn))
+	})
+}
+
+// invokeConn calls the RPC with a timeout per attempt and retries it while it
+// fails with Unavailable. The error is converted to *Error.
+func (c *client) invokeConn(ctx context.Context, method string,
+	call func(ctx context.Context, conn *grpc.ClientConn) error) error {
+
+	if atomic.LoadInt32(&c.closed) == 1 {
+		return &Error{Method: method, Code: codes.Canceled, Message: "client is closed"}
+	}
+	if c.secret != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, secretMetadataKey, c.secret)
+	}
+
+	backoff := c.backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err = call(attemptCtx, c.conn())
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if status.Code(err) != codes.Unavailable || attempt >= c.attempts {
+			break
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return newError(method, ctx.Err())
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+	return newError(method, err)
+}
+
+// conn returns the next connection of the pool.
+func (c *client) conn() *grpc.ClientConn {
+	return c.conns[int(atomic.AddUint32(&c.next, 1))%len(c.conns)]
+}
+
+// fallbackOf returns the stale cached decision if the agent is unavailable.
+func (c *client) fallbackOf(key string, err error) (interface{}, bool) {
+	if !IsUnavailable(err) {
+		return nil, false
+	}
+	return c.cache.get(key, true)
+}
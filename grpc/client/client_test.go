@@ -0,0 +1,343 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 6:50 AM
+ *
+ * Description:
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	ac "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/command/v1"
+	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/clock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeAgent allows the "valid" token and counts the calls.
+type fakeAgent struct {
+	calls  int32
+	secret atomic.Value
+}
+
+func (f *fakeAgent) CheckAccessWithToken(ctx context.Context,
+	req *msg.AccessCheckRequest) (*msg.AccessCheckResponse, error) {
+
+	atomic.AddInt32(&f.calls, 1)
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(secretMetadataKey)) > 0 {
+		f.secret.Store(md.Get(secretMetadataKey)[0])
+	}
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "unable to create RoleToken")
+	}
+	if req.Token != "valid" {
+		return &msg.AccessCheckResponse{AccessCheckStatus: msg.AccessStatus_DENY_ROLE_TOKEN_INVALID}, nil
+	}
+	return &msg.AccessCheckResponse{AccessCheckStatus: msg.AccessStatus_ALLOW}, nil
+}
+
+func (f *fakeAgent) CheckHTTPAccessWithToken(_ context.Context,
+	req *msg.HTTPAccessCheckRequest) (*msg.HTTPAccessCheckResponse, error) {
+
+	atomic.AddInt32(&f.calls, 1)
+	return &msg.HTTPAccessCheckResponse{AccessCheckStatus: msg.AccessStatus_ALLOW,
+		Access: "read", Resource: req.Path}, nil
+}
+
+func (f *fakeAgent) GetServiceToken(_ context.Context,
+	req *msg.ServiceTokenRequest) (*msg.ServiceTokenResponse, error) {
+	return &msg.ServiceTokenResponse{Token: "v=Z1;d=" + req.Domain}, nil
+}
+
+func (f *fakeAgent) GetAccessToken(context.Context, *msg.AccessTokenRequest) (*msg.AccessTokenResponse, error) {
+	return nil, status.Error(codes.PermissionDenied, "caller is not allowed to request the token")
+}
+
+func (f *fakeAgent) GetLogLevel(context.Context, *msg.GetLogLevelRequest) (*msg.LogLevelResponse, error) {
+	return &msg.LogLevelResponse{Level: "info"}, nil
+}
+
+func (f *fakeAgent) SetLogLevel(_ context.Context, req *msg.SetLogLevelRequest) (*msg.LogLevelResponse, error) {
+	return &msg.LogLevelResponse{Level: req.Level, PreviousLevel: "info"}, nil
+}
+
+func (f *fakeAgent) ListDomains(context.Context, *msg.ListDomainsRequest) (*msg.ListDomainsResponse, error) {
+	return &msg.ListDomainsResponse{Domains: []*msg.DomainStatus{{Domain: "sports", FileName: "sports.pol",
+		Valid: true}}}, nil
+}
+
+func (f *fakeAgent) RefreshPolicies(_ context.Context,
+	req *msg.RefreshPoliciesRequest) (*msg.RefreshPoliciesResponse, error) {
+	domains := make([]*msg.DomainStatus, 0, len(req.Domains))
+	for _, domain := range req.Domains {
+		domains = append(domains, &msg.DomainStatus{Domain: domain, FileName: domain + ".pol", Valid: true})
+	}
+	return &msg.RefreshPoliciesResponse{Domains: domains}, nil
+}
+
+func (f *fakeAgent) ReloadCache(context.Context, *msg.ReloadCacheRequest) (*msg.ReloadCacheResponse, error) {
+	return &msg.ReloadCacheResponse{}, nil
+}
+
+func (f *fakeAgent) FlushTokenCache(context.Context, *msg.FlushTokenCacheRequest) (*msg.FlushTokenCacheResponse,
+	error) {
+	return &msg.FlushTokenCacheResponse{FlushedTokens: 3}, nil
+}
+
+func (f *fakeAgent) GetStatus(context.Context, *msg.GetStatusRequest) (*msg.StatusResponse, error) {
+	return &msg.StatusResponse{LogLevel: "info", Domains: []string{"sports"}, LoadedDomains: 1}, nil
+}
+
+func TestClient_CheckAccess(t *testing.T) {
+	a := assert.New(t)
+	agent, address, stop := startAgent(t, "tcp", "127.0.0.1:0")
+	defer stop()
+
+	c, err := New(address, WithCache(time.Minute, 10), WithSecret("s3cret"), WithPoolSize(2))
+	a.NoError(err)
+	defer c.Close()
+	ctx := context.Background()
+
+	accessStatus, err := c.CheckAccess(ctx, "valid", "read", "sports:articles")
+	a.NoError(err)
+	a.Equal(msg.AccessStatus_ALLOW, accessStatus)
+	a.Equal("s3cret", agent.secret.Load())
+
+	// the decision is cached
+	accessStatus, err = c.CheckAccess(ctx, "valid", "read", "sports:articles")
+	a.NoError(err)
+	a.Equal(msg.AccessStatus_ALLOW, accessStatus)
+	a.Equal(int32(1), atomic.LoadInt32(&agent.calls))
+
+	accessStatus, err = c.CheckAccess(ctx, "invalid", "read", "sports:articles")
+	a.NoError(err)
+	a.Equal(msg.AccessStatus_DENY_ROLE_TOKEN_INVALID, accessStatus)
+
+	resp, err := c.CheckHTTPAccess(ctx, &msg.HTTPAccessCheckRequest{Token: "valid", Method: "GET", Path: "/articles"})
+	a.NoError(err)
+	a.Equal("/articles", resp.Resource)
+	_, err = c.CheckHTTPAccess(ctx, &msg.HTTPAccessCheckRequest{Token: "valid", Method: "GET", Path: "/articles"})
+	a.NoError(err)
+	a.Equal(int32(3), atomic.LoadInt32(&agent.calls))
+
+	token, err := c.GetServiceToken(ctx, &msg.ServiceTokenRequest{Domain: "sports"})
+	a.NoError(err)
+	a.Equal("v=Z1;d=sports", token)
+
+	// typed errors
+	_, err = c.CheckAccess(ctx, "", "read", "sports:articles")
+	a.True(IsInvalidArgument(err))
+	_, err = c.GetAccessToken(ctx, &msg.AccessTokenRequest{Domain: "weather"})
+	a.True(IsPermissionDenied(err))
+	if e, ok := err.(*Error); a.True(ok) {
+		a.Equal("GetAccessToken", e.Method)
+		a.Equal(codes.PermissionDenied, e.Code)
+	}
+
+	a.NoError(c.Close())
+	_, err = c.CheckAccess(ctx, "valid", "write", "sports:articles")
+	a.Equal(codes.Canceled, status.Code(err))
+}
+
+func TestClient_Fallback(t *testing.T) {
+	a := assert.New(t)
+	agent, address, stop := startAgent(t, "tcp", "127.0.0.1:0")
+
+	c, err := New(address, WithCache(50*time.Millisecond, 0), WithFallback(time.Minute),
+		WithRetry(2, 10*time.Millisecond), WithTimeout(time.Second))
+	a.NoError(err)
+	defer c.Close()
+	fake := clock.NewFake(time.Now())
+	c.(*client).cache.clock = fake
+	ctx := context.Background()
+
+	_, err = c.CheckAccess(ctx, "valid", "read", "sports:articles")
+	a.NoError(err)
+	stop()
+	fake.Add(100 * time.Millisecond)
+
+	// the expired decision is returned while the agent is unavailable
+	accessStatus, err := c.CheckAccess(ctx, "valid", "read", "sports:articles")
+	a.NoError(err)
+	a.Equal(msg.AccessStatus_ALLOW, accessStatus)
+	a.Equal(int32(1), atomic.LoadInt32(&agent.calls))
+
+	accessStatus, err = c.CheckAccess(ctx, "valid", "write", "sports:articles")
+	a.Error(err)
+	a.True(IsUnavailable(err))
+	a.Equal(msg.AccessStatus_DENY, accessStatus)
+}
+
+func TestDecisionCache_TokenExpiry(t *testing.T) {
+	a := assert.New(t)
+	c := newDecisionCache(time.Minute, time.Hour, 1)
+	expired := time.Now().Add(-time.Second).Unix()
+	token := "v=S1;d=sports;r=reader;e=" + strconv.FormatInt(expired, 10) + ";k=0;s=sig"
+	expiry, ok := tokenExpiry(token)
+	a.True(ok)
+	a.Equal(time.Unix(expired, 0), expiry)
+	expiry, ok = tokenExpiry("v=S1;d=sports")
+	a.True(ok)
+	a.True(expiry.IsZero())
+
+	// decisions of expired tokens are not returned, even as fallback
+	c.put("expired", msg.AccessStatus_ALLOW, time.Unix(expired, 0))
+	_, ok = c.get("expired", false)
+	a.False(ok)
+	_, ok = c.get("expired", true)
+	a.False(ok)
+
+	// the expired entry is removed when the cache is full
+	c.put("valid", msg.AccessStatus_ALLOW, time.Now().Add(time.Minute))
+	_, ok = c.get("valid", false)
+	a.True(ok)
+	a.Len(c.entries, 1)
+
+	expiry, ok = httpTokenExpiry(&msg.HTTPAccessCheckRequest{
+		Headers: map[string]string{"Authorization": "Bearer " + token, "Accept": "*/*", "Host": "api.sports.com"}})
+	a.True(ok)
+	a.Equal(time.Unix(expired, 0), expiry)
+}
+
+func TestDecisionCache_JWTExpiry(t *testing.T) {
+	a := assert.New(t)
+	jwt := func(payload string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","kid":"0"}`)) + "." +
+			base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+	}
+
+	expiry, ok := tokenExpiry(jwt(`{"aud":"sports","scope":"reader","exp":1893456000}`))
+	a.True(ok)
+	a.Equal(time.Unix(1893456000, 0), expiry)
+
+	// decisions of JWTs without expiry are not cached
+	_, ok = tokenExpiry(jwt(`{"aud":"sports","scope":"reader"}`))
+	a.False(ok)
+	_, ok = tokenExpiry(jwt("not json"))
+	a.False(ok)
+	_, ok = httpTokenExpiry(&msg.HTTPAccessCheckRequest{
+		Headers: map[string]string{"Authorization": "Bearer " + jwt(`{"aud":"sports"}`)}})
+	a.False(ok)
+
+	// dotted values like hosts are not JWTs
+	expiry, ok = tokenExpiry("www.sports.com")
+	a.True(ok)
+	a.True(expiry.IsZero())
+}
+
+func TestClient_Options(t *testing.T) {
+	a := assert.New(t)
+	for _, opts := range [][]Option{
+		{WithTimeout(0)},
+		{WithCache(0, 10)},
+		{WithCache(time.Minute, -1)},
+		{WithFallback(0)},
+		{WithFallback(time.Minute)},
+	} {
+		_, err := New("127.0.0.1:9090", opts...)
+		a.Error(err)
+	}
+
+	c, err := New("127.0.0.1:9090", WithCache(time.Minute, 10), WithFallback(time.Hour), WithTimeout(time.Second))
+	a.NoError(err)
+	a.NoError(c.Close())
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "agent")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+
+	_, _, stop := startAgent(t, "unix", filepath.Join(dir, "agent.sock"))
+	defer stop()
+
+	c, err := New("", WithUnixSocket(filepath.Join(dir, "agent.sock")))
+	a.NoError(err)
+	defer c.Close()
+	accessStatus, err := c.CheckAccess(context.Background(), "valid", "read", "sports:articles")
+	a.NoError(err)
+	a.Equal(msg.AccessStatus_ALLOW, accessStatus)
+
+	domains, err := c.ListDomains(context.Background())
+	a.NoError(err)
+	if a.Len(domains, 1) {
+		a.Equal("sports.pol", domains[0].FileName)
+		a.True(domains[0].Valid)
+	}
+
+	domains, err = c.RefreshPolicies(context.Background(), []string{"weather"})
+	a.NoError(err)
+	if a.Len(domains, 1) {
+		a.Equal("weather.pol", domains[0].FileName)
+	}
+	flushed, err := c.FlushTokenCache(context.Background())
+	a.NoError(err)
+	a.Equal(3, flushed)
+	agentStatus, err := c.GetStatus(context.Background())
+	a.NoError(err)
+	a.Equal(int32(1), agentStatus.LoadedDomains)
+
+	_, err = New("127.0.0.1")
+	a.Error(err)
+	_, err = New("127.0.0.1:9090", WithRetry(0, 0))
+	a.Error(err)
+	_, err = New("127.0.0.1:9090", WithServerName("agent"))
+	a.Error(err)
+}
+
+func TestCheckAccessWithClient(t *testing.T) {
+	a := assert.New(t)
+	_, address, stop := startAgent(t, "tcp", "127.0.0.1:0")
+	defer stop()
+
+	host, port, _ := net.SplitHostPort(address)
+	accessStatus, err := CheckAccessWithClient("valid", "read", "sports:articles", host, port)
+	a.NoError(err)
+	a.Equal(int32(msg.AccessStatus_ALLOW), accessStatus)
+
+	// a closed port is an error, not a fatal log
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	_ = listener.Close()
+	accessStatus, err = CheckAccessWithClient("valid", "read", "sports:articles", "127.0.0.1", closedPort)
+	a.True(IsUnavailable(err))
+	a.Equal(int32(-1), accessStatus)
+}
+
+func startAgent(t *testing.T, network, address string) (*fakeAgent, string, func()) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := new(fakeAgent)
+	server := grpc.NewServer()
+	ac.RegisterAthenzAgentServer(server, agent)
+	ac.RegisterAthenzAgentAdminServer(server, agent)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return agent, listener.Addr().String(), server.Stop
+}
@@ -0,0 +1,83 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 6:40 AM
+ *
+ * Description:
+ * Error is the error type of Client calls, it has the gRPC code of the
+ * failure, so callers can tell an unavailable agent from a rejected call.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// Error is the error of a failed call.
+	Error struct {
+		// Method is the called RPC, e.g. CheckAccessWithToken
+		Method  string
+		Code    codes.Code
+		Message string
+	}
+)
+
+// newError converts the error of a call to *Error.
+func newError(method string, err error) *Error {
+	switch err {
+	case context.DeadlineExceeded:
+		return &Error{Method: method, Code: codes.DeadlineExceeded, Message: err.Error()}
+	case context.Canceled:
+		return &Error{Method: method, Code: codes.Canceled, Message: err.Error()}
+	}
+	st := status.Convert(err)
+	return &Error{Method: method, Code: st.Code(), Message: st.Message()}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s failed, code: %s, error: %s", e.Method, e.Code.String(), e.Message)
+}
+
+// GRPCStatus returns the gRPC status of the error, so status.Code works with
+// *Error too.
+func (e *Error) GRPCStatus() *status.Status {
+	return status.New(e.Code, e.Message)
+}
+
+// IsUnavailable checks the agent couldn't be reached or didn't respond in
+// time.
+func IsUnavailable(err error) bool {
+	code := codeOf(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// IsPermissionDenied checks the caller is not allowed to call the RPC.
+func IsPermissionDenied(err error) bool {
+	return codeOf(err) == codes.PermissionDenied
+}
+
+// IsInvalidArgument checks the request was rejected, e.g. an invalid token.
+func IsInvalidArgument(err error) bool {
+	return codeOf(err) == codes.InvalidArgument
+}
+
+func codeOf(err error) codes.Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return status.Code(err)
+}
@@ -18,43 +18,27 @@
 package client
 
 import (
-	"fmt"
-	"github.com/hamed-yousefi/athenz-agent/common"
-	"github.com/hamed-yousefi/athenz-agent/common/log"
-	"golang.org/x/net/context"
-	"google.golang.org/grpc"
-
-	ac "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/command/v1"
-	msg "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
-)
-
-var (
-	logger = log.GetLogger(common.GolangFileName())
+	"context"
+	"net"
 )
 
 // CheckAccessWithClient connects to a athenz-agent server to check an access to a
 // resource for a token.
 //
-// CheckAccessWithClient is useful to test tokens and other kind of tests.
+// CheckAccessWithClient is useful to test tokens and other kind of tests, use
+// a long-lived Client in services.
 func CheckAccessWithClient(token, access, resource, host, serverPort string) (int32, error) {
-
-	var conn *grpc.ClientConn
-
-	conn, err := grpc.Dial(host+serverPort, grpc.WithInsecure())
+	c, err := New(net.JoinHostPort(host, serverPort))
 	if err != nil {
-		logger.Fatalf("unable to connect, error: %s", err.Error())
+		return -1, err
 	}
 	defer func() {
-		_ = conn.Close()
+		_ = c.Close()
 	}()
 
-	client := ac.NewAthenzAgentClient(conn)
-
-	response, err := client.CheckAccessWithToken(context.Background(),
-		&msg.AccessCheckRequest{Token: token, Access: access, Resource: resource})
+	accessStatus, err := c.CheckAccess(context.Background(), token, access, resource)
 	if err != nil {
-		return -1, common.Errorf(fmt.Sprintf("error when calling `CheckAccessWithToken`, error: %s", err.Error()))
+		return -1, err
 	}
-
-	return int32(response.AccessCheckStatus.Enum().Number()), err
+	return int32(accessStatus), nil
 }
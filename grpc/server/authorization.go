@@ -0,0 +1,228 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 1:30 AM
+ *
+ * Description:
+ * The authorization interceptors check the caller of each RPC against the
+ * authorization rules of server config. A caller is identified by its mTLS
+ * client certificate, its unix socket peer credentials or a shared secret
+ * in metadata. Denied calls are logged and fail with PermissionDenied.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	ac "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/command/v1"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SecretMetadataKey is the metadata key of the shared secret
+	SecretMetadataKey = "x-athenz-agent-secret"
+)
+
+type (
+	// caller holds the identities of a RPC caller.
+	caller struct {
+		// commonName of the verified client certificate
+		commonName string
+		unixPeer   *UnixPeer
+		secret     string
+		address    string
+	}
+
+	// secretFiles caches the content of authorization secret files.
+	secretFiles struct {
+		mu    sync.Mutex
+		files map[string]secretFile
+	}
+
+	// secretFile is a cached secret with the file state it was read from.
+	secretFile struct {
+		secret  string
+		modTime time.Time
+		size    int64
+	}
+)
+
+var (
+	// secrets are the secret files of authorization rules
+	secrets = &secretFiles{files: make(map[string]secretFile)}
+)
+
+// authorizeUnary is the unary interceptor of authorization rules.
+func authorizeUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+
+	if err := authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authorizeStream is the stream interceptor of authorization rules.
+func authorizeStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+
+	if err := authorize(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// authorize checks a rule of the method allows the caller, methods without
+// rule are denied. Admin RPCs without rule are allowed only to unix socket
+// peers that run as the agent user.
+func authorize(ctx context.Context, fullMethod string) error {
+	c := callerOf(ctx)
+	matched := false
+	for _, rule := range config.AgentConfig.Get().Server.Authorization {
+		if !rule.Matches(fullMethod) {
+			continue
+		}
+		matched = true
+		if c.isAllowed(rule) {
+			return nil
+		}
+	}
+	if !matched && isAdminMethod(fullMethod) && c.unixPeer != nil && c.unixPeer.UID == uint32(os.Getuid()) {
+		return nil
+	}
+	logger.Error(fmt.Sprintf("call of %s denied, caller: %s", fullMethod, c.String()))
+	return status.Errorf(codes.PermissionDenied, "caller is not allowed to call %s", fullMethod)
+}
+
+// isAdminMethod checks the full method name is a RPC of admin service.
+func isAdminMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+ac.AthenzAgentAdmin_ServiceDesc.ServiceName+"/")
+}
+
+// CallerName returns the common name of the verified client certificate of
+// the RPC context, or empty string if the caller has no certificate.
+func CallerName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+}
+
+// callerOf extracts the caller identities of the RPC context.
+func callerOf(ctx context.Context) caller {
+	c := caller{commonName: CallerName(ctx)}
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			c.address = p.Addr.String()
+		}
+		if unixPeer, ok := p.Addr.(*UnixPeer); ok {
+			c.unixPeer = unixPeer
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(SecretMetadataKey); len(values) > 0 {
+			c.secret = values[0]
+		}
+	}
+	return c
+}
+
+// isAllowed checks the caller matches any caller property of the rule.
+func (c caller) isAllowed(rule config.MethodRule) bool {
+	if rule.AllowAny {
+		return true
+	}
+	if c.commonName != "" {
+		for _, name := range rule.Callers {
+			if name == "*" || name == c.commonName {
+				return true
+			}
+		}
+	}
+	if c.unixPeer != nil {
+		for _, uid := range rule.UIDs {
+			if uint32(uid) == c.unixPeer.UID {
+				return true
+			}
+		}
+		for _, gid := range rule.GIDs {
+			if uint32(gid) == c.unixPeer.GID {
+				return true
+			}
+		}
+	}
+	if c.secret != "" && rule.SecretFile != "" {
+		expected, err := secrets.get(rule.SecretFile)
+		if err != nil {
+			logger.Error("unable to read authorization secret file, error: " + err.Error())
+			return false
+		}
+		return expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(c.secret)) == 1
+	}
+	return false
+}
+
+// get returns the trimmed content of the secret file. The file is read again
+// only if its modification time or size changed.
+func (s *secretFiles) get(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.files[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.secret, nil
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimSpace(string(content))
+	s.files[path] = secretFile{secret: secret, modTime: info.ModTime(), size: info.Size()}
+	return secret, nil
+}
+
+// String describes the caller for logs, the secret is not included.
+func (c caller) String() string {
+	var parts []string
+	if c.commonName != "" {
+		parts = append(parts, "cn="+c.commonName)
+	}
+	if c.unixPeer != nil {
+		parts = append(parts, fmt.Sprintf("pid=%d uid=%d gid=%d", c.unixPeer.PID, c.unixPeer.UID, c.unixPeer.GID))
+	}
+	if c.secret != "" {
+		parts = append(parts, "with secret")
+	}
+	if len(parts) == 0 {
+		return "anonymous " + c.address
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,142 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 1:45 AM
+ *
+ * Description:
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+const (
+	getServiceToken = "/athenz.agent.api.command.v1.AthenzAgent/GetServiceToken"
+	checkAccess     = "/athenz.agent.api.command.v1.AthenzAgent/CheckAccessWithToken"
+	setLogLevel     = "/athenz.agent.api.command.v1.AthenzAgentAdmin/SetLogLevel"
+)
+
+func TestAuthorize(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+
+	secretFile, err := ioutil.TempFile("", "secret")
+	a.NoError(err)
+	defer os.Remove(secretFile.Name())
+	_, err = secretFile.WriteString("s3cret\n")
+	a.NoError(err)
+	a.NoError(secretFile.Close())
+
+	// all calls are denied without rules
+	a.Error(authorize(context.Background(), getServiceToken))
+	a.Error(authorize(unixCaller(1000, 1000), checkAccess))
+
+	config.AgentConfig.Properties.Server.Authorization = []config.MethodRule{
+		{Method: "GetServiceToken", Callers: []string{"sports.frontend"}, UIDs: []int{1000},
+			SecretFile: secretFile.Name()},
+		{Method: checkAccess, AllowAny: true},
+		{Method: "*", GIDs: []int{0}},
+	}
+	defer func() { config.AgentConfig.Properties.Server.Authorization = nil }()
+
+	anonymous := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{Port: 5000}})
+	a.NoError(authorize(anonymous, checkAccess))
+	err = authorize(anonymous, getServiceToken)
+	a.Error(err)
+	a.Equal(codes.PermissionDenied, status.Code(err))
+
+	// mTLS caller
+	cert := loadCertificate(t, clientCrt)
+	cert.Subject.CommonName = "sports.frontend"
+	a.NoError(authorize(tlsCaller(cert), getServiceToken))
+	cert.Subject.CommonName = "sports.backend"
+	a.Error(authorize(tlsCaller(cert), getServiceToken))
+
+	// unix socket caller
+	a.NoError(authorize(unixCaller(1000, 1000), getServiceToken))
+	a.Error(authorize(unixCaller(1001, 1001), getServiceToken))
+	a.Error(authorize(unixCaller(1000, 1000), setLogLevel))
+	a.NoError(authorize(unixCaller(1001, 0), setLogLevel))
+
+	// shared secret caller
+	withSecret := func(secret string) context.Context {
+		return metadata.NewIncomingContext(anonymous, metadata.Pairs(SecretMetadataKey, secret))
+	}
+	a.NoError(authorize(withSecret("s3cret"), getServiceToken))
+	a.Error(authorize(withSecret("wrong"), getServiceToken))
+	a.Error(authorize(withSecret("s3cret"), setLogLevel))
+
+	// the cached secret is read again after the file changes
+	a.NoError(ioutil.WriteFile(secretFile.Name(), []byte("n3w-secret\n"), 0600))
+	a.NoError(os.Chtimes(secretFile.Name(), time.Now(), time.Now().Add(time.Minute)))
+	a.NoError(authorize(withSecret("n3w-secret"), getServiceToken))
+	a.Error(authorize(withSecret("s3cret"), getServiceToken))
+
+	// "*" callers match any verified client certificate, but not callers
+	// without one
+	config.AgentConfig.Properties.Server.Authorization = []config.MethodRule{
+		{Method: "AthenzAgent", Callers: []string{"*"}}}
+	a.NoError(authorize(tlsCaller(cert), checkAccess))
+	a.Error(authorize(anonymous, checkAccess))
+	a.Error(authorize(unixCaller(1000, 1000), checkAccess))
+}
+
+// allowAnyCaller opens the RPCs of the agent service to all callers, because
+// calls without authorization rules are denied. The returned function
+// removes the rule.
+func allowAnyCaller() func() {
+	config.AgentConfig.Properties.Server.Authorization = []config.MethodRule{{Method: "AthenzAgent", AllowAny: true}}
+	return func() { config.AgentConfig.Properties.Server.Authorization = nil }
+}
+
+func tlsCaller(cert *x509.Certificate) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{Port: 5000},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}},
+	})
+}
+
+func unixCaller(uid, gid uint32) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &UnixPeer{Addr: &net.UnixAddr{Net: "unix"}, PID: 1, UID: uid, GID: gid},
+	})
+}
+
+func TestAuthorize_AdminMethods(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+
+	// admin RPCs without a rule are allowed only for the agent user
+	err := authorize(context.Background(), setLogLevel)
+	a.Error(err)
+	a.Equal(codes.PermissionDenied, status.Code(err))
+	a.Error(authorize(unixCaller(uint32(os.Getuid())+1, 0), setLogLevel))
+	a.NoError(authorize(unixCaller(uint32(os.Getuid()), 0), setLogLevel))
+}
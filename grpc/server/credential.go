@@ -0,0 +1,329 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 11:50 PM
+ *
+ * Description:
+ * serverCredential serves the server mTLS certificate and CA bundle from
+ * GetCertificate and GetConfigForClient callbacks, so rotated files are
+ * used by the next handshakes without restart. The directories of the
+ * files are watched, invalid material is rejected and the current one is
+ * kept. Expiry and reload metrics are published in "server_mtls" expvar.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"expvar"
+	"github.com/fsnotify/fsnotify"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"google.golang.org/grpc/credentials"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// credentialReloadDelay groups the file events of one rotation, e.g.
+	// cert and key written one after another
+	credentialReloadDelay = time.Second
+)
+
+var (
+	// mtlsMetrics has cert_not_after, ca_not_after (unix time),
+	// cert_expires_in_seconds, reloads and reload_errors
+	mtlsMetrics = expvar.NewMap("server_mtls")
+)
+
+type (
+	// tlsMaterial is the loaded server certificate and client CA bundle.
+	tlsMaterial struct {
+		certificate *tls.Certificate
+		clientCAs   *x509.CertPool
+		notAfter    time.Time
+		// caNotAfter is the earliest expiry time of the CA bundle
+		caNotAfter time.Time
+		// digest of the file contents, to skip reloading the same files
+		digest [sha256.Size]byte
+	}
+
+	// serverCredential holds the current tlsMaterial and reloads it.
+	serverCredential struct {
+		mu         sync.Mutex
+		properties config.MtlsProperties
+		nextProtos []string
+		current    atomic.Value
+		watcher    *fsnotify.Watcher
+		// dirs are the watched directories
+		dirs map[string]bool
+	}
+)
+
+// mTLSCredential creates server transport credentials from mTLS properties.
+// The certificate and CA bundle are reloaded when their files change, when
+// mTLS properties change at runtime, or when a reloadOn subscription calls
+// its listener.
+func mTLSCredential(ctx context.Context, properties config.MtlsProperties,
+	reloadOn []func(func()) func()) (credentials.TransportCredentials, error) {
+
+	c, err := newServerCredential(ctx, properties, reloadOn, "h2")
+	if err != nil || c == nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		GetConfigForClient: c.configForClient,
+	}), nil
+}
+
+// newServerCredential loads mTLS files and starts reloading them, it returns
+// nil if mTLS is not configured. nextProtos are the ALPN protocols of the
+// server.
+func newServerCredential(ctx context.Context, properties config.MtlsProperties, reloadOn []func(func()) func(),
+	nextProtos ...string) (*serverCredential, error) {
+
+	if properties.IsEmpty() {
+		return nil, nil
+	}
+
+	material, err := loadTLSMaterial(properties)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	c := &serverCredential{properties: properties, nextProtos: nextProtos, dirs: make(map[string]bool)}
+	c.store(material)
+	mtlsMetrics.Set("cert_expires_in_seconds", expvar.Func(func() interface{} {
+		return int64(time.Until(c.load().notAfter).Seconds())
+	}))
+
+	if err := c.watch(ctx); err != nil {
+		logger.Error("unable to watch server mTLS files, they are only reloaded on config change, error: " +
+			err.Error())
+	}
+	unsubscribes := []func(){config.AgentConfig.OnChange(func() { c.reload(ctx, false) })}
+	for _, subscribe := range reloadOn {
+		unsubscribes = append(unsubscribes, subscribe(func() { c.reload(ctx, true) }))
+	}
+	go func() {
+		<-ctx.Done()
+		for _, unsubscribe := range unsubscribes {
+			unsubscribe()
+		}
+	}()
+	return c, nil
+}
+
+// configForClient returns the TLS config of a handshake with the current
+// client CA bundle.
+func (c *serverCredential) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	return &tls.Config{
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		ClientCAs:      c.load().clientCAs,
+		GetCertificate: c.getCertificate,
+		NextProtos:     c.nextProtos,
+	}, nil
+}
+
+// getCertificate returns the current server certificate.
+func (c *serverCredential) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.load().certificate, nil
+}
+
+func (c *serverCredential) load() *tlsMaterial {
+	return c.current.Load().(*tlsMaterial)
+}
+
+func (c *serverCredential) store(material *tlsMaterial) {
+	c.current.Store(material)
+	notAfter, caNotAfter := new(expvar.Int), new(expvar.Int)
+	notAfter.Set(material.notAfter.Unix())
+	caNotAfter.Set(material.caNotAfter.Unix())
+	mtlsMetrics.Set("cert_not_after", notAfter)
+	mtlsMetrics.Set("ca_not_after", caNotAfter)
+}
+
+// reload loads the files of current mTLS properties. Unless properties
+// changed or force is set, it's skipped. The material is only replaced if
+// it's valid and the files have changed.
+func (c *serverCredential) reload(ctx context.Context, force bool) {
+	if ctx.Err() != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	properties := config.AgentConfig.Get().Server.MtlsProperties
+	changed := properties != c.properties
+	if !changed && !force {
+		return
+	}
+	if properties.IsEmpty() {
+		logger.Error("server mTLS config change rejected, disabling mTLS requires restart")
+		return
+	}
+
+	material, err := loadTLSMaterial(properties)
+	if err != nil {
+		mtlsMetrics.Add("reload_errors", 1)
+		logger.Error("server mTLS reload rejected, the current certificate is kept, error: " + err.Error())
+		return
+	}
+	if !changed && material.digest == c.load().digest {
+		return
+	}
+	c.store(material)
+	c.properties = properties
+	if changed {
+		c.watchFiles()
+	}
+	mtlsMetrics.Add("reloads", 1)
+	logger.Info("server mTLS certificate reloaded, valid until " + material.notAfter.String())
+}
+
+// watch watches the directories of mTLS files until ctx is done. Directories
+// are watched instead of files, so files replaced by rename or symlink swap
+// are detected too.
+func (c *serverCredential) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.watcher = watcher
+	c.watchFiles()
+	c.mu.Unlock()
+
+	go func() {
+		defer watcher.Close()
+		timer := time.NewTimer(credentialReloadDelay)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				timer.Reset(credentialReloadDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Error("server mTLS file watcher error: " + err.Error())
+			case <-timer.C:
+				c.reload(ctx, true)
+			}
+		}
+	}()
+	return nil
+}
+
+// watchFiles updates the watched directories with the directories of
+// current mTLS files. It must be called with mu held.
+func (c *serverCredential) watchFiles() {
+	if c.watcher == nil {
+		return
+	}
+	dirs := map[string]bool{
+		filepath.Dir(c.properties.CrtPath):        true,
+		filepath.Dir(c.properties.PrivateKeyPath): true,
+		filepath.Dir(c.properties.CaPath):         true,
+	}
+	for dir := range c.dirs {
+		if !dirs[dir] {
+			_ = c.watcher.Remove(dir)
+			delete(c.dirs, dir)
+		}
+	}
+	for dir := range dirs {
+		if c.dirs[dir] {
+			continue
+		}
+		if err := c.watcher.Add(dir); err != nil {
+			logger.Error("unable to watch server mTLS directory " + dir + ", error: " + err.Error())
+			continue
+		}
+		c.dirs[dir] = true
+	}
+}
+
+// loadTLSMaterial reads certificate, private key and CA files. It returns an
+// error if the key doesn't match the certificate, the certificate is not
+// valid at this time or the CA bundle has no certificate.
+func loadTLSMaterial(properties config.MtlsProperties) (*tlsMaterial, error) {
+	certPem, err := ioutil.ReadFile(properties.CrtPath)
+	if err != nil {
+		return nil, err
+	}
+	keyPem, err := ioutil.ReadFile(properties.PrivateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	caPem, err := ioutil.ReadFile(properties.CaPath)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := tls.X509KeyPair(certPem, keyPem)
+	if err != nil {
+		return nil, common.Errorf("invalid server certificate %s, error: %s", properties.CrtPath, err.Error())
+	}
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return nil, common.Errorf("invalid server certificate %s, error: %s", properties.CrtPath, err.Error())
+	}
+	now := time.Now()
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return nil, common.Errorf("server certificate %s is only valid from %s until %s", properties.CrtPath,
+			leaf.NotBefore.String(), leaf.NotAfter.String())
+	}
+	certificate.Leaf = leaf
+
+	certPool := x509.NewCertPool()
+	var caNotAfter time.Time
+	for rest := caPem; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		ca, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			continue
+		}
+		certPool.AddCert(ca)
+		if caNotAfter.IsZero() || ca.NotAfter.Before(caNotAfter) {
+			caNotAfter = ca.NotAfter
+		}
+	}
+	if caNotAfter.IsZero() {
+		return nil, common.Errorf("unable to append ca cert: %s", properties.CaPath)
+	}
+
+	hash := sha256.New()
+	hash.Write(certPem)
+	hash.Write(keyPem)
+	hash.Write(caPem)
+	material := &tlsMaterial{certificate: &certificate, clientCAs: certPool, notAfter: leaf.NotAfter,
+		caNotAfter: caNotAfter}
+	copy(material.digest[:], hash.Sum(nil))
+	return material, nil
+}
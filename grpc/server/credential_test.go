@@ -0,0 +1,173 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 11:55 PM
+ *
+ * Description:
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"expvar"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMTLSCredentialReload(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+
+	dir := mtlsDir(t, serverCrt, serverKey)
+	defer os.RemoveAll(dir)
+
+	var listener func()
+	unsubscribed := make(chan struct{})
+	subscribe := func(fn func()) func() {
+		listener = fn
+		return func() { close(unsubscribed) }
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	credential, err := mTLSCredential(ctx, config.AgentConfig.Get().Server.MtlsProperties,
+		[]func(func()) func(){subscribe})
+	a.NoError(err)
+	a.NotNil(listener)
+
+	serverCert := serverCertificate(t, credential)
+	a.Equal(loadCertificate(t, serverCrt).SerialNumber, serverCert.SerialNumber)
+	a.Equal(serverCert.NotAfter.Unix(), mtlsMetrics.Get("cert_not_after").(*expvar.Int).Value())
+
+	// the files are rotated at the same path, listener forces the reload
+	copyFile(t, clientCrt, dir+"/crt.pem")
+	copyFile(t, clientKey, dir+"/key.pem")
+	listener()
+
+	serverCert = serverCertificate(t, credential)
+	a.Equal(loadCertificate(t, clientCrt).SerialNumber, serverCert.SerialNumber)
+
+	// the listener is unsubscribed when the server is stopped
+	cancel()
+	select {
+	case <-unsubscribed:
+	case <-time.After(5 * time.Second):
+		a.Fail("reload listener was not unsubscribed")
+	}
+}
+
+func TestMTLSCredentialFileWatcher(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+
+	dir := mtlsDir(t, serverCrt, serverKey)
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	credential, err := mTLSCredential(ctx, config.AgentConfig.Get().Server.MtlsProperties, nil)
+	a.NoError(err)
+
+	// the key doesn't match the certificate, the current one is kept
+	errors := metricValue("reload_errors")
+	copyFile(t, clientKey, dir+"/key.pem")
+	a.Eventually(func() bool { return metricValue("reload_errors") > errors }, 5*time.Second,
+		100*time.Millisecond)
+	a.Equal(loadCertificate(t, serverCrt).SerialNumber, serverCertificate(t, credential).SerialNumber)
+
+	// the rotated certificate is used without reload call
+	copyFile(t, clientCrt, dir+"/crt.pem")
+	clientSerial := loadCertificate(t, clientCrt).SerialNumber
+	a.Eventually(func() bool {
+		return serverCertificate(t, credential).SerialNumber.Cmp(clientSerial) == 0
+	}, 5*time.Second, 100*time.Millisecond)
+	a.True(mtlsMetrics.Get("cert_expires_in_seconds").(expvar.Func).Value().(int64) > 0)
+}
+
+// mtlsDir copies the cert and key into a temp directory and sets server
+// mTLS properties to them.
+func mtlsDir(t *testing.T, crt, key string) string {
+	dir, err := ioutil.TempDir("", "mtls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copyFile(t, crt, dir+"/crt.pem")
+	copyFile(t, key, dir+"/key.pem")
+
+	config.AgentConfig.Properties.Server.CrtPath = dir + "/crt.pem"
+	config.AgentConfig.Properties.Server.PrivateKeyPath = dir + "/key.pem"
+	config.AgentConfig.Properties.Server.CaPath = ca
+	return dir
+}
+
+func metricValue(key string) int64 {
+	if v, ok := mtlsMetrics.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// serverCertificate returns the certificate that server presents in TLS
+// handshake.
+func serverCertificate(t *testing.T, credential credentials.TransportCredentials) *x509.Certificate {
+	clientCert, err := tls.LoadX509KeyPair(clientCrt, clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+	go func() {
+		_, _, _ = credential.ServerHandshake(serverConn)
+	}()
+
+	conn := tls.Client(clientConn, &tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{clientCert},
+		NextProtos:         []string{"h2"},
+	})
+	if err := conn.Handshake(); err != nil {
+		t.Fatal(err)
+	}
+	return conn.ConnectionState().PeerCertificates[0]
+}
+
+func loadCertificate(t *testing.T, file string) *x509.Certificate {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(data)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dst, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -21,41 +21,89 @@ package server
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/hamed-yousefi/athenz-agent/common/log"
 	"github.com/hamed-yousefi/athenz-agent/config"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"io/ioutil"
 	"net"
 	"os"
 	"os/signal"
 	"sync"
 
 	ac "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/command/v1"
+	authv3 "github.com/hamed-yousefi/athenz-agent/.gen/proto/envoy/service/auth/v3"
 )
 
 var (
 	logger = log.GetLogger(common.GolangFileName())
 )
 
-func RunServer(ctx context.Context, ps ac.AthenzAgentServer, port string, waitGrp *sync.WaitGroup) error {
-	listen, err := net.Listen("tcp", ":"+port)
+type (
+	// Option configures optional gRPC server features.
+	Option func(*serverOptions)
+
+	serverOptions struct {
+		adminService    ac.AthenzAgentAdminServer
+		extAuthzService authv3.AuthorizationServer
+		// reloadOn subscribes listeners that reload mTLS credentials, the
+		// returned functions unsubscribe them
+		reloadOn []func(func()) func()
+	}
+)
+
+// WithAdminService registers the admin service next to the agent service.
+func WithAdminService(as ac.AthenzAgentAdminServer) Option {
+	return func(o *serverOptions) {
+		o.adminService = as
+	}
+}
+
+// WithExtAuthzService registers Envoy ext_authz Authorization service next to
+// the agent service.
+func WithExtAuthzService(as authv3.AuthorizationServer) Option {
+	return func(o *serverOptions) {
+		o.extAuthzService = as
+	}
+}
+
+// WithCredentialReload reloads the server mTLS credentials from the configured
+// files whenever subscribe calls its listener, e.g. after the service identity
+// certificate is refreshed. The listener is unsubscribed when the server
+// context is done.
+func WithCredentialReload(subscribe func(fn func()) func()) Option {
+	return func(o *serverOptions) {
+		o.reloadOn = append(o.reloadOn, subscribe)
+	}
+}
+
+func RunServer(ctx context.Context, ps ac.AthenzAgentServer, port string, waitGrp *sync.WaitGroup, opts ...Option) error {
+	options := new(serverOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	listeners, err := listen(config.AgentConfig.Get().Server, port)
 	if err != nil {
 		return err
 	}
 
-	credential, err := mTLSCredential(config.AgentConfig.Properties.Server.MtlsProperties)
+	credential, err := mTLSCredential(ctx, config.AgentConfig.Get().Server.MtlsProperties, options.reloadOn)
 	if err != nil {
 		logger.Error(err.Error())
+		closeAll(listeners)
 		return err
 	}
 
 	// register service
-	server := grpc.NewServer(grpc.Creds(credential))
+	server := grpc.NewServer(grpc.Creds(credential), grpc.UnaryInterceptor(authorizeUnary),
+		grpc.StreamInterceptor(authorizeStream))
 	ac.RegisterAthenzAgentServer(server, ps)
+	if options.adminService != nil {
+		ac.RegisterAthenzAgentAdminServer(server, options.adminService)
+	}
+	if options.extAuthzService != nil {
+		authv3.RegisterAuthorizationServer(server, options.extAuthzService)
+	}
 
 	// graceful shutdown
 	c := make(chan os.Signal, 1)
@@ -75,39 +123,27 @@ func RunServer(ctx context.Context, ps ac.AthenzAgentServer, port string, waitGr
 		}
 	}()
 
-	// start gRPC server
-	logger.Info("'athenz-agent' gRPC server listening on port: " + port)
-	return server.Serve(listen)
-}
-
-func mTLSCredential(properties config.MtlsProperties) (credentials.TransportCredentials, error) {
-
-	if properties.IsEmpty() {
-		return nil, nil
+	// start gRPC server on all listeners, the first failure stops the others
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		logger.Info("'athenz-agent' gRPC server listening on " + listener.Addr().Network() + " " +
+			listener.Addr().String())
+		go func(listener net.Listener) {
+			errs <- server.Serve(listener)
+		}(listener)
 	}
-	certificate, err := tls.LoadX509KeyPair(properties.CrtPath, properties.PrivateKeyPath)
-	if err != nil {
-		logger.Error(err.Error())
-		return nil, err
-	}
-
-	data, err := ioutil.ReadFile(properties.CaPath)
-	if err != nil {
-		logger.Error(err.Error())
-		return nil, err
-	}
-
-	certPool := x509.NewCertPool()
-	if !certPool.AppendCertsFromPEM(data) {
-		logger.Error("append ca cert failed!")
-		return nil, err
+	for range listeners {
+		if err := <-errs; err != nil {
+			server.Stop()
+			return err
+		}
 	}
+	return nil
+}
 
-	tlsConfig := &tls.Config{
-		ClientAuth:   tls.RequireAndVerifyClientCert,
-		Certificates: []tls.Certificate{certificate},
-		ClientCAs:    certPool,
+// closeAll closes the listeners that are not served yet.
+func closeAll(listeners []net.Listener) {
+	for _, listener := range listeners {
+		_ = listener.Close()
 	}
-
-	return credentials.NewTLS(tlsConfig), err
 }
@@ -94,6 +94,7 @@ func TestRunServer(t *testing.T) {
 	config.AgentConfig.Properties.Server.CrtPath = ""
 	config.AgentConfig.Properties.Server.PrivateKeyPath = ""
 	config.AgentConfig.Properties.Server.CaPath = ""
+	defer allowAnyCaller()()
 	a := assert.New(t)
 
 	wg := new(sync.WaitGroup)
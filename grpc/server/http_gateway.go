@@ -0,0 +1,316 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 2:20 AM
+ *
+ * Description:
+ * HTTP/JSON gateway for the clients that can't use gRPC, e.g. shell
+ * scripts or nginx auth_request. Only POST is accepted. It calls the same AthenzAgentServer as
+ * the gRPC server, with the same mTLS settings and authorization rules:
+ *   POST /v1/access  CheckAccessWithToken, 200 if access is allowed,
+ *                    503 if the policies of the domain are loading,
+ *                    otherwise 403
+ *   POST /v1/token   GetServiceToken
+ * The role token is read from Athenz-Role-Auth or Authorization header,
+ * parameters are read from JSON body or query/form values.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	ac "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/command/v1"
+	v1 "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// RoleAuthHeader is the header of role token in HTTP requests
+	RoleAuthHeader = "Athenz-Role-Auth"
+	// maxRequestBodySize limits JSON request bodies
+	maxRequestBodySize = 1 << 20
+	// gatewayShutdownTimeout is the time that running requests have to
+	// complete on shutdown
+	gatewayShutdownTimeout = 5 * time.Second
+)
+
+type (
+	// gateway is the http.Handler of the HTTP/JSON gateway.
+	gateway struct {
+		ps  ac.AthenzAgentServer
+		mux *http.ServeMux
+	}
+
+	// accessResponse is the JSON response of /v1/access.
+	accessResponse struct {
+		Status string `json:"status"`
+		Code   int32  `json:"code"`
+		// StalePolicyMode is set if the policies of the domain are expired
+		StalePolicyMode string `json:"stale_policy_mode,omitempty"`
+	}
+
+	// tokenResponse is the JSON response of /v1/token.
+	tokenResponse struct {
+		Token string `json:"token"`
+	}
+
+	// errorResponse is the JSON response of failed requests.
+	errorResponse struct {
+		Error   string `json:"error"`
+		Message string `json:"message"`
+	}
+)
+
+// RunHTTPGateway starts the HTTP/JSON gateway on the port and the address of
+// server config. It uses TLS if server mTLS is configured. waitGrp is done
+// after shutdown, or if the gateway fails to start.
+func RunHTTPGateway(ctx context.Context, ps ac.AthenzAgentServer, port string, waitGrp *sync.WaitGroup,
+	opts ...Option) error {
+
+	options := new(serverOptions)
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	properties := config.AgentConfig.Get().Server
+	listener, err := net.Listen("tcp", net.JoinHostPort(properties.Address, port))
+	if err != nil {
+		waitGrp.Done()
+		return err
+	}
+	credential, err := newServerCredential(ctx, properties.MtlsProperties, options.reloadOn, "http/1.1")
+	if err != nil {
+		_ = listener.Close()
+		waitGrp.Done()
+		return err
+	}
+	if credential != nil {
+		listener = tls.NewListener(listener, &tls.Config{GetConfigForClient: credential.configForClient})
+	}
+
+	server := &http.Server{Handler: newGateway(ps), ReadHeaderTimeout: 10 * time.Second}
+	return serveHTTP(ctx, "HTTP gateway", server, listener, gatewayShutdownTimeout, waitGrp)
+}
+
+// newGateway creates the gateway handler of the AthenzAgentServer.
+func newGateway(ps ac.AthenzAgentServer) http.Handler {
+	g := &gateway{ps: ps, mux: http.NewServeMux()}
+	g.mux.HandleFunc("/v1/access", g.checkAccess)
+	g.mux.HandleFunc("/v1/token", g.serviceToken)
+	return g
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// checkAccess handles /v1/access.
+func (g *gateway) checkAccess(w http.ResponseWriter, r *http.Request) {
+	if !isPost(w, r) {
+		return
+	}
+
+	req := new(v1.AccessCheckRequest)
+	if err := readRequest(r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.Access == "" {
+		req.Access = r.FormValue("access")
+	}
+	if req.Resource == "" {
+		req.Resource = r.FormValue("resource")
+	}
+	if req.Token == "" {
+		req.Token = roleToken(r)
+	}
+
+	ctx, err := gatewayContext(r, "CheckAccessWithToken")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := g.ps.CheckAccessWithToken(ctx, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	code := http.StatusOK
+	if resp.AccessCheckStatus == v1.AccessStatus_DENY_DOMAIN_LOADING {
+		// the client can retry when the domain is loaded
+		code = http.StatusServiceUnavailable
+		w.Header().Set("Retry-After", "1")
+	} else if resp.AccessCheckStatus != v1.AccessStatus_ALLOW {
+		code = http.StatusForbidden
+	}
+	response := accessResponse{Status: resp.AccessCheckStatus.String(), Code: int32(resp.AccessCheckStatus)}
+	if resp.StalePolicyMode != v1.StalePolicyMode_FRESH {
+		response.StalePolicyMode = resp.StalePolicyMode.String()
+	}
+	writeJSON(w, code, response)
+}
+
+// serviceToken handles /v1/token.
+func (g *gateway) serviceToken(w http.ResponseWriter, r *http.Request) {
+	if !isPost(w, r) {
+		return
+	}
+
+	req := new(v1.ServiceTokenRequest)
+	if err := readRequest(r, req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.Domain == "" {
+		req.Domain = r.FormValue("domain")
+	}
+	if len(req.Roles) == 0 && r.FormValue("roles") != "" {
+		req.Roles = strings.Split(r.FormValue("roles"), ",")
+	}
+	if req.ProxyForPrincipal == "" {
+		req.ProxyForPrincipal = r.FormValue("proxy_for_principal")
+	}
+	for key, value := range map[string]*int32{"min_expiry_time": &req.MinExpiryTime,
+		"max_expiry_time": &req.MaxExpiryTime} {
+		if *value != 0 || r.FormValue(key) == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(r.FormValue(key), 10, 32)
+		if err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid %s '%s'", key, r.FormValue(key)))
+			return
+		}
+		*value = int32(n)
+	}
+
+	ctx, err := gatewayContext(r, "GetServiceToken")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := g.ps.GetServiceToken(ctx, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tokenResponse{Token: resp.Token})
+}
+
+// readRequest decodes the JSON body of the request into message, if it has
+// a JSON body.
+func readRequest(r *http.Request, message proto.Message) error {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequestBodySize))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "unable to read request body, error: "+err.Error())
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	if err := protojson.Unmarshal(body, message); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid JSON request, error: "+err.Error())
+	}
+	return nil
+}
+
+// roleToken returns the role token of Athenz-Role-Auth header, or the token
+// of Authorization header with or without Bearer scheme.
+func roleToken(r *http.Request) string {
+	if token := r.Header.Get(RoleAuthHeader); token != "" {
+		return token
+	}
+	authorization := r.Header.Get("Authorization")
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "Bearer ") {
+		return authorization[7:]
+	}
+	return authorization
+}
+
+// isPost checks the request method is POST, otherwise it writes 405. Other
+// methods are rejected, so requests can't be sent by cross-site GET, e.g.
+// an image or a link.
+func isPost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodPost {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodPost)
+	writeError(w, status.Error(codes.Unimplemented, "method "+r.Method+" is not allowed"))
+	return false
+}
+
+// gatewayContext creates the context of the gRPC method with the peer and
+// the secret of HTTP request, and checks authorization rules.
+func gatewayContext(r *http.Request, method string) (context.Context, error) {
+	p := &peer.Peer{}
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		p.Addr = addr
+	}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS}
+	}
+	ctx := peer.NewContext(r.Context(), p)
+	if secret := r.Header.Get(SecretMetadataKey); secret != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(SecretMetadataKey, secret))
+	}
+
+	fullMethod := "/" + ac.AthenzAgent_ServiceDesc.ServiceName + "/" + method
+	if err := authorize(ctx, fullMethod); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// writeError writes the gRPC status of err with the matching HTTP status.
+func writeError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code := http.StatusInternalServerError
+	switch st.Code() {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.Unimplemented:
+		code = http.StatusMethodNotAllowed
+	case codes.Unavailable:
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, errorResponse{Error: st.Code().String(), Message: st.Message()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error("unable to write HTTP response, error: " + err.Error())
+	}
+}
@@ -0,0 +1,219 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 2:45 AM
+ *
+ * Description:
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	v1 "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAgentService records the requests and allows the "valid" token.
+type fakeAgentService struct {
+	accessRequest *v1.AccessCheckRequest
+	tokenRequest  *v1.ServiceTokenRequest
+}
+
+func (f *fakeAgentService) CheckAccessWithToken(_ context.Context,
+	req *v1.AccessCheckRequest) (*v1.AccessCheckResponse, error) {
+
+	f.accessRequest = req
+	if req.Token == "" {
+		return nil, status.Error(codes.InvalidArgument, "unable to create RoleToken")
+	}
+	if req.Token != "valid" {
+		return &v1.AccessCheckResponse{AccessCheckStatus: v1.AccessStatus_DENY_ROLE_TOKEN_INVALID}, nil
+	}
+	return &v1.AccessCheckResponse{AccessCheckStatus: v1.AccessStatus_ALLOW}, nil
+}
+
+func (f *fakeAgentService) CheckHTTPAccessWithToken(context.Context,
+	*v1.HTTPAccessCheckRequest) (*v1.HTTPAccessCheckResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func (f *fakeAgentService) GetServiceToken(_ context.Context,
+	req *v1.ServiceTokenRequest) (*v1.ServiceTokenResponse, error) {
+
+	f.tokenRequest = req
+	if req.Domain == "weather" {
+		return nil, status.Error(codes.PermissionDenied, "caller is not allowed to request the token")
+	}
+	return &v1.ServiceTokenResponse{Token: "v=Z1;d=" + req.Domain}, nil
+}
+
+func (f *fakeAgentService) GetAccessToken(context.Context, *v1.AccessTokenRequest) (*v1.AccessTokenResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "not implemented")
+}
+
+func TestGateway_CheckAccess(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+	defer allowAnyCaller()()
+
+	service := new(fakeAgentService)
+	server := httptest.NewServer(newGateway(service))
+	defer server.Close()
+
+	// JSON body and Athenz-Role-Auth header
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/access",
+		strings.NewReader(`{"access":"read","resource":"sports:articles"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RoleAuthHeader, "valid")
+	code, body := doRequest(t, req)
+	a.Equal(http.StatusOK, code)
+	a.Equal(map[string]interface{}{"status": "ALLOW", "code": float64(0)}, body)
+	a.Equal("read", service.accessRequest.Access)
+	a.Equal("sports:articles", service.accessRequest.Resource)
+
+	// query parameters and bearer token, like nginx auth_request
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/access?access=read&resource=sports:articles", nil)
+	req.Header.Set("Authorization", "Bearer invalid")
+	code, body = doRequest(t, req)
+	a.Equal(http.StatusForbidden, code)
+	a.Equal(map[string]interface{}{"status": "DENY_ROLE_TOKEN_INVALID", "code": float64(3)}, body)
+	a.Equal("invalid", service.accessRequest.Token)
+
+	// errors of the service are mapped to HTTP status
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/access", nil)
+	code, body = doRequest(t, req)
+	a.Equal(http.StatusBadRequest, code)
+	a.Equal("InvalidArgument", body["error"])
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/access", strings.NewReader(`{"unknown":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	code, _ = doRequest(t, req)
+	a.Equal(http.StatusBadRequest, code)
+
+	for _, method := range []string{http.MethodGet, http.MethodPut} {
+		req, _ = http.NewRequest(method, server.URL+"/v1/access?access=read&resource=sports:articles", nil)
+		req.Header.Set(RoleAuthHeader, "valid")
+		resp, err := http.DefaultClient.Do(req)
+		a.NoError(err)
+		a.Equal(http.StatusMethodNotAllowed, resp.StatusCode)
+		a.Equal(http.MethodPost, resp.Header.Get("Allow"))
+		_ = resp.Body.Close()
+	}
+
+	// authorization rules of gRPC methods apply to the gateway too
+	config.AgentConfig.Properties.Server.Authorization = []config.MethodRule{
+		{Method: "GetServiceToken", AllowAny: true}}
+	defer func() { config.AgentConfig.Properties.Server.Authorization = nil }()
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/access?access=read&resource=sports:articles", nil)
+	req.Header.Set(RoleAuthHeader, "valid")
+	code, body = doRequest(t, req)
+	a.Equal(http.StatusForbidden, code)
+	a.Equal("PermissionDenied", body["error"])
+}
+
+func TestGateway_ServiceToken(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+	defer allowAnyCaller()()
+
+	service := new(fakeAgentService)
+	server := httptest.NewServer(newGateway(service))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPost,
+		server.URL+"/v1/token?domain=sports&roles=reader,writer&min_expiry_time=600&max_expiry_time=3600", nil)
+	code, body := doRequest(t, req)
+	a.Equal(http.StatusOK, code)
+	a.Equal(map[string]interface{}{"token": "v=Z1;d=sports"}, body)
+	a.Equal([]string{"reader", "writer"}, service.tokenRequest.Roles)
+	a.Equal(int32(600), service.tokenRequest.MinExpiryTime)
+	a.Equal(int32(3600), service.tokenRequest.MaxExpiryTime)
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/token",
+		strings.NewReader(`{"domain":"sports","roles":["reader"],"proxy_for_principal":"user.jane"}`))
+	req.Header.Set("Content-Type", "application/json")
+	code, _ = doRequest(t, req)
+	a.Equal(http.StatusOK, code)
+	a.Equal([]string{"reader"}, service.tokenRequest.Roles)
+	a.Equal("user.jane", service.tokenRequest.ProxyForPrincipal)
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/token?min_expiry_time=soon", nil)
+	code, body = doRequest(t, req)
+	a.Equal(http.StatusBadRequest, code)
+	a.Equal("invalid min_expiry_time 'soon'", body["message"])
+
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/v1/token?domain=weather", nil)
+	code, body = doRequest(t, req)
+	a.Equal(http.StatusForbidden, code)
+	a.Equal("PermissionDenied", body["error"])
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/v1/token?domain=sports", nil)
+	code, _ = doRequest(t, req)
+	a.Equal(http.StatusMethodNotAllowed, code)
+}
+
+func TestRunHTTPGateway(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+	defer allowAnyCaller()()
+	config.AgentConfig.Properties.Server.MtlsProperties = config.MtlsProperties{}
+
+	httpPort := randomPort()
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- RunHTTPGateway(ctx, new(fakeAgentService), httpPort, wg)
+	}()
+
+	a.Eventually(func() bool {
+		resp, err := http.Post("http://127.0.0.1:"+httpPort+"/v1/token?domain=sports", "", nil)
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-stopped:
+		a.NoError(err)
+	case <-time.After(5 * time.Second):
+		a.Fail("gateway was not shut down")
+	}
+	wg.Wait()
+}
+
+func doRequest(t *testing.T, req *http.Request) (int, map[string]interface{}) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body := make(map[string]interface{})
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, body
+}
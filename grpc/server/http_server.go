@@ -0,0 +1,58 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 10:05 PM
+ *
+ * Description:
+ * serveHTTP runs the HTTP listeners of the agent, i.e. the HTTP gateway
+ * and the metrics listener, and shuts them down gracefully when the
+ * context is done or the process is interrupted.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// serveHTTP serves HTTP requests on the listener until ctx is done or the
+// process is interrupted. Running requests have shutdownTimeout to complete,
+// then waitGrp is done. name is used in the log messages.
+func serveHTTP(ctx context.Context, name string, server *http.Server, listener net.Listener,
+	shutdownTimeout time.Duration, waitGrp *sync.WaitGroup) error {
+
+	// graceful shutdown
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		defer signal.Stop(c)
+		select {
+		case <-c:
+		case <-ctx.Done():
+		}
+		logger.Info("shutting down 'athenz-agent' " + name + "...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+		waitGrp.Done()
+	}()
+
+	logger.Info("'athenz-agent' " + name + " listening on " + listener.Addr().String())
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 12:30 AM
+ *
+ * Description:
+ * The gRPC server listens on TCP address:port, on a unix domain socket, or
+ * on both. Peers of the unix socket are checked by their credentials
+ * (SO_PEERCRED), and the credentials are available in the peer address of
+ * the gRPC context as UnixPeer.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// staleSocketDialTimeout is the time to wait for an existing socket to
+	// accept a connection before it's removed as stale
+	staleSocketDialTimeout = time.Second
+)
+
+type (
+	// UnixPeer is the remote address of unix socket connections, it has the
+	// credentials of the client process.
+	UnixPeer struct {
+		net.Addr
+		PID int32
+		UID uint32
+		GID uint32
+	}
+
+	// peerListener is a unix socket listener that rejects the peers that
+	// are not allowed by server config.
+	peerListener struct {
+		net.Listener
+	}
+
+	// peerConn is an accepted unix socket connection with peer credentials.
+	peerConn struct {
+		net.Conn
+		peer *UnixPeer
+	}
+)
+
+// listen creates the listeners of server properties, a TCP listener if port
+// is set, and a unix socket listener if socket path is set.
+func listen(properties config.ServerProperties, port string) ([]net.Listener, error) {
+	if port == "" && properties.SocketPath == "" {
+		return nil, common.Error("server port or socket path is required")
+	}
+
+	var listeners []net.Listener
+	if port != "" {
+		listener, err := net.Listen("tcp", net.JoinHostPort(properties.Address, port))
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	if properties.SocketPath != "" {
+		listener, err := listenUnix(properties.SocketProperties)
+		if err != nil {
+			closeAll(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// listenUnix creates the unix socket with configured mode and ownership. A
+// stale socket of the previous run is removed, other files and sockets that
+// accept connections, e.g. of another running agent, are not.
+func listenUnix(properties config.SocketProperties) (net.Listener, error) {
+	mode, err := properties.FileMode()
+	if err != nil {
+		return nil, err
+	}
+	uid, gid, err := properties.Ownership()
+	if err != nil {
+		return nil, err
+	}
+
+	path := properties.SocketPath
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, common.Errorf("unable to create socket, %s exists and it's not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, staleSocketDialTimeout); err == nil {
+			_ = conn.Close()
+			return nil, common.Errorf("unable to create socket, %s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(path, uid, gid); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+	}
+	return &peerListener{Listener: listener}, nil
+}
+
+// Accept waits for the next allowed peer. Connections of other peers are
+// closed and logged, they are not returned as error because gRPC server
+// stops on accept errors.
+func (l *peerListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		properties := config.AgentConfig.Get().Server.SocketProperties
+		peer, err := peerCredentials(conn)
+		if err != nil {
+			if !properties.IsRestricted() {
+				return conn, nil
+			}
+			logger.Error("socket connection rejected, unable to get peer credentials, error: " + err.Error())
+			_ = conn.Close()
+			continue
+		}
+		if !properties.AllowsPeer(peer.UID, peer.GID) {
+			logger.Error(fmt.Sprintf("socket connection rejected, peer is not allowed, pid: %d, uid: %d, gid: %d",
+				peer.PID, peer.UID, peer.GID))
+			_ = conn.Close()
+			continue
+		}
+		return &peerConn{Conn: conn, peer: peer}, nil
+	}
+}
+
+// RemoteAddr returns the UnixPeer of the connection.
+func (c *peerConn) RemoteAddr() net.Addr {
+	return c.peer
+}
@@ -0,0 +1,172 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 12:50 AM
+ *
+ * Description:
+ *
+ */
+
+package server
+
+import (
+	"context"
+	ac "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/command/v1"
+	v1 "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/grpc/server/mock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunServerOnSocket(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "socket")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	socketPath := dir + "/run/agent.sock"
+
+	config.AgentConfig.Properties.Server.MtlsProperties = config.MtlsProperties{}
+	config.AgentConfig.Properties.Server.SocketProperties = config.SocketProperties{SocketPath: socketPath,
+		SocketMode: "0600"}
+	defer func() { config.AgentConfig.Properties.Server.SocketProperties = config.SocketProperties{} }()
+	defer allowAnyCaller()()
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		a.NoError(RunServer(ctx, new(mock.AthenzAgentService), "", wg))
+	}()
+	a.Eventually(func() bool {
+		_, err := os.Stat(socketPath)
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	info, err := os.Stat(socketPath)
+	a.NoError(err)
+	a.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	response, err := checkAccessOnSocket(socketPath)
+	a.NoError(err)
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_EMPTY, response.AccessCheckStatus)
+
+	cancel()
+	a.Eventually(func() bool {
+		_, err := os.Stat(socketPath)
+		return os.IsNotExist(err)
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestListenUnix_ExistingSocket(t *testing.T) {
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "socket")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	socketPath := dir + "/agent.sock"
+	properties := config.SocketProperties{SocketPath: socketPath, SocketMode: "0600"}
+
+	// a socket in use is not removed
+	running, err := net.Listen("unix", socketPath)
+	a.NoError(err)
+	_, err = listenUnix(properties)
+	a.Error(err)
+	a.Contains(err.Error(), "is in use by another process")
+
+	// a stale socket is replaced
+	running.(*net.UnixListener).SetUnlinkOnClose(false)
+	a.NoError(running.Close())
+	_, err = os.Stat(socketPath)
+	a.NoError(err)
+	listener, err := listenUnix(properties)
+	a.NoError(err)
+	a.NoError(listener.Close())
+}
+
+func TestPeerListener(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_PEERCRED is linux specific")
+	}
+	a := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "socket")
+	a.NoError(err)
+	defer os.RemoveAll(dir)
+	socketPath := dir + "/agent.sock"
+
+	// a stale socket is replaced, other files are not
+	a.NoError(ioutil.WriteFile(socketPath, []byte("file"), 0600))
+	_, err = listenUnix(config.SocketProperties{SocketPath: socketPath})
+	a.Error(err)
+	a.NoError(os.Remove(socketPath))
+	stale, err := net.Listen("unix", socketPath)
+	a.NoError(err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	a.NoError(stale.Close())
+
+	listener, err := listenUnix(config.SocketProperties{SocketPath: socketPath})
+	a.NoError(err)
+	defer listener.Close()
+
+	conn, err := net.Dial("unix", socketPath)
+	a.NoError(err)
+	defer conn.Close()
+
+	accepted, err := listener.Accept()
+	a.NoError(err)
+	defer accepted.Close()
+	peer, ok := accepted.RemoteAddr().(*UnixPeer)
+	a.True(ok)
+	a.Equal(uint32(os.Getuid()), peer.UID)
+	a.Equal(uint32(os.Getgid()), peer.GID)
+	a.Equal(int32(os.Getpid()), peer.PID)
+
+	// the peer is not allowed, so the connection is closed
+	config.AgentConfig.Properties.Server.SocketAllowedUIDs = []int{os.Getuid() + 1}
+	defer func() { config.AgentConfig.Properties.Server.SocketProperties = config.SocketProperties{} }()
+	go func() {
+		_, _ = listener.Accept()
+	}()
+	rejected, err := net.Dial("unix", socketPath)
+	a.NoError(err)
+	defer rejected.Close()
+	a.NoError(rejected.SetReadDeadline(time.Now().Add(5 * time.Second)))
+	_, err = rejected.Read(make([]byte, 1))
+	a.Equal(io.EOF, err)
+}
+
+func checkAccessOnSocket(socketPath string) (*v1.AccessCheckResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, socketPath, grpc.WithInsecure(), grpc.WithBlock(),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", addr)
+		}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	return ac.NewAthenzAgentClient(conn).CheckAccessWithToken(ctx,
+		&v1.AccessCheckRequest{Token: "token", Access: "access", Resource: "resource"})
+}
@@ -0,0 +1,55 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:10 PM
+ *
+ * Description:
+ * The metrics listener serves the expvar metrics of the agent, e.g.
+ * server_mtls and stale_policies, as JSON on GET /debug/vars. It listens
+ * on metrics_address of server config, separately from the gRPC server
+ * and HTTP gateway, so it can be bound to a loopback or internal address.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// metricsShutdownTimeout is the time that running metrics requests have
+	// to complete on shutdown
+	metricsShutdownTimeout = time.Second
+)
+
+// RunMetricsServer serves the expvar metrics on the address until ctx is
+// done or the process is interrupted. waitGrp is done after shutdown, or if
+// the listener fails to start.
+func RunMetricsServer(ctx context.Context, address string, waitGrp *sync.WaitGroup) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		waitGrp.Done()
+		return err
+	}
+	server := &http.Server{Handler: NewMetricsHandler(), ReadHeaderTimeout: 10 * time.Second}
+	return serveHTTP(ctx, "metrics listener", server, listener, metricsShutdownTimeout, waitGrp)
+}
+
+// NewMetricsHandler creates the handler of /debug/vars.
+func NewMetricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
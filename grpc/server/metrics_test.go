@@ -0,0 +1,67 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 9:25 PM
+ *
+ * Description:
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunMetricsServer(t *testing.T) {
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	a := assert.New(t)
+	mtlsMetrics.Add("reloads", 1)
+
+	address := "127.0.0.1:" + randomPort()
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- RunMetricsServer(ctx, address, wg)
+	}()
+
+	var metrics map[string]json.RawMessage
+	a.Eventually(func() bool {
+		resp, err := http.Get("http://" + address + "/debug/vars")
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&metrics) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	var mtls map[string]interface{}
+	if a.Contains(metrics, "server_mtls") {
+		a.NoError(json.Unmarshal(metrics["server_mtls"], &mtls))
+		a.Contains(mtls, "reloads")
+	}
+
+	cancel()
+	select {
+	case err := <-stopped:
+		a.NoError(err)
+	case <-time.After(5 * time.Second):
+		a.Fail("metrics listener was not shut down")
+	}
+	wg.Wait()
+}
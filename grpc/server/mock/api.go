@@ -29,6 +29,14 @@ func (m AthenzAgentService) CheckAccessWithToken(ctx context.Context, request *v
 	return &v1.AccessCheckResponse{AccessCheckStatus: v1.AccessStatus_DENY_DOMAIN_EMPTY}, nil
 }
 
+func (m AthenzAgentService) CheckHTTPAccessWithToken(ctx context.Context, request *v1.HTTPAccessCheckRequest) (*v1.HTTPAccessCheckResponse, error) {
+	panic("implement me")
+}
+
 func (m AthenzAgentService) GetServiceToken(ctx context.Context, request *v1.ServiceTokenRequest) (*v1.ServiceTokenResponse, error) {
 	panic("implement me")
 }
+
+func (m AthenzAgentService) GetAccessToken(ctx context.Context, request *v1.AccessTokenRequest) (*v1.AccessTokenResponse, error) {
+	panic("implement me")
+}
@@ -0,0 +1,47 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 12:40 AM
+ *
+ * Description:
+ * peerCredentials reads the credentials of unix socket peer by SO_PEERCRED.
+ *
+ */
+
+package server
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"net"
+	"syscall"
+)
+
+// peerCredentials returns the pid, uid and gid of the peer process.
+func peerCredentials(conn net.Conn) (*UnixPeer, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, common.Error("peer credentials are only available for unix socket connections")
+	}
+	rawConn, err := unixConn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var ucred *syscall.Ucred
+	var credErr error
+	if err := rawConn.Control(func(fd uintptr) {
+		ucred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return nil, err
+	}
+	if credErr != nil {
+		return nil, credErr
+	}
+	return &UnixPeer{Addr: conn.RemoteAddr(), PID: ucred.Pid, UID: ucred.Uid, GID: ucred.Gid}, nil
+}
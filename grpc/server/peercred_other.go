@@ -0,0 +1,31 @@
+//go:build !linux
+// +build !linux
+
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 12:40 AM
+ *
+ * Description:
+ * SO_PEERCRED is linux specific, peers of the socket can't be restricted
+ * on other platforms.
+ *
+ */
+
+package server
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"net"
+)
+
+// peerCredentials returns an error, it's not supported on this platform.
+func peerCredentials(net.Conn) (*UnixPeer, error) {
+	return nil, common.Error("peer credentials are not supported on this platform")
+}
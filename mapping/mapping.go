@@ -0,0 +1,350 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 4:20 AM
+ *
+ * Description:
+ * This file maps HTTP requests to Athenz action and resource, so the
+ * callers behind a proxy don't need to build them. Rules are checked in
+ * order and the first rule that matches the method, the path template and
+ * the extracted headers and query parameters is used:
+ *		- path template segments are literals, `*` for any segment,
+ *		  `{name}` for a variable segment and `{name*}` for the rest
+ *		  of path, e.g. /api/{version}/articles/{id*}
+ *		- headers and query map variable names to header names and
+ *		  query parameters, the rule doesn't match if one is missing
+ *		- action and resource are templates of the variables, {method}
+ *		  and {path} are always defined
+ *		- the path is cleaned before matching, decoded segment variables
+ *		  must not have '/', ':' or be a dot segment, and the rest of
+ *		  path is cleaned after decoding
+ *
+ */
+
+package mapping
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// variableName is the pattern of template variables, they are lower case
+	// because config keys of headers and query are lower cased
+	variableName = regexp.MustCompile(`^[a-z0-9_]+$`)
+	// placeholder is a variable of action and resource templates
+	placeholder = regexp.MustCompile(`{([^{}]*)}`)
+)
+
+type (
+	// Rule maps the matching HTTP requests to an action and a resource.
+	Rule struct {
+		// HTTP methods, empty matches all methods
+		Methods []string `mapstructure:"methods"`
+		// path template, e.g. /api/{version}/articles/{id}
+		Path string `mapstructure:"path"`
+		// action template, default is {method}, the lower case HTTP method
+		Action string `mapstructure:"action"`
+		// resource template, a resource without domain prefix belongs to
+		// the domain of role token
+		Resource string `mapstructure:"resource"`
+		// variable name to header name
+		Headers map[string]string `mapstructure:"headers"`
+		// variable name to query parameter
+		Query map[string]string `mapstructure:"query"`
+	}
+
+	// Request describes an HTTP request. Path may have query.
+	Request struct {
+		Method  string
+		Path    string
+		Headers map[string]string
+	}
+
+	// Mapping is the action and the resource of a request.
+	Mapping struct {
+		Action   string
+		Resource string
+	}
+
+	// Mapper maps HTTP requests by a list of rules.
+	Mapper interface {
+		// Map returns the mapping of the first matching rule, or false if
+		// no rule matches.
+		Map(request Request) (Mapping, bool)
+	}
+
+	mapper struct {
+		rules []*compiledRule
+	}
+
+	compiledRule struct {
+		Rule
+		methods  map[string]bool
+		segments []segment
+	}
+
+	// segment is a segment of path template, a segment without literal and
+	// variable matches any segment.
+	segment struct {
+		literal  string
+		variable string
+		// rest matches the rest of path
+		rest bool
+	}
+)
+
+// NewMapper compiles the rules and returns a Mapper. It returns the error
+// of the first invalid rule.
+func NewMapper(rules []Rule) (Mapper, error) {
+	m := &mapper{rules: make([]*compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		compiled, err := compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %s", i, err.Error())
+		}
+		m.rules = append(m.rules, compiled)
+	}
+	return m, nil
+}
+
+// Default returns the mapping of the requests that don't match any rule, the
+// lower case method and the cleaned path without query.
+func Default(request Request) Mapping {
+	requestPath, _ := splitPath(request.Path)
+	return Mapping{Action: strings.ToLower(request.Method), Resource: cleanPath(requestPath)}
+}
+
+// Validate checks the path template, the variables and the templates of the
+// rule.
+func (r Rule) Validate() error {
+	_, err := compile(r)
+	return err
+}
+
+func (m *mapper) Map(request Request) (Mapping, bool) {
+	path, rawQuery := splitPath(request.Path)
+	if !strings.HasPrefix(path, "/") {
+		return Mapping{}, false
+	}
+	path = cleanPath(path)
+	headers := make(map[string]string, len(request.Headers))
+	for name, value := range request.Headers {
+		headers[strings.ToLower(name)] = value
+	}
+	query, _ := url.ParseQuery(rawQuery)
+	method := strings.ToUpper(request.Method)
+
+	for _, rule := range m.rules {
+		if len(rule.methods) > 0 && !rule.methods[method] {
+			continue
+		}
+		variables, ok := rule.matchPath(path)
+		if !ok {
+			continue
+		}
+		if !extract(variables, rule.Headers, func(name string) string { return headers[strings.ToLower(name)] }) ||
+			!extract(variables, rule.Query, query.Get) {
+			continue
+		}
+		variables["method"] = strings.ToLower(method)
+		variables["path"] = path
+
+		expand := func(template string) string {
+			return placeholder.ReplaceAllStringFunc(template, func(s string) string {
+				return variables[s[1:len(s)-1]]
+			})
+		}
+		return Mapping{Action: expand(rule.Action), Resource: expand(rule.Resource)}, true
+	}
+	return Mapping{}, false
+}
+
+// compile parses the path template and checks that the templates only use
+// defined variables.
+func compile(rule Rule) (*compiledRule, error) {
+	if !strings.HasPrefix(rule.Path, "/") {
+		return nil, fmt.Errorf("path must start with '/', value: '%s'", rule.Path)
+	}
+	if rule.Resource == "" {
+		return nil, fmt.Errorf("resource is required")
+	}
+	if rule.Action == "" {
+		rule.Action = "{method}"
+	}
+
+	compiled := &compiledRule{Rule: rule, methods: make(map[string]bool)}
+	for _, method := range rule.Methods {
+		compiled.methods[strings.ToUpper(method)] = true
+	}
+
+	defined := map[string]bool{"method": true, "path": true}
+	define := func(name string) error {
+		if !variableName.MatchString(name) {
+			return fmt.Errorf("invalid variable name '%s'", name)
+		}
+		if defined[name] {
+			return fmt.Errorf("variable '%s' is defined more than once", name)
+		}
+		defined[name] = true
+		return nil
+	}
+
+	parts := segments(rule.Path)
+	for i, part := range parts {
+		var s segment
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("path must not have empty segments, value: '%s'", rule.Path)
+		case part == "*":
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "*}"):
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("'%s' must be the last segment of path", part)
+			}
+			s.variable, s.rest = part[1:len(part)-2], true
+		case strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}"):
+			s.variable = part[1 : len(part)-1]
+		case strings.ContainsAny(part, "{}*"):
+			return nil, fmt.Errorf("path segment '%s' must be a literal, *, {name} or {name*}", part)
+		default:
+			s.literal = part
+		}
+		if strings.HasPrefix(part, "{") {
+			if err := define(s.variable); err != nil {
+				return nil, err
+			}
+		}
+		compiled.segments = append(compiled.segments, s)
+	}
+
+	for _, variables := range []map[string]string{rule.Headers, rule.Query} {
+		for _, name := range sortedKeys(variables) {
+			if err := define(name); err != nil {
+				return nil, err
+			}
+			if variables[name] == "" {
+				return nil, fmt.Errorf("variable '%s' has no header or query parameter", name)
+			}
+		}
+	}
+
+	for _, template := range []string{rule.Action, rule.Resource} {
+		if strings.Count(template, "{") != strings.Count(template, "}") {
+			return nil, fmt.Errorf("unbalanced braces in '%s'", template)
+		}
+		for _, match := range placeholder.FindAllStringSubmatch(template, -1) {
+			if !defined[match[1]] {
+				return nil, fmt.Errorf("undefined variable '%s' in '%s'", match[1], template)
+			}
+		}
+	}
+	return compiled, nil
+}
+
+// matchPath matches the path with the template and returns the variables of
+// path segments. Decoded segments that would change the resource structure,
+// i.e. that have '/' or ':' or are dot segments, don't match a variable.
+func (r *compiledRule) matchPath(path string) (map[string]string, bool) {
+	parts := segments(path)
+
+	variables := make(map[string]string)
+	for i, s := range r.segments {
+		if s.rest {
+			value, err := url.PathUnescape(strings.Join(parts[i:], "/"))
+			if err != nil {
+				return nil, false
+			}
+			value = strings.TrimPrefix(cleanPath("/"+value), "/")
+			if strings.Contains(value, ":") {
+				return nil, false
+			}
+			variables[s.variable] = value
+			return variables, true
+		}
+		if i >= len(parts) || parts[i] == "" {
+			return nil, false
+		}
+		value, err := url.PathUnescape(parts[i])
+		if err != nil {
+			return nil, false
+		}
+		switch {
+		case s.literal != "" && s.literal != value:
+			return nil, false
+		case s.variable != "":
+			if value == "." || value == ".." || strings.ContainsAny(value, "/:") {
+				return nil, false
+			}
+			variables[s.variable] = value
+		}
+	}
+	return variables, len(parts) == len(r.segments)
+}
+
+// extract sets the variables from the values of lookup, it returns false if
+// a value is missing.
+func extract(variables, names map[string]string, lookup func(string) string) bool {
+	for variable, name := range names {
+		value := lookup(name)
+		if value == "" {
+			return false
+		}
+		variables[variable] = value
+	}
+	return true
+}
+
+// splitPath splits the query of the path and removes its fragment. An empty
+// path is "/".
+func splitPath(path string) (string, string) {
+	if i := strings.IndexByte(path, '#'); i >= 0 {
+		path = path[:i]
+	}
+	var query string
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+	if path == "" {
+		path = "/"
+	}
+	return path, query
+}
+
+// cleanPath removes dot segments and duplicate slashes of the absolute path,
+// the trailing slash is kept.
+func cleanPath(p string) string {
+	cleaned := path.Clean(p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// segments splits the absolute path into its segments, the trailing slash is
+// ignored.
+func segments(path string) []string {
+	path = strings.TrimSuffix(path[1:], "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
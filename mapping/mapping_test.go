@@ -0,0 +1,142 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 4:50 AM
+ *
+ * Description:
+ *
+ */
+
+package mapping
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMapper_Map(t *testing.T) {
+	a := assert.New(t)
+
+	mapper, err := NewMapper([]Rule{
+		{Methods: []string{"get", "HEAD"}, Path: "/api/{version}/articles/{id}", Action: "read",
+			Resource: "sports:articles.{id}"},
+		{Methods: []string{"POST", "PUT"}, Path: "/api/*/articles", Action: "write",
+			Resource: "articles.{tenant}", Headers: map[string]string{"tenant": "X-Tenant"}},
+		{Path: "/files/{file*}", Resource: "files.{file}.{page}", Query: map[string]string{"page": "page"}},
+		{Path: "/", Resource: "web{path}"},
+	})
+	a.NoError(err)
+
+	mapping, ok := mapper.Map(Request{Method: "GET", Path: "/api/v1/articles/42?fields=title"})
+	a.True(ok)
+	a.Equal(Mapping{Action: "read", Resource: "sports:articles.42"}, mapping)
+
+	// the trailing slash is ignored and escaped segments are decoded
+	mapping, ok = mapper.Map(Request{Method: "head", Path: "/api/v1/articles/a%20b/"})
+	a.True(ok)
+	a.Equal("sports:articles.a b", mapping.Resource)
+
+	_, ok = mapper.Map(Request{Method: "DELETE", Path: "/api/v1/articles/42"})
+	a.False(ok)
+	_, ok = mapper.Map(Request{Method: "GET", Path: "/api/v1/articles/42/comments"})
+	a.False(ok)
+
+	// header variables, the rule doesn't match without the header
+	mapping, ok = mapper.Map(Request{Method: "POST", Path: "/api/v2/articles",
+		Headers: map[string]string{"x-tenant": "acme"}})
+	a.True(ok)
+	a.Equal(Mapping{Action: "write", Resource: "articles.acme"}, mapping)
+	_, ok = mapper.Map(Request{Method: "POST", Path: "/api/v2/articles"})
+	a.False(ok)
+
+	// rest of path and query variables, action is the lower case method
+	mapping, ok = mapper.Map(Request{Method: "DELETE", Path: "/files/2026/report.pdf?page=3"})
+	a.True(ok)
+	a.Equal(Mapping{Action: "delete", Resource: "files.2026/report.pdf.3"}, mapping)
+	_, ok = mapper.Map(Request{Method: "DELETE", Path: "/files/2026/report.pdf"})
+	a.False(ok)
+
+	mapping, ok = mapper.Map(Request{Method: "GET", Path: ""})
+	a.True(ok)
+	a.Equal(Mapping{Action: "get", Resource: "web/"}, mapping)
+
+	_, ok = mapper.Map(Request{Method: "GET", Path: "/index.html"})
+	a.False(ok)
+	a.Equal(Mapping{Action: "get", Resource: "/index.html"}, Default(Request{Method: "GET", Path: "/index.html?a=1"}))
+	a.Equal(Mapping{Action: "get", Resource: "/admin/"}, Default(Request{Method: "GET", Path: "/public/..//admin/"}))
+}
+
+func TestMapper_MapUnsafePath(t *testing.T) {
+	a := assert.New(t)
+
+	mapper, err := NewMapper([]Rule{
+		{Path: "/api/{version}/articles/{id}", Action: "read", Resource: "sports:articles.{id}"},
+		{Path: "/files/{file*}", Resource: "files.{file}"},
+	})
+	a.NoError(err)
+
+	tests := []struct {
+		path     string
+		resource string
+	}{
+		// decoded segment variables must not change the resource structure
+		{path: "/api/v1/articles/a%2Fb"},
+		{path: "/api/v1/articles/weather%3Asecret"},
+		{path: "/api/v1/articles/%2e%2e"},
+		{path: "/api/v1/articles/."},
+		// the path is cleaned before matching
+		{path: "/api/v1/articles/42/../43", resource: "sports:articles.43"},
+		{path: "/api/v1/x/../articles//42", resource: "sports:articles.42"},
+		{path: "/files/../api/v1/articles/42", resource: "sports:articles.42"},
+		// the rest of path is cleaned after decoding
+		{path: "/files/a/%2e%2e/%2e%2e/%2e%2e/etc/passwd", resource: "files.etc/passwd"},
+		{path: "/files/a%2F%2Fb/./c", resource: "files.a/b/c"},
+		{path: "/files/a/weather%3Asecret"},
+	}
+	for _, test := range tests {
+		mapping, ok := mapper.Map(Request{Method: "GET", Path: test.path})
+		if test.resource == "" {
+			a.False(ok, test.path)
+			continue
+		}
+		if a.True(ok, test.path) {
+			a.Equal(test.resource, mapping.Resource, test.path)
+		}
+	}
+}
+
+func TestRule_Validate(t *testing.T) {
+	a := assert.New(t)
+
+	a.NoError(Rule{Path: "/api/{id}", Resource: "api.{id}.{method}"}.Validate())
+
+	invalid := map[string]Rule{
+		"path must start with '/', value: 'api'":                       {Path: "api", Resource: "api"},
+		"resource is required":                                         {Path: "/api"},
+		"path must not have empty segments, value: '/api//x'":          {Path: "/api//x", Resource: "api"},
+		"'{rest*}' must be the last segment of path":                   {Path: "/{rest*}/x", Resource: "api"},
+		"path segment 'v{id}' must be a literal, *, {name} or {name*}": {Path: "/v{id}", Resource: "api"},
+		"invalid variable name 'Id'":                                   {Path: "/{Id}", Resource: "api"},
+		"variable 'path' is defined more than once":                    {Path: "/{path}", Resource: "api"},
+		"variable 'id' is defined more than once":                      {Path: "/{id}", Resource: "api", Query: map[string]string{"id": "id"}},
+		"variable 'tenant' has no header or query parameter":           {Path: "/", Resource: "api", Headers: map[string]string{"tenant": ""}},
+		"unbalanced braces in 'api.{id'":                               {Path: "/{id}", Resource: "api.{id"},
+		"undefined variable 'name' in 'api.{name}'":                    {Path: "/{id}", Resource: "api.{name}"},
+		"undefined variable 'verb' in '{verb}'":                        {Path: "/", Action: "{verb}", Resource: "api"},
+	}
+	for message, rule := range invalid {
+		err := rule.Validate()
+		if a.Error(err) {
+			a.Equal(message, err.Error())
+		}
+	}
+
+	_, err := NewMapper([]Rule{{Path: "/", Resource: "web"}, {Path: "web"}})
+	a.EqualError(err, "rule 1: path must start with '/', value: 'web'")
+}
@@ -32,25 +32,34 @@ var (
 
 type (
 	// cacheMonitor is an implementation of monitor for monitoring policy caching.
-	cacheMonitor struct{}
+	cacheMonitor struct {
+		// reload wakes up the monitor when ZPE config changes at runtime
+		reload chan struct{}
+	}
 )
 
 // NewCacheMonitor creates new instance of Monitor type from cacheMonitor.
 func NewCacheMonitor() Monitor {
-	return cacheMonitor{}
+	c := cacheMonitor{reload: make(chan struct{}, 1)}
+	config.ZpeConfig.OnChange(func() { wakeUp(c.reload) })
+	return c
 }
 
 func (c cacheMonitor) Start(cacheChan chan<- string) {
 	for {
+		zpeProperties := config.ZpeConfig.Get()
 		cacheLogger.Info("Cleanup role token cache...")
 		cache.CleanupRoleTokenCache()
-		files, err := common.LoadFileStatus(config.ZpeConfig.Properties.PolicyFilesDir)
-		if err != nil {
+		cacheLogger.Info("Start caching policy files...")
+		if err := cache.LoadDirectory(zpeProperties.PolicyFilesDir); err != nil {
 			cacheLogger.Error(err.Error())
 			cacheChan <- fmt.Sprintf("unable to read policy directory, error: %s", err.Error())
 		}
-		cacheLogger.Info("Start caching policy files...")
-		cache.LoadDB(files)
-		<-time.After(time.Duration(config.ZpeConfig.Properties.CleanupTokenInterval) * time.Second)
+		if zpeProperties.PolicySnapshotFile != "" {
+			if err := cache.SaveSnapshot(zpeProperties.PolicySnapshotFile); err != nil {
+				cacheLogger.Error(err.Error())
+			}
+		}
+		sleep(time.Duration(zpeProperties.CleanupTokenInterval)*time.Second, c.reload)
 	}
 }
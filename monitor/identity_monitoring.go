@@ -0,0 +1,179 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 11:15 PM
+ *
+ * Description:
+ * identityMonitor refreshes the service identity certificate before it
+ * expires, if sia_provider is set in zpe config. Listeners are notified
+ * after a new certificate is written, e.g. to reload server credentials.
+ *
+ */
+
+package monitor
+
+import (
+	"crypto/x509"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"sync"
+	"time"
+)
+
+const (
+	// identityTimeout is the timeout of each ZTS request
+	identityTimeout = 30 * time.Second
+	// identityRetryInterval is the wait time after a failed download
+	identityRetryInterval = time.Minute
+	// certRefreshFraction is the fraction of certificate lifetime that
+	// triggers the refresh
+	certRefreshFraction = 1.0 / 3
+)
+
+var (
+	identityLogger = log.GetLogger(common.GolangFileName())
+)
+
+type (
+	// IdentityMonitor is a Monitor that notifies listeners when the service
+	// identity certificate changes.
+	IdentityMonitor interface {
+		Monitor
+		// Init gets a certificate if there is no valid one, so other
+		// components can start with it
+		Init() error
+		// OnChange registers a function that is called after a new
+		// certificate is written, the returned function unregisters it
+		OnChange(fn func()) func()
+	}
+
+	// identityMonitor is an implementation of IdentityMonitor.
+	identityMonitor struct {
+		// reload wakes up the monitor when ZPE config changes at runtime
+		reload chan struct{}
+
+		mu        sync.Mutex
+		listeners []*identityListener
+	}
+
+	// identityListener wraps a listener, so it can be found on unsubscribe.
+	identityListener struct {
+		fn func()
+	}
+)
+
+// NewIdentityMonitor creates new instance of IdentityMonitor type.
+func NewIdentityMonitor() IdentityMonitor {
+	i := &identityMonitor{reload: make(chan struct{}, 1)}
+	config.ZpeConfig.OnChange(func() { wakeUp(i.reload) })
+	return i
+}
+
+// Init downloads a certificate if sia is enabled and there is no valid one.
+func (i *identityMonitor) Init() error {
+	if config.ZpeConfig.Get().SiaProvider == "" {
+		return nil
+	}
+	d := newIdentityDownloader()
+	if d.Certificate() != nil {
+		return nil
+	}
+	if _, err := d.DownloadIdentity(); err != nil {
+		return err
+	}
+	i.publish()
+	return nil
+}
+
+// Start refreshes the certificate when certRefreshFraction of its lifetime
+// remains. Download failures are only logged and retried, the current
+// certificate is valid until it expires, so Start never sends an error to
+// the channel.
+func (i *identityMonitor) Start(chan<- string) {
+	for {
+		// sia is disabled, wait for config changes
+		if config.ZpeConfig.Get().SiaProvider == "" {
+			<-i.reload
+			continue
+		}
+
+		d := newIdentityDownloader()
+		cert := d.Certificate()
+		if cert == nil || !time.Now().Before(refreshTime(cert)) {
+			identityLogger.Info("Start downloading service identity certificate...")
+			newCert, err := d.DownloadIdentity()
+			if err != nil {
+				identityLogger.Error(err.Error())
+				sleep(identityRetryInterval, i.reload)
+				continue
+			}
+			cert = newCert
+			i.publish()
+		}
+		sleep(time.Until(refreshTime(cert)), i.reload)
+	}
+}
+
+// OnChange registers a listener of certificate changes and returns a
+// function that unregisters it.
+func (i *identityMonitor) OnChange(fn func()) func() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	l := &identityListener{fn: fn}
+	i.listeners = append(i.listeners, l)
+	return func() {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		for n, listener := range i.listeners {
+			if listener == l {
+				i.listeners = append(i.listeners[:n:n], i.listeners[n+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// publish calls the listeners.
+func (i *identityMonitor) publish() {
+	i.mu.Lock()
+	listeners := append([]*identityListener{}, i.listeners...)
+	i.mu.Unlock()
+	for _, l := range listeners {
+		l.fn()
+	}
+}
+
+// refreshTime returns the time that certRefreshFraction of the certificate
+// lifetime remains.
+func refreshTime(cert *x509.Certificate) time.Time {
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return cert.NotAfter.Add(-time.Duration(float64(lifetime) * certRefreshFraction))
+}
+
+// newIdentityDownloader creates an IdentityDownloader with the current
+// configs.
+func newIdentityDownloader() downloader.IdentityDownloader {
+	zpeProperties := config.ZpeConfig.Get()
+	return downloader.NewIdentityDownloader(downloader.IdentityConfig{
+		ZtsURL:              config.KeyStore.Get().ZtsUrl,
+		Provider:            zpeProperties.SiaProvider,
+		Domain:              zpeProperties.DomainName,
+		Service:             zpeProperties.ServiceName,
+		InstanceID:          zpeProperties.SiaInstanceID,
+		DNSSuffix:           zpeProperties.SiaDNSSuffix,
+		AttestationDataFile: zpeProperties.SiaAttestationDataFile,
+		CertFile:            zpeProperties.CertFilePath,
+		KeyFile:             zpeProperties.KeyFilePath,
+		CACertFile:          zpeProperties.SiaCACertFile,
+		ExpiryTime:          zpeProperties.SiaCertExpiryTime,
+		Timeout:             identityTimeout,
+	})
+}
@@ -0,0 +1,92 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/19/26
+ * Time: 5:05 PM
+ *
+ * Description:
+ *
+ */
+
+package monitor
+
+import (
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"time"
+)
+
+const (
+	// keyDownloadTimeout is the timeout of each ZTS request
+	keyDownloadTimeout = 30 * time.Second
+)
+
+var (
+	keyLogger = log.GetLogger(common.GolangFileName())
+)
+
+type (
+	// keyMonitor is an implementation of monitor. It refreshes ZTS and ZMS
+	// public keys periodically.
+	keyMonitor struct {
+		// reload wakes up the monitor when ZPE config changes at runtime
+		reload chan struct{}
+	}
+)
+
+// NewKeyMonitor creates new instance Monitor type from keyMonitor.
+func NewKeyMonitor() Monitor {
+	k := keyMonitor{reload: make(chan struct{}, 1)}
+	config.ZpeConfig.OnChange(func() { wakeUp(k.reload) })
+	return k
+}
+
+// Start loads the cached public keys and refreshes them periodically. Key
+// download failures are only logged, the static and cached keys are still
+// valid, so Start never sends an error to the channel.
+func (k keyMonitor) Start(chan<- string) {
+	LoadCachedKeys()
+
+	for {
+		interval := config.ZpeConfig.Get().PublicKeysRefreshInterval
+		// fetching public keys is disabled, wait for config changes
+		if interval <= 0 {
+			<-k.reload
+			continue
+		}
+
+		keyLogger.Info("Start downloading public keys...")
+		if err := newKeyDownloader().DownloadKeys(); err != nil {
+			keyLogger.Error(err.Error())
+		}
+		sleep(time.Duration(interval)*time.Second, k.reload)
+	}
+}
+
+// LoadCachedKeys loads the public keys of the last successful download from
+// the public keys cache file of ZPE config, errors are only logged.
+func LoadCachedKeys() {
+	if err := newKeyDownloader().LoadCachedKeys(); err != nil {
+		keyLogger.Error(err.Error())
+	}
+}
+
+// newKeyDownloader creates a KeyDownloader with the current configs.
+func newKeyDownloader() downloader.KeyDownloader {
+	zpeProperties := config.ZpeConfig.Get()
+	client, err := downloader.NewZtsHTTPClient(zpeProperties.CertFilePath, zpeProperties.KeyFilePath,
+		keyDownloadTimeout)
+	if err != nil {
+		keyLogger.Error(err.Error())
+		client, _ = downloader.NewZtsHTTPClient("", "", keyDownloadTimeout)
+	}
+	return downloader.NewKeyDownloader(config.KeyStore.Get().ZtsUrl, client,
+		zpeProperties.PublicKeysCacheFile, config.KeyStore)
+}
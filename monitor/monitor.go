@@ -16,6 +16,8 @@
 
 package monitor
 
+import "time"
+
 type (
 
 	// Monitor monitors a process.
@@ -26,3 +28,20 @@ type (
 		Start(chan<- string)
 	}
 )
+
+// sleep blocks until the duration elapsed or a reload signal received.
+func sleep(d time.Duration, reload <-chan struct{}) {
+	select {
+	case <-time.After(d):
+	case <-reload:
+	}
+}
+
+// wakeUp sends a reload signal without blocking. The signal is dropped if
+// there is a pending one.
+func wakeUp(reload chan<- struct{}) {
+	select {
+	case reload <- struct{}{}:
+	default:
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/hamed-yousefi/athenz-agent/common/log"
 	"github.com/hamed-yousefi/athenz-agent/config"
 	"github.com/hamed-yousefi/athenz-agent/downloader"
+	"strings"
 	"time"
 )
 
@@ -31,25 +32,71 @@ var (
 type (
 	// zpuMonitor is an implementation of monitor. It monitors ZPU policy
 	// downloader.
-	zpuMonitor struct{}
+	zpuMonitor struct {
+		// reload wakes up the monitor when ZPE or ZPU config changes at runtime
+		reload chan struct{}
+	}
 )
 
 // NewZpuMonitor creates new instance Monitor type from zpuMonitor.
 func NewZpuMonitor() Monitor {
-	return zpuMonitor{}
+	z := zpuMonitor{reload: make(chan struct{}, 1)}
+	config.ZpeConfig.OnChange(func() {
+		pruneSubscriptions()
+		wakeUp(z.reload)
+	})
+	config.ZpuConfig.OnChange(func() { wakeUp(z.reload) })
+	return z
 }
 
 // Start starts a process and monitor it. Most of the time this function
 // runs in a separate goroutine, because of that it accept a channel as
 // input argument.
 func (z zpuMonitor) Start(downloadChan chan<- string) {
+	restoreSubscriptions()
 	for {
 		zpuLogger.Info("Start downloading policy files...")
-		err := downloader.NewPolicyDownloader(config.ZpuConfig.Properties).DownloadPolicies()
+		zpuConfig := config.ZpuConfig.Get()
+		if zpuConfig == nil {
+			zpuLogger.Error("zpu config is not loaded")
+			downloadChan <- "Policy updator failed, zpu config is not loaded"
+			sleep(time.Duration(config.ZpeConfig.Get().ZpuDownloadInterval)*time.Second, z.reload)
+			continue
+		}
+		// subscribed domains are downloaded with the domains of ZPU config
+		zpuProperties := *zpuConfig
+		zpuProperties.DomainList = downloader.Subscriptions.DomainList(zpuProperties.DomainList)
+		err := downloader.NewPolicyDownloader(&zpuProperties).DownloadPolicies()
 		if err != nil {
 			zpuLogger.Error(err.Error())
 			downloadChan <- fmt.Sprintf("Policy updator failed, %s", err.Error())
 		}
-		<-time.After(time.Duration(config.ZpeConfig.Properties.ZpuDownloadInterval) * time.Second)
+		sleep(time.Duration(config.ZpeConfig.Get().ZpuDownloadInterval)*time.Second, z.reload)
+	}
+}
+
+// restoreSubscriptions subscribes the dynamic domains that were subscribed
+// before the agent restarted.
+func restoreSubscriptions() {
+	dynamicDomains := config.ZpeConfig.Get().DynamicDomains
+	if len(dynamicDomains.Allow) == 0 || dynamicDomains.SubscriptionsFile == "" {
+		return
+	}
+	if err := downloader.Subscriptions.Restore(dynamicDomains.SubscriptionsFile, dynamicDomains.Allows); err != nil {
+		zpuLogger.Error("unable to restore subscribed domains, error: " + err.Error())
+	}
+}
+
+// pruneSubscriptions unsubscribes the domains that dynamic_domains doesn't
+// allow anymore, their policy files are not downloaded from then on.
+func pruneSubscriptions() {
+	dynamicDomains := config.ZpeConfig.Get().DynamicDomains
+	removed := downloader.Subscriptions.Prune(dynamicDomains.Allows)
+	if len(removed) == 0 {
+		return
+	}
+	zpuLogger.Info("unsubscribed domains: " + strings.Join(removed, ", "))
+	if err := downloader.Subscriptions.Save(dynamicDomains.SubscriptionsFile); err != nil {
+		zpuLogger.Error("unable to save subscribed domains, error: " + err.Error())
 	}
 }
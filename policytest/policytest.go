@@ -0,0 +1,170 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 8:10 AM
+ *
+ * Description:
+ * policytest evaluates test cases against a directory of policy files
+ * offline, e.g. to test policies in CI before they are deployed. Policy
+ * files are loaded by cache.LoadDB and cases are checked like
+ * CheckAccessWithToken checks the roles of a validated role token.
+ *
+ */
+
+package policytest
+
+import (
+	"fmt"
+	v1 "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/cache"
+	"github.com/hamed-yousefi/athenz-agent/common"
+	"github.com/hamed-yousefi/athenz-agent/grpc/api"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const policyFileExtension = ".pol"
+
+type (
+	// Case is an access check and its expected status.
+	Case struct {
+		// Name describes the case, the check is used if it's empty
+		Name     string   `yaml:"name"`
+		Domain   string   `yaml:"domain"`
+		Roles    []string `yaml:"roles"`
+		Action   string   `yaml:"action"`
+		Resource string   `yaml:"resource"`
+		// Expect is the name of the expected AccessStatus, e.g. ALLOW or
+		// DENY_NO_MATCH
+		Expect string `yaml:"expect"`
+	}
+
+	// Result is the status of a case.
+	Result struct {
+		Case   Case
+		Status v1.AccessStatus
+		Passed bool
+	}
+
+	// Options configures loading of the policy files.
+	Options struct {
+		// SkipVerification loads the policy files without verifying their
+		// signatures, otherwise config.KeyStore must have the public keys
+		SkipVerification bool
+		// IgnoreExpiry evaluates the policies of expired policy files, it's
+		// an option of Run
+		IgnoreExpiry bool
+	}
+)
+
+// LoadCases reads the cases of a YAML or JSON file, a list of cases.
+func LoadCases(path string) ([]Case, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, common.Errorf("unable to read cases file: %s, error: %s", path, err.Error())
+	}
+
+	var cases []Case
+	if err := yaml.UnmarshalStrict(data, &cases); err != nil {
+		return nil, common.Errorf("unable to decode cases file: %s, error: %s", path, err.Error())
+	}
+	for i, c := range cases {
+		if err := c.Validate(); err != nil {
+			return nil, common.Errorf("case %d: %s", i, err.Error())
+		}
+	}
+	return cases, nil
+}
+
+// Validate checks the required fields and the expected status.
+func (c Case) Validate() error {
+	switch {
+	case c.Domain == "":
+		return fmt.Errorf("domain is required")
+	case len(c.Roles) == 0:
+		return fmt.Errorf("roles are required")
+	case c.Action == "" || c.Resource == "":
+		return fmt.Errorf("action and resource are required")
+	}
+	if _, ok := v1.AccessStatus_value[strings.ToUpper(c.Expect)]; !ok {
+		return fmt.Errorf("unknown expected status '%s'", c.Expect)
+	}
+	return nil
+}
+
+// String returns the name of the case, or its check if it has no name.
+func (c Case) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%s:%s %s %s", c.Domain, strings.Join(c.Roles, ","), c.Action, c.Resource)
+}
+
+// LoadPolicies loads the policy files of the directory into the cache and
+// returns the loaded domains. It fails if a policy file is invalid.
+func LoadPolicies(dir string, opts Options) ([]string, error) {
+	files, err := common.LoadFileStatus(dir)
+	if err != nil {
+		return nil, common.Errorf("unable to read policy directory: %s, error: %s", dir, err.Error())
+	}
+	policyFiles := make([]os.FileInfo, 0, len(files))
+	names := make(map[string]bool, len(files))
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), policyFileExtension) {
+			policyFiles = append(policyFiles, file)
+			names[file.Name()] = true
+		}
+	}
+	if len(policyFiles) == 0 {
+		return nil, common.Errorf("no policy files in directory: %s", dir)
+	}
+
+	if opts.SkipVerification {
+		cache.LoadUnverifiedDB(dir, policyFiles)
+	} else {
+		cache.LoadDB(dir, policyFiles)
+	}
+	invalid := make([]string, 0)
+	for _, name := range cache.InvalidPolicyFiles() {
+		if names[name] {
+			invalid = append(invalid, name)
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, common.Errorf("invalid policy files: %s", strings.Join(invalid, ", "))
+	}
+
+	domains := make([]string, 0, len(cache.DomainStandardRoleAllowMap))
+	for domain := range cache.DomainStandardRoleAllowMap {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	return domains, nil
+}
+
+// Run evaluates the cases by the loaded policies now, or before any expiry
+// if opts ignores it.
+func Run(cases []Case, opts Options) []Result {
+	now := time.Now().UnixNano()
+	if opts.IgnoreExpiry {
+		now = math.MinInt64
+	}
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		accessStatus := api.EvaluateAccess(c.Action, c.Resource, c.Domain, c.Roles, now)
+		results = append(results, Result{Case: c, Status: accessStatus,
+			Passed: accessStatus.String() == strings.ToUpper(c.Expect)})
+	}
+	return results
+}
@@ -0,0 +1,134 @@
+/**
+ * Copyright © 2019 Hamed Yousefi <hdyousefi@gmail.com>.
+ *
+ * Use of this source code is governed by an MIT-style
+ * license that can be found in the LICENSE file.
+ *
+ * User: Hamed Yousefi
+ * Email: hdyousefi@gmail.com
+ * Date: 10/20/26
+ * Time: 8:40 AM
+ *
+ * Description:
+ *
+ */
+
+package policytest
+
+import (
+	v1 "github.com/hamed-yousefi/athenz-agent/.gen/proto/api/message/v1"
+	"github.com/hamed-yousefi/athenz-agent/common/log"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sportsPolicy = `{"signedPolicyData":{"expires":"%s","modified":"2020-06-02T06:11:12.125Z",
+"policyData":{"domain":"sports","policies":[{"name":"sports:policy.readers","assertions":[
+{"action":"read","effect":"ALLOW","resource":"sports:articles.*","role":"sports:role.reader"},
+{"action":"read","effect":"DENY","resource":"sports:articles.draft","role":"sports:role.reader"}]}]},
+"zmsSignature":"unsigned","zmsKeyId":"0"},"signature":"unsigned","keyId":"0"}`
+
+const cases = `
+- name: readers read articles
+  domain: sports
+  roles: [reader]
+  action: read
+  resource: sports:articles.football
+  expect: ALLOW
+- domain: sports
+  roles: [reader]
+  action: read
+  resource: articles.draft
+  expect: deny
+- domain: sports
+  roles: [writer]
+  action: read
+  resource: sports:articles.football
+  expect: ALLOW
+`
+
+func TestRun(t *testing.T) {
+	a := assert.New(t)
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	dir := writeFiles(t, map[string]string{
+		"sports.pol": strings.Replace(sportsPolicy, "%s", "2099-01-01T00:00:00.000Z", 1),
+		"cases.yaml": cases,
+		"README.md":  "not a policy file",
+	})
+	defer os.RemoveAll(dir)
+
+	// signatures are verified by default
+	_, err := LoadPolicies(dir, Options{})
+	a.Error(err)
+
+	domains, err := LoadPolicies(dir, Options{SkipVerification: true})
+	a.NoError(err)
+	a.Equal([]string{"sports"}, domains)
+
+	loaded, err := LoadCases(filepath.Join(dir, "cases.yaml"))
+	a.NoError(err)
+	results := Run(loaded, Options{})
+	a.Len(results, 3)
+	a.True(results[0].Passed)
+	a.True(results[1].Passed)
+	a.False(results[2].Passed)
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_EMPTY, results[2].Status)
+	a.Equal("sports:writer read sports:articles.football", results[2].Case.String())
+}
+
+func TestRun_IgnoreExpiry(t *testing.T) {
+	a := assert.New(t)
+	log.NewLogrusInitializer().InitialLog(log.Info)
+	dir := writeFiles(t, map[string]string{
+		"expired.pol": strings.Replace(sportsPolicy, "%s", "2020-01-01T00:00:00.000Z", 1)})
+	defer os.RemoveAll(dir)
+
+	_, err := LoadPolicies(dir, Options{SkipVerification: true})
+	a.NoError(err)
+	c := Case{Domain: "sports", Roles: []string{"reader"}, Action: "read", Resource: "articles.x", Expect: "ALLOW"}
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_EXPIRED, Run([]Case{c}, Options{})[0].Status)
+	a.True(Run([]Case{c}, Options{IgnoreExpiry: true})[0].Passed)
+
+	// the expiry of the cached policies isn't changed
+	a.Equal(v1.AccessStatus_DENY_DOMAIN_EXPIRED, Run([]Case{c}, Options{})[0].Status)
+}
+
+func TestLoadCases(t *testing.T) {
+	a := assert.New(t)
+	dir := writeFiles(t, map[string]string{
+		"cases.json":   `[{"domain":"sports","roles":["reader"],"action":"read","resource":"x","expect":"ALLOW"}]`,
+		"unknown.yaml": "- {domain: sports, roles: [reader], action: read, resource: x, expect: MAYBE}",
+		"missing.yaml": "- {domain: sports, action: read, resource: x, expect: ALLOW}",
+		"typo.yaml":    "- {domain: sports, role: [reader], action: read, resource: x, expect: ALLOW}",
+	})
+	defer os.RemoveAll(dir)
+
+	loaded, err := LoadCases(filepath.Join(dir, "cases.json"))
+	a.NoError(err)
+	a.Equal([]Case{{Domain: "sports", Roles: []string{"reader"}, Action: "read", Resource: "x", Expect: "ALLOW"}},
+		loaded)
+
+	_, err = LoadCases(filepath.Join(dir, "unknown.yaml"))
+	a.Contains(err.Error(), "case 0: unknown expected status 'MAYBE'")
+	_, err = LoadCases(filepath.Join(dir, "missing.yaml"))
+	a.Contains(err.Error(), "roles are required")
+	_, err = LoadCases(filepath.Join(dir, "typo.yaml"))
+	a.Error(err)
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "policytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
@@ -19,9 +19,11 @@
 package token
 
 import (
+	"fmt"
 	"github.com/hamed-yousefi/athenz-agent/common"
 	"github.com/hamed-yousefi/athenz-agent/common/log"
 	"github.com/hamed-yousefi/athenz-agent/config"
+	"github.com/yahoo/athenz/libs/go/zmssvctoken"
 	"strconv"
 	"strings"
 	"time"
@@ -103,16 +105,36 @@ type RoleToken struct {
 // time and then verify the roleToken by checking public key and hashing of data
 // and signature.
 func (roleToken *RoleToken) Validate(publicKey string, allowedOffset int64, allowNoExpiry bool) (bool, error) {
+	return roleToken.validate(common.FuncName(), publicKey != "", func() error {
+		return common.Verify(roleToken.UnsignedToken, roleToken.Signature, publicKey)
+	}, allowedOffset, allowNoExpiry)
+}
+
+// ValidateWithVerifier validates roleToken the same way Validate does, but it
+// uses an already parsed public key. Use it with config.KeyStore verifiers to
+// avoid parsing the public key for every token.
+func (roleToken *RoleToken) ValidateWithVerifier(verifier zmssvctoken.Verifier, allowedOffset int64,
+	allowNoExpiry bool) (bool, error) {
+	return roleToken.validate(common.FuncName(), verifier != nil, func() error {
+		return verifier.Verify(roleToken.UnsignedToken, roleToken.Signature)
+	}, allowedOffset, allowNoExpiry)
+}
+
+// validate checks roleToken fields, generated time and expiry time and then
+// calls verify to check the signature. Errors have the name of the exported
+// caller, funcName, like common.Errorf errors.
+func (roleToken *RoleToken) validate(funcName string, hasPublicKey bool, verify func() error,
+	allowedOffset int64, allowNoExpiry bool) (bool, error) {
 
 	// check if data and signature exists
 	if roleToken.UnsignedToken == "" || roleToken.Signature == "" {
-		return false, common.Errorf("missing data/signature component, data: %s, signature: %s",
+		return false, validationError(funcName, "missing data/signature component, data: %s, signature: %s",
 			roleToken.UnsignedToken, roleToken.Signature)
 	}
 
 	// check if public key exists
-	if publicKey == "" {
-		return false, common.Errorf("no public key provided, data: %s", roleToken.UnsignedToken)
+	if !hasPublicKey {
+		return false, validationError(funcName, "no public key provided, data: %s", roleToken.UnsignedToken)
 	}
 
 	now := common.CurrentTimeMillis() / 1000
@@ -121,7 +143,7 @@ func (roleToken *RoleToken) Validate(publicKey string, allowedOffset int64, allo
 	// future we'll allow the configured offset between servers.
 	if roleToken.GenerationTime != 0 &&
 		(roleToken.GenerationTime/int64(time.Second))-allowedOffset > now {
-		return false, common.Errorf("token has future generatedTime, generated time: %+v, "+
+		return false, validationError(funcName, "token has future generatedTime, generated time: %+v, "+
 			"now: %+v, allowed offset: %d", roleToken.GenerationTime, time.Unix(0, now), allowedOffset)
 	}
 
@@ -131,19 +153,19 @@ func (roleToken *RoleToken) Validate(publicKey string, allowedOffset int64, allo
 	if roleToken.ExpiryTime != 0 || !allowNoExpiry {
 		expiry := roleToken.ExpiryTime / int64(time.Second)
 		if expiry < now {
-			return false, common.Errorf("token has expired, expiry time: %+v, now: %+v",
+			return false, validationError(funcName, "token has expired, expiry time: %+v, now: %+v",
 				roleToken.ExpiryTime, time.Unix(0, now))
 		}
 
 		if expiry > now+(roleToken.AthenzTokenMaxExpiry*24*60*60)+allowedOffset {
-			return false, common.Errorf("token expires too far in the future, expiryTime: %+v"+
+			return false, validationError(funcName, "token expires too far in the future, expiryTime: %+v"+
 				", current time: %+v, max expiry: %d days, allowed offset: %d", roleToken.ExpiryTime,
 				time.Unix(0, now), roleToken.AthenzTokenMaxExpiry, allowedOffset)
 		}
 
 	}
 
-	err := common.Verify(roleToken.UnsignedToken, roleToken.Signature, publicKey)
+	err := verify()
 	if err != nil {
 		logger.Error(err.Error())
 		return false, nil
@@ -151,6 +173,11 @@ func (roleToken *RoleToken) Validate(publicKey string, allowedOffset int64, allo
 	return true, nil
 }
 
+// validationError creates an error with funcName prefix like common.Errorf.
+func validationError(funcName, format string, params ...interface{}) error {
+	return fmt.Errorf("%s-> %s", funcName, fmt.Sprintf(format, params...))
+}
+
 // NewRoleToken creates new roleToken by a roleToken string that created by zpe.
 func NewRoleToken(signedToken string) (*RoleToken, error) {
 	if signedToken == "" {
@@ -212,9 +239,10 @@ func NewRoleToken(signedToken string) (*RoleToken, error) {
 		}
 	}
 
-	roleToken.AthenzTokenNoExpiry = config.ZpeConfig.Properties.AthenzTokenNoExpiry
+	zpeProperties := config.ZpeConfig.Get()
+	roleToken.AthenzTokenNoExpiry = zpeProperties.AthenzTokenNoExpiry
 	// convert days to milliseconds
-	roleToken.AthenzTokenMaxExpiry = config.ZpeConfig.Properties.AthenzTokenMaxExpiry
+	roleToken.AthenzTokenMaxExpiry = zpeProperties.AthenzTokenMaxExpiry
 
 	// the required attributes for the token are domain
 	// and roles. The signature will be verified during